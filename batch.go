@@ -0,0 +1,86 @@
+package manifests
+
+import (
+	"context"
+	"runtime"
+	"sync"
+)
+
+// File is a named file to parse with ParseAll.
+type File struct {
+	// Filename identifies the parser, as for Parse.
+	Filename string
+	Content  []byte
+}
+
+// BatchOptions configures ParseAll.
+type BatchOptions struct {
+	// Workers is the number of files parsed at once. Zero means
+	// runtime.GOMAXPROCS(0).
+	Workers int
+	// Parse is passed through to Parse for each file.
+	Parse Options
+}
+
+// BatchResult is the outcome of parsing one file with ParseAll. Exactly
+// one of ParseResult and Err is set.
+type BatchResult struct {
+	Filename string
+	*ParseResult
+	Err error
+}
+
+// ParseAll parses files concurrently on a bounded pool of workers and
+// returns one result per file, in input order. A file that fails to
+// parse records its error in BatchResult.Err without affecting the
+// others.
+//
+// When ctx is cancelled, files not yet parsed are given ctx.Err() as
+// their error and ParseAll returns ctx.Err() once the files already
+// being parsed finish. Otherwise the returned error is nil. Each file
+// is parsed with ParseContext, so BatchOptions.Parse limits apply to
+// every file.
+//
+// Parse, and every registered parser, is safe for concurrent use, so
+// callers with their own pool may call Parse directly instead.
+func ParseAll(ctx context.Context, files []File, opts ...BatchOptions) ([]BatchResult, error) {
+	var o BatchOptions
+	if len(opts) > 0 {
+		o = opts[0]
+	}
+	workers := o.Workers
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	workers = min(workers, len(files))
+
+	results := make([]BatchResult, len(files))
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Go(func() {
+			for i := range indexes {
+				f := files[i]
+				res, err := ParseContext(ctx, f.Filename, f.Content, o.Parse)
+				results[i] = BatchResult{Filename: f.Filename, ParseResult: res, Err: err}
+			}
+		})
+	}
+
+	next := 0
+feed:
+	for ; next < len(files) && ctx.Err() == nil; next++ {
+		select {
+		case indexes <- next:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(indexes)
+	wg.Wait()
+
+	for i := next; i < len(files); i++ {
+		results[i] = BatchResult{Filename: files[i].Filename, Err: ctx.Err()}
+	}
+	return results, ctx.Err()
+}
@@ -0,0 +1,130 @@
+package manifests
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// batchFixtures reads every recognised file under testdata.
+func batchFixtures(t *testing.T) []File {
+	t.Helper()
+	var files []File
+	err := filepath.WalkDir("testdata", func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || len(IdentifyAll(p)) == 0 {
+			return err
+		}
+		content, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		files = append(files, File{Filename: p, Content: content})
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("failed to read fixtures: %v", err)
+	}
+	return files
+}
+
+// TestParseAllConcurrent parses every fixture several times over at
+// once, so that go test -race catches shared state in any parser.
+func TestParseAllConcurrent(t *testing.T) {
+	fixtures := batchFixtures(t)
+	if len(fixtures) < 100 {
+		t.Fatalf("expected fixtures for every ecosystem, got %d", len(fixtures))
+	}
+	var files []File
+	for range 4 {
+		files = append(files, fixtures...)
+	}
+
+	results, err := ParseAll(context.Background(), files, BatchOptions{Workers: 16})
+	if err != nil {
+		t.Fatalf("ParseAll: %v", err)
+	}
+	if len(results) != len(files) {
+		t.Fatalf("expected %d results, got %d", len(files), len(results))
+	}
+
+	for i, r := range results {
+		f := files[i]
+		if r.Filename != f.Filename {
+			t.Fatalf("result %d is for %s, expected %s", i, r.Filename, f.Filename)
+		}
+		want, wantErr := Parse(f.Filename, f.Content)
+		if (r.Err == nil) != (wantErr == nil) {
+			t.Errorf("%s: got error %v, Parse gave %v", f.Filename, r.Err, wantErr)
+			continue
+		}
+		if r.Err != nil {
+			if r.ParseResult != nil {
+				t.Errorf("%s: result set alongside error", f.Filename)
+			}
+			continue
+		}
+		if len(r.Dependencies) != len(want.Dependencies) {
+			t.Errorf("%s: got %d dependencies, Parse gave %d", f.Filename, len(r.Dependencies), len(want.Dependencies))
+		}
+	}
+}
+
+func TestParseAllErrors(t *testing.T) {
+	files := []File{
+		{Filename: "package.json", Content: []byte(`{"dependencies": {"react": "^18.0.0"}}`)},
+		{Filename: "unknown.txt", Content: []byte("x")},
+		{Filename: "composer.lock", Content: []byte("{not json")},
+		{Filename: "go.mod", Content: []byte("module m\n\nrequire example.com/a v1.0.0\n")},
+	}
+	results, err := ParseAll(context.Background(), files)
+	if err != nil {
+		t.Fatalf("ParseAll: %v", err)
+	}
+
+	if results[0].Err != nil || len(results[0].Dependencies) != 1 {
+		t.Errorf("package.json: %+v", results[0])
+	}
+	var unknown *UnknownFileError
+	if !errors.As(results[1].Err, &unknown) {
+		t.Errorf("unknown.txt: expected UnknownFileError, got %v", results[1].Err)
+	}
+	var parseErr *ParseError
+	if !errors.As(results[2].Err, &parseErr) {
+		t.Errorf("composer.lock: expected ParseError, got %v", results[2].Err)
+	}
+	if results[3].Err != nil || results[3].Dependencies[0].Name != "example.com/a" {
+		t.Errorf("go.mod: %+v", results[3])
+	}
+}
+
+func TestParseAllCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	files := []File{
+		{Filename: "package.json", Content: []byte(`{}`)},
+		{Filename: "Gemfile", Content: []byte("gem 'rails'\n")},
+	}
+	results, err := ParseAll(ctx, files, BatchOptions{Workers: 1})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	if len(results) != len(files) {
+		t.Fatalf("expected %d results, got %d", len(files), len(results))
+	}
+	for i, r := range results {
+		if r.Filename != files[i].Filename || !errors.Is(r.Err, context.Canceled) {
+			t.Errorf("result %d: expected %s to be cancelled, got %+v", i, files[i].Filename, r)
+		}
+	}
+}
+
+func TestParseAllEmpty(t *testing.T) {
+	results, err := ParseAll(context.Background(), nil)
+	if err != nil || len(results) != 0 {
+		t.Errorf("got %v, %v", results, err)
+	}
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/git-pkgs/manifests"
+)
+
+func identifyCmd(args []string, stdout, stderr io.Writer) int {
+	fs := newFlagSet("identify", "<file>...", stderr)
+	fs.kindFlag()
+	paths, err := fs.parse(args)
+	if err != nil {
+		return usageStatus(err)
+	}
+	if !fs.nargs(paths, 1, -1) {
+		return exitUsage
+	}
+
+	status := exitOK
+	var records []identifyRecord
+	for _, p := range paths {
+		eco, kind, ok := manifests.Identify(filepath.ToSlash(p))
+		if !ok {
+			fmt.Fprintf(stderr, "manifests: %s: unrecognised file\n", p)
+			status = exitFailure
+			continue
+		}
+		if fs.opts.includeKind(kind) {
+			records = append(records, identifyRecord{Path: p, Ecosystem: eco, Kind: kind})
+		}
+	}
+	if err := writeIdentify(stdout, fs.opts.format, records); err != nil {
+		fmt.Fprintf(stderr, "manifests: %v\n", err)
+		return exitFailure
+	}
+	return status
+}
+
+func parseCmd(args []string, stdout, stderr io.Writer) int {
+	fs := newFlagSet("parse", "<file>...", stderr)
+	fs.scopeFlag()
+	fs.kindFlag()
+	fs.directFlag()
+	fs.strictFlag()
+	paths, err := fs.parse(args)
+	if err != nil {
+		return usageStatus(err)
+	}
+	if !fs.nargs(paths, 1, -1) {
+		return exitUsage
+	}
+
+	status := exitOK
+	var files []manifests.ScannedFile
+	for _, p := range paths {
+		res, err := parseFile(p, fs.opts.parse)
+		if err != nil {
+			fmt.Fprintf(stderr, "manifests: %v\n", err)
+			status = exitFailure
+			continue
+		}
+		files = append(files, manifests.ScannedFile{Path: p, ParseResult: res})
+	}
+	writeDiagnostics(stderr, &fs.opts, files)
+	if err := writeFiles(stdout, &fs.opts, files); err != nil {
+		fmt.Fprintf(stderr, "manifests: %v\n", err)
+		return exitFailure
+	}
+	return status
+}
+
+func scanCmd(args []string, stdout, stderr io.Writer) int {
+	fs := newFlagSet("scan", "<dir>", stderr)
+	fs.scopeFlag()
+	fs.kindFlag()
+	fs.directFlag()
+	fs.strictFlag()
+	var scanOpts manifests.ScanOptions
+	fs.Func("ignore", "skip files and directories matching this glob (repeatable)", func(pattern string) error {
+		scanOpts.Ignore = append(scanOpts.Ignore, pattern)
+		return nil
+	})
+	fs.BoolVar(&scanOpts.NoDefaultIgnores, "no-default-ignores", false, "also scan node_modules, vendor, testdata and VCS directories")
+	fs.IntVar(&scanOpts.MaxDepth, "max-depth", 0, "how many directory levels to descend; 0 means no limit")
+	dirs, err := fs.parse(args)
+	if err != nil {
+		return usageStatus(err)
+	}
+	if !fs.nargs(dirs, 1, 1) {
+		return exitUsage
+	}
+	dir := dirs[0]
+	scanOpts.Parse = fs.opts.parse
+
+	res, err := manifests.Scan(os.DirFS(dir), scanOpts)
+	if err != nil {
+		fmt.Fprintf(stderr, "manifests: %v\n", err)
+		return exitFailure
+	}
+	writeDiagnostics(stderr, &fs.opts, res.Files)
+	status := exitOK
+	for _, e := range res.Errors {
+		fmt.Fprintf(stderr, "manifests: %s: %v\n", filepath.Join(dir, filepath.FromSlash(e.Path)), e.Err)
+		status = exitFailure
+	}
+	if err := writeFiles(stdout, &fs.opts, res.Files); err != nil {
+		fmt.Fprintf(stderr, "manifests: %v\n", err)
+		return exitFailure
+	}
+	return status
+}
+
+func diffCmd(args []string, stdout, stderr io.Writer) int {
+	fs := newFlagSet("diff", "<old> <new>", stderr)
+	fs.scopeFlag()
+	fs.directFlag()
+	fs.strictFlag()
+	paths, err := fs.parse(args)
+	if err != nil {
+		return usageStatus(err)
+	}
+	if !fs.nargs(paths, 2, 2) {
+		return exitUsage
+	}
+
+	var results [2]*manifests.ParseResult
+	for i, p := range paths {
+		if results[i], err = parseFile(p, fs.opts.parse); err != nil {
+			fmt.Fprintf(stderr, "manifests: %v\n", err)
+			return exitFailure
+		}
+	}
+	changes, err := manifests.Diff(results[0], results[1])
+	if err != nil {
+		fmt.Fprintf(stderr, "manifests: %v\n", err)
+		return exitFailure
+	}
+	if err := writeChanges(stdout, &fs.opts, changes); err != nil {
+		fmt.Fprintf(stderr, "manifests: %v\n", err)
+		return exitFailure
+	}
+	return exitOK
+}
+
+func ecosystemsCmd(args []string, stdout, stderr io.Writer) int {
+	fs := newFlagSet("ecosystems", "", stderr)
+	rest, err := fs.parse(args)
+	if err != nil {
+		return usageStatus(err)
+	}
+	if !fs.nargs(rest, 0, 0) {
+		return exitUsage
+	}
+	if err := writeEcosystems(stdout, fs.opts.format, manifests.Ecosystems()); err != nil {
+		fmt.Fprintf(stderr, "manifests: %v\n", err)
+		return exitFailure
+	}
+	return exitOK
+}
+
+// parseFile reads and parses the file at p. The path, not just its base
+// name, is matched against parsers so that files such as
+// .github/workflows/ci.yml are recognised.
+func parseFile(p string, opts manifests.Options) (*manifests.ParseResult, error) {
+	content, err := os.ReadFile(p)
+	if err != nil {
+		return nil, err
+	}
+	return manifests.Parse(filepath.ToSlash(p), content, opts)
+}
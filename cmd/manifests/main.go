@@ -0,0 +1,243 @@
+// Command manifests identifies, parses and compares dependency manifests
+// and lockfiles from the shell.
+//
+// Usage:
+//
+//	manifests identify [flags] <file>...
+//	manifests parse [flags] <file>...
+//	manifests scan [flags] <dir>
+//	manifests diff [flags] <old> <new>
+//	manifests ecosystems [flags]
+//
+// Output is a table by default; -format json writes one JSON document
+// and -format ndjson one JSON object per line. The exit status is 1 when
+// any file can't be read, identified or parsed, and 2 for usage errors.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/git-pkgs/manifests"
+)
+
+const (
+	exitOK      = 0
+	exitFailure = 1
+	exitUsage   = 2
+)
+
+const usage = `usage: manifests <command> [flags] [args]
+
+Commands:
+  identify <file>...   report the ecosystem and kind of each file
+  parse <file>...      parse files and list their dependencies
+  scan <dir>           find and parse every manifest and lockfile under dir
+  diff <old> <new>     compare two versions of a manifest or lockfile
+  ecosystems           list supported ecosystems
+
+Run 'manifests <command> -h' for a command's flags.
+`
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// run executes the command line args and returns the exit status.
+func run(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, usage)
+		return exitUsage
+	}
+	cmd, args := args[0], args[1:]
+	switch cmd {
+	case "identify":
+		return identifyCmd(args, stdout, stderr)
+	case "parse":
+		return parseCmd(args, stdout, stderr)
+	case "scan":
+		return scanCmd(args, stdout, stderr)
+	case "diff":
+		return diffCmd(args, stdout, stderr)
+	case "ecosystems":
+		return ecosystemsCmd(args, stdout, stderr)
+	case "help", "-h", "-help", "--help":
+		fmt.Fprint(stdout, usage)
+		return exitOK
+	default:
+		fmt.Fprintf(stderr, "manifests: unknown command %q\n\n%s", cmd, usage)
+		return exitUsage
+	}
+}
+
+// outputFormats are the values -format accepts.
+var outputFormats = []string{"table", "json", "ndjson"}
+
+var scopes = []manifests.Scope{
+	manifests.Runtime,
+	manifests.Development,
+	manifests.Test,
+	manifests.Build,
+	manifests.Optional,
+}
+
+var kinds = []manifests.Kind{
+	manifests.Manifest,
+	manifests.Lockfile,
+	manifests.Supplement,
+}
+
+// options holds the flags shared by the subcommands. Each subcommand
+// registers the ones that make sense for it.
+type options struct {
+	format string
+	scope  string
+	kind   string
+	direct bool
+	parse  manifests.Options
+
+	scopes map[manifests.Scope]bool
+	kinds  map[manifests.Kind]bool
+}
+
+type flagSet struct {
+	*flag.FlagSet
+	opts options
+}
+
+func newFlagSet(name, args string, stderr io.Writer) *flagSet {
+	fs := &flagSet{FlagSet: flag.NewFlagSet(name, flag.ContinueOnError)}
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(stderr, "usage: manifests %s [flags] %s\n\nFlags:\n", name, args)
+		fs.PrintDefaults()
+	}
+	fs.StringVar(&fs.opts.format, "format", "table", "output format: "+strings.Join(outputFormats, ", "))
+	return fs
+}
+
+func (fs *flagSet) scopeFlag() {
+	names := make([]string, len(scopes))
+	for i, s := range scopes {
+		names[i] = string(s)
+	}
+	fs.StringVar(&fs.opts.scope, "scope", "", "only include dependencies in these comma-separated scopes: "+strings.Join(names, ", "))
+}
+
+func (fs *flagSet) kindFlag() {
+	names := make([]string, len(kinds))
+	for i, k := range kinds {
+		names[i] = string(k)
+	}
+	fs.StringVar(&fs.opts.kind, "kind", "", "only include files of these comma-separated kinds: "+strings.Join(names, ", "))
+}
+
+func (fs *flagSet) directFlag() {
+	fs.BoolVar(&fs.opts.direct, "direct", false, "only include direct dependencies")
+}
+
+func (fs *flagSet) strictFlag() {
+	fs.BoolVar(&fs.opts.parse.Strict, "strict", false, "fail on files with input the parser had to skip or guess at")
+}
+
+// parse parses args, allowing flags after positional arguments so that
+// "manifests parse Gemfile.lock -format json" works, and validates the
+// flag values.
+func (fs *flagSet) parse(args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		rest := fs.Args()
+		if len(rest) == 0 {
+			break
+		}
+		// Everything after "--" is positional
+		if consumed := len(args) - len(rest); consumed > 0 && args[consumed-1] == "--" {
+			positional = append(positional, rest...)
+			break
+		}
+		positional = append(positional, rest[0])
+		args = rest[1:]
+	}
+
+	if err := fs.opts.validate(); err != nil {
+		fmt.Fprintf(fs.Output(), "manifests %s: %v\n", fs.Name(), err)
+		return nil, err
+	}
+	return positional, nil
+}
+
+// nargs checks the number of positional arguments, printing usage when
+// it's wrong. max < 0 means no upper limit.
+func (fs *flagSet) nargs(args []string, min, max int) bool {
+	if len(args) >= min && (max < 0 || len(args) <= max) {
+		return true
+	}
+	fs.Usage()
+	return false
+}
+
+func (o *options) validate() error {
+	if !contains(outputFormats, o.format) {
+		return fmt.Errorf("unknown format %q", o.format)
+	}
+	if o.scope != "" {
+		o.scopes = make(map[manifests.Scope]bool)
+		for _, s := range strings.Split(o.scope, ",") {
+			scope := manifests.Scope(strings.TrimSpace(s))
+			if !contains(scopes, scope) {
+				return fmt.Errorf("unknown scope %q", scope)
+			}
+			o.scopes[scope] = true
+		}
+	}
+	if o.kind != "" {
+		o.kinds = make(map[manifests.Kind]bool)
+		for _, k := range strings.Split(o.kind, ",") {
+			kind := manifests.Kind(strings.TrimSpace(k))
+			if !contains(kinds, kind) {
+				return fmt.Errorf("unknown kind %q", kind)
+			}
+			o.kinds[kind] = true
+		}
+	}
+	return nil
+}
+
+// includeKind reports whether files of kind pass the -kind filter.
+func (o *options) includeKind(kind manifests.Kind) bool {
+	return o.kinds == nil || o.kinds[kind]
+}
+
+// includeDependency reports whether dep passes the -scope and -direct
+// filters.
+func (o *options) includeDependency(dep *manifests.Dependency) bool {
+	if o.direct && !dep.Direct {
+		return false
+	}
+	return o.scopes == nil || o.scopes[dep.Scope]
+}
+
+func contains[T comparable](values []T, v T) bool {
+	for _, value := range values {
+		if value == v {
+			return true
+		}
+	}
+	return false
+}
+
+// usageStatus maps a flag parsing error to an exit status: asking for
+// help succeeds, anything else is a usage error.
+func usageStatus(err error) int {
+	if errors.Is(err, flag.ErrHelp) {
+		return exitOK
+	}
+	return exitUsage
+}
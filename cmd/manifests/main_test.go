@@ -0,0 +1,238 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func runCmd(t *testing.T, args ...string) (stdout, stderr string, status int) {
+	t.Helper()
+	var out, errOut bytes.Buffer
+	status = run(args, &out, &errOut)
+	return out.String(), errOut.String(), status
+}
+
+func TestIdentify(t *testing.T) {
+	stdout, stderr, status := runCmd(t, "identify", "-format", "json", "Gemfile.lock", "dir/package.json", "notes.txt")
+	if status != exitFailure {
+		t.Errorf("status = %d, want %d", status, exitFailure)
+	}
+	if !strings.Contains(stderr, "notes.txt: unrecognised file") {
+		t.Errorf("stderr = %q", stderr)
+	}
+	var records []identifyRecord
+	if err := json.Unmarshal([]byte(stdout), &records); err != nil {
+		t.Fatalf("invalid JSON %q: %v", stdout, err)
+	}
+	if len(records) != 2 || records[0].Ecosystem != "gem" || records[1].Kind != "manifest" {
+		t.Errorf("unexpected records %+v", records)
+	}
+}
+
+func TestParse(t *testing.T) {
+	stdout, stderr, status := runCmd(t, "parse", "../../testdata/npm/package.json", "-format", "json", "-scope", "development")
+	if status != exitOK {
+		t.Fatalf("status = %d, stderr %q", status, stderr)
+	}
+	var files []fileRecord
+	if err := json.Unmarshal([]byte(stdout), &files); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if len(files) != 1 || files[0].Ecosystem != "npm" || files[0].Path != "../../testdata/npm/package.json" {
+		t.Fatalf("unexpected files %+v", files)
+	}
+	if len(files[0].Dependencies) == 0 {
+		t.Fatal("expected development dependencies")
+	}
+	if m := files[0].Metadata; m == nil || m.License != "MIT" {
+		t.Errorf("unexpected metadata %+v", m)
+	}
+	for _, dep := range files[0].Dependencies {
+		if dep.Scope != "development" {
+			t.Errorf("%s has scope %q", dep.Name, dep.Scope)
+		}
+	}
+}
+
+func TestParseGoWork(t *testing.T) {
+	stdout, stderr, status := runCmd(t, "parse", "../../testdata/golang/go.work", "-format", "json")
+	if status != exitOK {
+		t.Fatalf("status = %d, stderr %q", status, stderr)
+	}
+	var files []fileRecord
+	if err := json.Unmarshal([]byte(stdout), &files); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if len(files) != 1 || len(files[0].Runtimes) != 2 || len(files[0].Replacements) != 3 {
+		t.Fatalf("unexpected files %+v", files)
+	}
+	if r := files[0].Replacements[2]; r.Name != "example.com/shared" || r.NewName != "../shared" || r.NewVersion != "" {
+		t.Errorf("unexpected replacement %+v", r)
+	}
+}
+
+func TestParseFailure(t *testing.T) {
+	dir := t.TempDir()
+	bad := filepath.Join(dir, "package.json")
+	if err := os.WriteFile(bad, []byte("{"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	stdout, stderr, status := runCmd(t, "parse", "-format", "ndjson", "../../testdata/cargo/Cargo.lock", bad)
+	if status != exitFailure {
+		t.Errorf("status = %d, want %d", status, exitFailure)
+	}
+	if !strings.Contains(stderr, "package.json") {
+		t.Errorf("stderr = %q", stderr)
+	}
+	// The file that did parse is still written
+	lines := strings.Split(strings.TrimSpace(stdout), "\n")
+	var first dependencyLine
+	if err := json.Unmarshal([]byte(lines[0]), &first); err != nil {
+		t.Fatalf("invalid NDJSON line %q: %v", lines[0], err)
+	}
+	if first.Ecosystem != "cargo" || first.Kind != "lockfile" || first.Name == "" {
+		t.Errorf("unexpected line %+v", first)
+	}
+}
+
+func TestParseDiagnostics(t *testing.T) {
+	dir := t.TempDir()
+	dockerfile := filepath.Join(dir, "Dockerfile")
+	if err := os.WriteFile(dockerfile, []byte("FROM ${BASE}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	stdout, stderr, status := runCmd(t, "parse", "-format", "json", dockerfile)
+	if status != exitOK {
+		t.Errorf("status = %d, want %d", status, exitOK)
+	}
+	if !strings.Contains(stderr, dockerfile+":1: warning: ") {
+		t.Errorf("stderr = %q", stderr)
+	}
+	var files []fileRecord
+	if err := json.Unmarshal([]byte(stdout), &files); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if len(files) != 1 || len(files[0].Diagnostics) != 1 || files[0].Diagnostics[0].Code != "unresolved-variable" {
+		t.Errorf("unexpected files %+v", files)
+	}
+
+	_, stderr, status = runCmd(t, "parse", "-strict", dockerfile)
+	if status != exitFailure || !strings.Contains(stderr, "strict: ") {
+		t.Errorf("strict: status = %d, stderr %q", status, stderr)
+	}
+}
+
+func TestScan(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"package.json":      `{"dependencies": {"express": "^4.18.0"}, "devDependencies": {"jest": "^29.0.0"}}`,
+		"api/Gemfile":       "source 'https://rubygems.org'\ngem 'rails'\n",
+		"api/Gemfile.lock":  "GEM\n  remote: https://rubygems.org/\n  specs:\n    rails (7.1.0)\n\nDEPENDENCIES\n  rails\n",
+		"node_modules/x.js": "",
+	}
+	for name, content := range files {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	stdout, stderr, status := runCmd(t, "scan", dir, "-kind", "manifest", "-scope", "runtime")
+	if status != exitOK {
+		t.Fatalf("status = %d, stderr %q", status, stderr)
+	}
+	lines := strings.Split(strings.TrimSpace(stdout), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("expected header and two rows, got:\n%s", stdout)
+	}
+	if fields := strings.Fields(lines[0]); fields[0] != "PATH" || fields[3] != "NAME" {
+		t.Errorf("unexpected header %q", lines[0])
+	}
+	if !strings.Contains(stdout, "api/Gemfile ") || !strings.Contains(stdout, "express") {
+		t.Errorf("unexpected rows:\n%s", stdout)
+	}
+	if strings.Contains(stdout, "jest") || strings.Contains(stdout, "Gemfile.lock") {
+		t.Errorf("filters not applied:\n%s", stdout)
+	}
+}
+
+func TestDiff(t *testing.T) {
+	dir := t.TempDir()
+	before := filepath.Join(dir, "before", "package.json")
+	after := filepath.Join(dir, "after", "package.json")
+	for p, content := range map[string]string{
+		before: `{"dependencies": {"express": "^4.17.0", "lodash": "^4.17.0"}}`,
+		after:  `{"dependencies": {"express": "^5.0.0"}, "devDependencies": {"jest": "^29.0.0"}}`,
+	} {
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	stdout, stderr, status := runCmd(t, "diff", "-format", "json", "-scope", "runtime", before, after)
+	if status != exitOK {
+		t.Fatalf("status = %d, stderr %q", status, stderr)
+	}
+	var changes []changeRecord
+	if err := json.Unmarshal([]byte(stdout), &changes); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	got := make(map[string]changeRecord)
+	for _, c := range changes {
+		got[c.Name] = c
+	}
+	if len(got) != 2 {
+		t.Errorf("expected express and lodash, got %+v", changes)
+	}
+	if c := got["express"]; c.Kind != "upgraded" || c.Bump != "major" || c.OldVersion != "^4.17.0" || c.NewVersion != "^5.0.0" {
+		t.Errorf("express: %+v", c)
+	}
+	if c := got["lodash"]; c.Kind != "removed" {
+		t.Errorf("lodash: %+v", c)
+	}
+}
+
+func TestUsage(t *testing.T) {
+	tests := []struct {
+		args   []string
+		status int
+	}{
+		{nil, exitUsage},
+		{[]string{"frobnicate"}, exitUsage},
+		{[]string{"help"}, exitOK},
+		{[]string{"parse"}, exitUsage},
+		{[]string{"parse", "-h"}, exitOK},
+		{[]string{"parse", "-format", "xml", "Gemfile"}, exitUsage},
+		{[]string{"parse", "-scope", "runtime,prod", "Gemfile"}, exitUsage},
+		{[]string{"diff", "a"}, exitUsage},
+		{[]string{"ecosystems", "extra"}, exitUsage},
+	}
+	for _, tt := range tests {
+		if _, _, status := runCmd(t, tt.args...); status != tt.status {
+			t.Errorf("%v: status = %d, want %d", tt.args, status, tt.status)
+		}
+	}
+}
+
+func TestEcosystems(t *testing.T) {
+	stdout, _, status := runCmd(t, "ecosystems", "-format", "ndjson")
+	if status != exitOK {
+		t.Fatalf("status = %d", status)
+	}
+	if !strings.Contains(stdout, `"npm"`+"\n") {
+		t.Errorf("npm missing from:\n%s", stdout)
+	}
+	if strings.Contains(stdout, `"cyclonedx"`) || strings.Contains(stdout, `"spdx"`) {
+		t.Errorf("SBOM formats listed as ecosystems:\n%s", stdout)
+	}
+}
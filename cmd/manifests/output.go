@@ -0,0 +1,334 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/git-pkgs/manifests"
+)
+
+type identifyRecord struct {
+	Path      string         `json:"path"`
+	Ecosystem string         `json:"ecosystem"`
+	Kind      manifests.Kind `json:"kind"`
+}
+
+// fileRecord is a parsed file in JSON output.
+type fileRecord struct {
+	Path          string              `json:"path"`
+	Ecosystem     string              `json:"ecosystem"`
+	Kind          manifests.Kind      `json:"kind"`
+	Name          string              `json:"name,omitempty"`
+	Version       string              `json:"version,omitempty"`
+	FormatVersion string              `json:"format_version,omitempty"`
+	Metadata      *metadataRecord     `json:"metadata,omitempty"`
+	Runtimes      []runtimeRecord     `json:"runtimes,omitempty"`
+	Replacements  []replacementRecord `json:"replacements,omitempty"`
+	Excludes      []exclusionRecord   `json:"excludes,omitempty"`
+	Retractions   []retractionRecord  `json:"retractions,omitempty"`
+	Dependencies  []dependencyRecord  `json:"dependencies"`
+	Diagnostics   []diagnosticRecord  `json:"diagnostics,omitempty"`
+}
+
+type runtimeRecord struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+	Source  string `json:"source"`
+}
+
+type replacementRecord struct {
+	Name       string `json:"name"`
+	Version    string `json:"version,omitempty"`
+	NewName    string `json:"new_name"`
+	NewVersion string `json:"new_version,omitempty"`
+}
+
+func newReplacementRecord(r *manifests.Replacement) replacementRecord {
+	return replacementRecord{Name: r.Name, Version: r.Version, NewName: r.NewName, NewVersion: r.NewVersion}
+}
+
+type exclusionRecord struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+// retractionRecord gives the retracted versions as a vers URI.
+type retractionRecord struct {
+	Versions  string `json:"versions"`
+	Rationale string `json:"rationale,omitempty"`
+}
+
+type metadataRecord struct {
+	License     string   `json:"license,omitempty"`
+	Description string   `json:"description,omitempty"`
+	Homepage    string   `json:"homepage,omitempty"`
+	Repository  string   `json:"repository,omitempty"`
+	Authors     []string `json:"authors,omitempty"`
+	Keywords    []string `json:"keywords,omitempty"`
+}
+
+type diagnosticRecord struct {
+	Severity manifests.Severity `json:"severity"`
+	Code     string             `json:"code"`
+	Message  string             `json:"message"`
+	Line     int                `json:"line,omitempty"`
+}
+
+// dependencyRecord is a dependency in JSON output. Ecosystem is only set
+// when it differs from the file's, as in SBOMs.
+type dependencyRecord struct {
+	Name        string             `json:"name"`
+	Version     string             `json:"version,omitempty"`
+	Ecosystem   string             `json:"ecosystem,omitempty"`
+	Scope       manifests.Scope    `json:"scope,omitempty"`
+	Direct      bool               `json:"direct"`
+	PURL        string             `json:"purl,omitempty"`
+	Integrity   string             `json:"integrity,omitempty"`
+	RegistryURL string             `json:"registry_url,omitempty"`
+	License     string             `json:"license,omitempty"`
+	Hashes      []hashRecord       `json:"hashes,omitempty"`
+	Workspace   bool               `json:"workspace,omitempty"`
+	Replacement *replacementRecord `json:"replacement,omitempty"`
+}
+
+type hashRecord struct {
+	Algorithm string `json:"algorithm"`
+	Value     string `json:"value"`
+	Artifact  string `json:"artifact,omitempty"`
+}
+
+// dependencyLine is a dependency in NDJSON output, carrying the file it
+// came from so each line stands alone.
+type dependencyLine struct {
+	Path string         `json:"path"`
+	Kind manifests.Kind `json:"kind"`
+	dependencyRecord
+}
+
+type changeRecord struct {
+	Kind       manifests.ChangeKind `json:"kind"`
+	Name       string               `json:"name"`
+	Bump       manifests.Bump       `json:"bump,omitempty"`
+	OldVersion string               `json:"old_version,omitempty"`
+	NewVersion string               `json:"new_version,omitempty"`
+	OldScope   manifests.Scope      `json:"old_scope,omitempty"`
+	NewScope   manifests.Scope      `json:"new_scope,omitempty"`
+}
+
+func newDependencyRecord(dep *manifests.Dependency) dependencyRecord {
+	r := dependencyRecord{
+		Name:        dep.Name,
+		Version:     dep.Version,
+		Ecosystem:   dep.Ecosystem,
+		Scope:       dep.Scope,
+		Direct:      dep.Direct,
+		PURL:        dep.PURL,
+		Integrity:   dep.Integrity,
+		RegistryURL: dep.RegistryURL,
+		License:     dep.License,
+		Workspace:   dep.Workspace,
+	}
+	if dep.Replacement != nil {
+		replacement := newReplacementRecord(dep.Replacement)
+		r.Replacement = &replacement
+	}
+	for _, h := range dep.Hashes {
+		r.Hashes = append(r.Hashes, hashRecord(h))
+	}
+	return r
+}
+
+func writeIdentify(w io.Writer, format string, records []identifyRecord) error {
+	switch format {
+	case "json":
+		return writeJSON(w, nonNil(records))
+	case "ndjson":
+		return writeNDJSON(w, records)
+	}
+	tw := newTable(w, "PATH", "ECOSYSTEM", "KIND")
+	for _, r := range records {
+		row(tw, r.Path, r.Ecosystem, string(r.Kind))
+	}
+	return tw.Flush()
+}
+
+// writeFiles writes parsed files, applying the scope, kind and direct
+// filters. NDJSON output has one line per dependency rather than per
+// file.
+func writeFiles(w io.Writer, o *options, files []manifests.ScannedFile) error {
+	records := make([]fileRecord, 0, len(files))
+	for _, f := range files {
+		if !o.includeKind(f.Kind) {
+			continue
+		}
+		r := fileRecord{
+			Path:          f.Path,
+			Ecosystem:     f.Ecosystem,
+			Kind:          f.Kind,
+			Name:          f.Name,
+			Version:       f.Version,
+			FormatVersion: f.FormatVersion,
+			Dependencies:  []dependencyRecord{},
+		}
+		if !f.Metadata.IsZero() {
+			metadata := metadataRecord(f.Metadata)
+			r.Metadata = &metadata
+		}
+		for _, rt := range f.Runtimes {
+			r.Runtimes = append(r.Runtimes, runtimeRecord(rt))
+		}
+		for i := range f.Replacements {
+			r.Replacements = append(r.Replacements, newReplacementRecord(&f.Replacements[i]))
+		}
+		for _, e := range f.Excludes {
+			r.Excludes = append(r.Excludes, exclusionRecord{Name: e.Name, Version: e.Version})
+		}
+		for _, rt := range f.Retractions {
+			r.Retractions = append(r.Retractions, retractionRecord{Versions: rt.Range.VERS, Rationale: rt.Rationale})
+		}
+		for i := range f.Dependencies {
+			if dep := &f.Dependencies[i]; o.includeDependency(dep) {
+				r.Dependencies = append(r.Dependencies, newDependencyRecord(dep))
+			}
+		}
+		for _, d := range f.Diagnostics {
+			r.Diagnostics = append(r.Diagnostics, diagnosticRecord(d))
+		}
+		records = append(records, r)
+	}
+
+	switch o.format {
+	case "json":
+		return writeJSON(w, records)
+	case "ndjson":
+		var lines []dependencyLine
+		for _, r := range records {
+			for _, dep := range r.Dependencies {
+				if dep.Ecosystem == "" {
+					dep.Ecosystem = r.Ecosystem
+				}
+				lines = append(lines, dependencyLine{Path: r.Path, Kind: r.Kind, dependencyRecord: dep})
+			}
+		}
+		return writeNDJSON(w, lines)
+	}
+	tw := newTable(w, "PATH", "ECOSYSTEM", "KIND", "NAME", "VERSION", "SCOPE", "DIRECT")
+	for _, r := range records {
+		for _, dep := range r.Dependencies {
+			eco := dep.Ecosystem
+			if eco == "" {
+				eco = r.Ecosystem
+			}
+			row(tw, r.Path, eco, string(r.Kind), dep.Name, dep.Version, string(dep.Scope), strconv.FormatBool(dep.Direct))
+		}
+	}
+	return tw.Flush()
+}
+
+// writeDiagnostics reports the files' diagnostics on stderr, in the
+// usual file:line: form, whatever the output format.
+func writeDiagnostics(w io.Writer, o *options, files []manifests.ScannedFile) {
+	for _, f := range files {
+		if !o.includeKind(f.Kind) {
+			continue
+		}
+		for _, d := range f.Diagnostics {
+			loc := f.Path
+			if d.Line > 0 {
+				loc += ":" + strconv.Itoa(d.Line)
+			}
+			fmt.Fprintf(w, "manifests: %s: %s: %s (%s)\n", loc, d.Severity, d.Message, d.Code)
+		}
+	}
+}
+
+// writeChanges writes the result of Diff. A change passes the scope and
+// direct filters if either side does.
+func writeChanges(w io.Writer, o *options, changes []manifests.Change) error {
+	records := make([]changeRecord, 0, len(changes))
+	for _, c := range changes {
+		if !(c.Old != nil && o.includeDependency(c.Old)) && !(c.New != nil && o.includeDependency(c.New)) {
+			continue
+		}
+		r := changeRecord{Kind: c.Kind, Name: c.Name, Bump: c.Bump}
+		if c.Old != nil {
+			r.OldVersion, r.OldScope = c.Old.Version, c.Old.Scope
+		}
+		if c.New != nil {
+			r.NewVersion, r.NewScope = c.New.Version, c.New.Scope
+		}
+		records = append(records, r)
+	}
+
+	switch o.format {
+	case "json":
+		return writeJSON(w, records)
+	case "ndjson":
+		return writeNDJSON(w, records)
+	}
+	tw := newTable(w, "CHANGE", "NAME", "FROM", "TO", "BUMP")
+	for _, r := range records {
+		from, to := r.OldVersion, r.NewVersion
+		if r.Kind == manifests.ScopeChanged {
+			from, to = string(r.OldScope), string(r.NewScope)
+		}
+		row(tw, string(r.Kind), r.Name, from, to, string(r.Bump))
+	}
+	return tw.Flush()
+}
+
+func writeEcosystems(w io.Writer, format string, ecosystems []string) error {
+	switch format {
+	case "json":
+		return writeJSON(w, nonNil(ecosystems))
+	case "ndjson":
+		return writeNDJSON(w, ecosystems)
+	}
+	for _, eco := range ecosystems {
+		if _, err := fmt.Fprintln(w, eco); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeJSON(w io.Writer, v any) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	return enc.Encode(v)
+}
+
+func writeNDJSON[T any](w io.Writer, values []T) error {
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	for _, v := range values {
+		if err := enc.Encode(v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// nonNil makes empty results encode as [] rather than null.
+func nonNil[T any](values []T) []T {
+	if values == nil {
+		return []T{}
+	}
+	return values
+}
+
+func newTable(w io.Writer, header ...string) *tabwriter.Writer {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	row(tw, header...)
+	return tw
+}
+
+// row writes a table row. Errors surface from Flush.
+func row(tw *tabwriter.Writer, cells ...string) {
+	fmt.Fprintln(tw, strings.Join(cells, "\t"))
+}
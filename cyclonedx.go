@@ -0,0 +1,208 @@
+package manifests
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// CycloneDXOptions configures WriteCycloneDX.
+type CycloneDXOptions struct {
+	// SpecVersion is the CycloneDX version to write, "1.5" or "1.6".
+	// Defaults to "1.6".
+	SpecVersion string
+	// Name and Version describe the project the SBOM is for, written as
+	// the metadata component. Default to the first result's own name.
+	Name    string
+	Version string
+	// SerialNumber is written as the BOM's serialNumber when set, e.g.
+	// "urn:uuid:3e671687-395b-41f5-a30f-a58921a69b79".
+	SerialNumber string
+	// Timestamp is written to the metadata when non-zero. Left unset,
+	// the output is a pure function of the inputs.
+	Timestamp time.Time
+}
+
+// cdxSpecVersions are the CycloneDX versions WriteCycloneDX can write.
+var cdxSpecVersions = map[string]bool{"1.5": true, "1.6": true}
+
+// cdxHashAlgs maps SRI algorithm names to CycloneDX ones.
+var cdxHashAlgs = map[string]string{
+	"md5":    "MD5",
+	"sha1":   "SHA-1",
+	"sha256": "SHA-256",
+	"sha384": "SHA-384",
+	"sha512": "SHA-512",
+}
+
+type cdxBOM struct {
+	BOMFormat    string          `json:"bomFormat"`
+	SpecVersion  string          `json:"specVersion"`
+	SerialNumber string          `json:"serialNumber,omitempty"`
+	Version      int             `json:"version"`
+	Metadata     *cdxMetadata    `json:"metadata,omitempty"`
+	Components   []cdxComponent  `json:"components"`
+	Dependencies []cdxDependency `json:"dependencies,omitempty"`
+}
+
+type cdxMetadata struct {
+	Timestamp string        `json:"timestamp,omitempty"`
+	Component *cdxComponent `json:"component,omitempty"`
+}
+
+type cdxComponent struct {
+	Type               string           `json:"type"`
+	BOMRef             string           `json:"bom-ref"`
+	Name               string           `json:"name"`
+	Version            string           `json:"version,omitempty"`
+	Scope              string           `json:"scope,omitempty"`
+	Hashes             []cdxHash        `json:"hashes,omitempty"`
+	Licenses           []cdxLicense     `json:"licenses,omitempty"`
+	PURL               string           `json:"purl,omitempty"`
+	ExternalReferences []cdxExternalRef `json:"externalReferences,omitempty"`
+}
+
+type cdxHash struct {
+	Alg     string `json:"alg"`
+	Content string `json:"content"`
+}
+
+// cdxLicense is one entry of a component's licenses: either an SPDX
+// expression or a named license.
+type cdxLicense struct {
+	Expression string          `json:"expression,omitempty"`
+	License    *cdxLicenseName `json:"license,omitempty"`
+}
+
+type cdxLicenseName struct {
+	Name string `json:"name"`
+}
+
+type cdxExternalRef struct {
+	Type string `json:"type"`
+	URL  string `json:"url"`
+}
+
+type cdxDependency struct {
+	Ref       string   `json:"ref"`
+	DependsOn []string `json:"dependsOn"`
+}
+
+// WriteCycloneDX writes the dependencies of one or more parse results
+// as a CycloneDX JSON SBOM.
+//
+// Each distinct package becomes a library component identified by its
+// PURL. Lockfile entries carry their resolved version; a manifest
+// requirement is folded into the lockfile component of the same name
+// when results include one, and is otherwise written without a version.
+// Integrity values and Hashes become hashes, with SRI digests decoded
+// to hex and duplicates dropped. License becomes an SPDX license
+// expression when it reads as one and a named license otherwise.
+// Scope maps to CycloneDX scope: Runtime is "required", Optional is
+// "optional", and Development, Test and Build are "excluded". The
+// dependencies section lists the edges recorded by lockfile graphs, and
+// when the SBOM has a metadata component, the project's direct
+// dependencies.
+func WriteCycloneDX(w io.Writer, results []*ParseResult, opts ...CycloneDXOptions) error {
+	var o CycloneDXOptions
+	if len(opts) > 0 {
+		o = opts[0]
+	}
+	if o.SpecVersion == "" {
+		o.SpecVersion = "1.6"
+	}
+	if !cdxSpecVersions[o.SpecVersion] {
+		return fmt.Errorf("cyclonedx: unsupported spec version %q", o.SpecVersion)
+	}
+
+	inv := newSBOMInventory(results)
+	bom := cdxBOM{
+		BOMFormat:    "CycloneDX",
+		SpecVersion:  o.SpecVersion,
+		SerialNumber: o.SerialNumber,
+		Version:      1,
+		Components:   make([]cdxComponent, 0, len(inv.components)),
+	}
+
+	var metadata cdxMetadata
+	if !o.Timestamp.IsZero() {
+		metadata.Timestamp = o.Timestamp.UTC().Format(time.RFC3339)
+	}
+	name, version := sbomName(results, o.Name, o.Version)
+	if name != "" {
+		ref := name
+		if version != "" {
+			ref += "@" + version
+		}
+		metadata.Component = &cdxComponent{Type: "application", BOMRef: ref, Name: name, Version: version}
+	}
+	if metadata != (cdxMetadata{}) {
+		bom.Metadata = &metadata
+	}
+
+	for _, c := range inv.components {
+		bom.Components = append(bom.Components, cdxComponentFor(c))
+	}
+
+	if metadata.Component != nil {
+		if direct := inv.direct(results); len(direct) > 0 {
+			bom.Dependencies = append(bom.Dependencies, cdxDependency{
+				Ref:       metadata.Component.BOMRef,
+				DependsOn: cdxRefs(direct),
+			})
+		}
+	}
+	edges := inv.edges(results)
+	for _, c := range inv.components {
+		if children, ok := edges[c]; ok {
+			bom.Dependencies = append(bom.Dependencies, cdxDependency{Ref: c.ref, DependsOn: cdxRefs(children)})
+		}
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	return enc.Encode(bom)
+}
+
+func cdxComponentFor(c *sbomComponent) cdxComponent {
+	out := cdxComponent{
+		Type:    "library",
+		BOMRef:  c.ref,
+		Name:    c.dep.Name,
+		Version: c.version,
+		PURL:    c.dep.PURL,
+	}
+	switch c.dep.Scope {
+	case Runtime:
+		out.Scope = "required"
+	case Optional:
+		out.Scope = "optional"
+	case Development, Test, Build:
+		out.Scope = "excluded"
+	}
+	for _, h := range componentHashes(c.dep) {
+		out.Hashes = append(out.Hashes, cdxHash{Alg: cdxHashAlgs[h.alg], Content: h.digest})
+	}
+	switch {
+	case c.dep.License == "":
+	case isSPDXExpression(c.dep.License):
+		out.Licenses = []cdxLicense{{Expression: c.dep.License}}
+	default:
+		out.Licenses = []cdxLicense{{License: &cdxLicenseName{Name: c.dep.License}}}
+	}
+	if strings.HasPrefix(c.dep.RegistryURL, "https://") || strings.HasPrefix(c.dep.RegistryURL, "http://") {
+		out.ExternalReferences = []cdxExternalRef{{Type: "distribution", URL: c.dep.RegistryURL}}
+	}
+	return out
+}
+
+func cdxRefs(components []*sbomComponent) []string {
+	refs := make([]string, len(components))
+	for i, c := range components {
+		refs[i] = c.ref
+	}
+	return refs
+}
@@ -0,0 +1,314 @@
+package manifests
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"sort"
+	"testing"
+)
+
+func TestWriteCycloneDX(t *testing.T) {
+	manifest, err := Parse("package.json", []byte(projectPackageJSON))
+	if err != nil {
+		t.Fatalf("Parse manifest failed: %v", err)
+	}
+	lockfile, err := Parse("package-lock.json", []byte(projectPackageLock))
+	if err != nil {
+		t.Fatalf("Parse lockfile failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteCycloneDX(&buf, []*ParseResult{manifest, lockfile}, CycloneDXOptions{SpecVersion: "1.5"}); err != nil {
+		t.Fatalf("WriteCycloneDX failed: %v", err)
+	}
+
+	var bom struct {
+		BOMFormat   string `json:"bomFormat"`
+		SpecVersion string `json:"specVersion"`
+		Metadata    struct {
+			Component struct {
+				BOMRef string `json:"bom-ref"`
+				Name   string `json:"name"`
+			} `json:"component"`
+		} `json:"metadata"`
+		Components []struct {
+			BOMRef  string `json:"bom-ref"`
+			Name    string `json:"name"`
+			Version string `json:"version"`
+			Scope   string `json:"scope"`
+			PURL    string `json:"purl"`
+			Hashes  []struct {
+				Alg     string `json:"alg"`
+				Content string `json:"content"`
+			} `json:"hashes"`
+		} `json:"components"`
+		Dependencies []struct {
+			Ref       string   `json:"ref"`
+			DependsOn []string `json:"dependsOn"`
+		} `json:"dependencies"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &bom); err != nil {
+		t.Fatalf("output is not valid JSON: %v", err)
+	}
+	if bom.BOMFormat != "CycloneDX" || bom.SpecVersion != "1.5" {
+		t.Errorf("bomFormat %q, specVersion %q", bom.BOMFormat, bom.SpecVersion)
+	}
+	if bom.Metadata.Component.Name != "app" || bom.Metadata.Component.BOMRef != "app@1.0.0" {
+		t.Errorf("metadata component = %+v", bom.Metadata.Component)
+	}
+
+	// lodash, mocha and ms from the lockfile; left-pad unlocked
+	if len(bom.Components) != 4 {
+		t.Fatalf("expected 4 components, got %d", len(bom.Components))
+	}
+	lodash := bom.Components[0]
+	if lodash.BOMRef != "pkg:npm/lodash@4.17.21" || lodash.Version != "4.17.21" || lodash.Scope != "required" {
+		t.Errorf("lodash = %+v", lodash)
+	}
+	if len(lodash.Hashes) != 1 || lodash.Hashes[0].Alg != "SHA-512" ||
+		lodash.Hashes[0].Content != "bf690311ee7b95e713ba568322e3533f2dd1cb880b189e99d4edef13592b81764daec43e2c54c61d5c558dc5cfb35ecb85b65519e74026ff17675b6f8f916f4a" {
+		t.Errorf("lodash hashes = %+v", lodash.Hashes)
+	}
+	if mocha := bom.Components[1]; mocha.Name != "mocha" || mocha.Scope != "excluded" {
+		t.Errorf("mocha = %+v", mocha)
+	}
+	if leftPad := bom.Components[3]; leftPad.Name != "left-pad" || leftPad.Version != "" || leftPad.PURL != "pkg:npm/left-pad" {
+		t.Errorf("left-pad = %+v", leftPad)
+	}
+
+	deps := make(map[string][]string)
+	for _, d := range bom.Dependencies {
+		deps[d.Ref] = d.DependsOn
+	}
+	got := deps["app@1.0.0"]
+	sort.Strings(got)
+	if len(got) != 3 || got[0] != "pkg:npm/left-pad" || got[1] != "pkg:npm/lodash@4.17.21" || got[2] != "pkg:npm/mocha@10.2.0" {
+		t.Errorf("app dependsOn = %v", got)
+	}
+	if got := deps["pkg:npm/mocha@10.2.0"]; len(got) != 1 || got[0] != "pkg:npm/ms@2.1.3" {
+		t.Errorf("mocha dependsOn = %v", got)
+	}
+}
+
+func TestWriteCycloneDXCargo(t *testing.T) {
+	content, err := os.ReadFile("testdata/cargo/Cargo.lock")
+	if err != nil {
+		t.Fatalf("failed to read fixture: %v", err)
+	}
+	lockfile, err := Parse("Cargo.lock", content)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteCycloneDX(&buf, []*ParseResult{lockfile}); err != nil {
+		t.Fatalf("WriteCycloneDX failed: %v", err)
+	}
+	var bom struct {
+		SpecVersion string `json:"specVersion"`
+		Metadata    *struct{}
+		Components  []struct {
+			Hashes []struct {
+				Alg     string `json:"alg"`
+				Content string `json:"content"`
+			} `json:"hashes"`
+		} `json:"components"`
+		Dependencies []struct {
+			Ref string `json:"ref"`
+		} `json:"dependencies"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &bom); err != nil {
+		t.Fatalf("output is not valid JSON: %v", err)
+	}
+	if bom.SpecVersion != "1.6" || bom.Metadata != nil {
+		t.Errorf("specVersion %q, metadata %v", bom.SpecVersion, bom.Metadata)
+	}
+	if len(bom.Components) != len(lockfile.Dependencies) {
+		t.Errorf("expected %d components, got %d", len(lockfile.Dependencies), len(bom.Components))
+	}
+	hashed := 0
+	for _, c := range bom.Components {
+		for _, h := range c.Hashes {
+			if h.Alg != "SHA-256" || len(h.Content) != 64 {
+				t.Errorf("unexpected hash %+v", h)
+			}
+			hashed++
+		}
+	}
+	if hashed == 0 {
+		t.Error("expected Cargo.lock checksums as hashes")
+	}
+	if len(bom.Dependencies) == 0 {
+		t.Error("expected dependencies from the Cargo.lock graph")
+	}
+
+	if err := WriteCycloneDX(&buf, nil, CycloneDXOptions{SpecVersion: "1.2"}); err == nil {
+		t.Error("expected error for unsupported spec version")
+	}
+}
+
+func TestIntegrityHashes(t *testing.T) {
+	tests := []struct {
+		integrity string
+		alg       string
+		digest    string
+	}{
+		{"sha256-" + "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855", "sha256", "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"},
+		{"sha256-47DEQpj8HBSa+/TImW+5JCeuQeRkm5NMpJWZG3hSuFU=", "sha256", "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"},
+		{"sha1-DA39A3EE5E6B4B0D3255BFEF95601890AFD80709", "sha1", "da39a3ee5e6b4b0d3255bfef95601890afd80709"},
+		{"md5-d41d8cd98f00b204e9800998ecf8427e", "md5", "d41d8cd98f00b204e9800998ecf8427e"},
+		{"h1:47DEQpj8HBSa+/TImW+5JCeuQeRkm5NMpJWZG3hSuFU=", "", ""},
+		{"sha256-tooshort", "", ""},
+	}
+	for _, tt := range tests {
+		got := integrityHashes(tt.integrity)
+		if tt.alg == "" {
+			if len(got) != 0 {
+				t.Errorf("integrityHashes(%q) = %v, want none", tt.integrity, got)
+			}
+			continue
+		}
+		if len(got) != 1 || got[0].alg != tt.alg || got[0].digest != tt.digest {
+			t.Errorf("integrityHashes(%q) = %v, want %s %s", tt.integrity, got, tt.alg, tt.digest)
+		}
+	}
+}
+
+func TestWriteCycloneDXHashesAndLicenses(t *testing.T) {
+	const sha256 = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+	const wheel = "5feb91325bbceade6afab43eb3b508c63ee53579fe896c77137ded51c6b6958e"
+	lockfile := &ParseResult{
+		Ecosystem: "pypi",
+		Kind:      Lockfile,
+		Dependencies: []Dependency{
+			{
+				Name:      "requests",
+				Version:   "2.31.0",
+				PURL:      "pkg:pypi/requests@2.31.0",
+				Integrity: "sha256-" + sha256,
+				Hashes: []Hash{
+					{Algorithm: "sha256", Value: sha256, Artifact: "requests-2.31.0.tar.gz"},
+					{Algorithm: "sha256", Value: wheel, Artifact: "requests-2.31.0-py3-none-any.whl"},
+					{Algorithm: "blake2b", Value: "ignored"},
+				},
+				License: "Apache-2.0",
+			},
+			{Name: "idna", Version: "3.6", PURL: "pkg:pypi/idna@3.6", License: "(MIT OR Apache-2.0) AND BSD-3-Clause"},
+			{Name: "certifi", Version: "2024.2.2", PURL: "pkg:pypi/certifi@2024.2.2", License: "GPL (>= 2)"},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteCycloneDX(&buf, []*ParseResult{lockfile}); err != nil {
+		t.Fatalf("WriteCycloneDX failed: %v", err)
+	}
+	var bom struct {
+		Components []struct {
+			Name   string `json:"name"`
+			Hashes []struct {
+				Alg     string `json:"alg"`
+				Content string `json:"content"`
+			} `json:"hashes"`
+			Licenses []struct {
+				Expression string `json:"expression"`
+				License    *struct {
+					Name string `json:"name"`
+				} `json:"license"`
+			} `json:"licenses"`
+		} `json:"components"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &bom); err != nil {
+		t.Fatalf("output is not valid JSON: %v", err)
+	}
+	if len(bom.Components) != 3 {
+		t.Fatalf("expected 3 components, got %d", len(bom.Components))
+	}
+
+	requests := bom.Components[0]
+	if len(requests.Hashes) != 2 || requests.Hashes[0].Content != sha256 || requests.Hashes[1].Content != wheel {
+		t.Errorf("requests hashes = %+v, want the integrity hash and the wheel's", requests.Hashes)
+	}
+	if len(requests.Licenses) != 1 || requests.Licenses[0].Expression != "Apache-2.0" {
+		t.Errorf("requests licenses = %+v", requests.Licenses)
+	}
+	if idna := bom.Components[1]; len(idna.Licenses) != 1 || idna.Licenses[0].Expression != "(MIT OR Apache-2.0) AND BSD-3-Clause" {
+		t.Errorf("idna licenses = %+v", idna.Licenses)
+	}
+	certifi := bom.Components[2]
+	if len(certifi.Licenses) != 1 || certifi.Licenses[0].License == nil || certifi.Licenses[0].License.Name != "GPL (>= 2)" || certifi.Licenses[0].Expression != "" {
+		t.Errorf("certifi licenses = %+v", certifi.Licenses)
+	}
+	if len(certifi.Hashes) != 0 {
+		t.Errorf("certifi hashes = %+v, want none", certifi.Hashes)
+	}
+}
+
+func TestIsSPDXExpression(t *testing.T) {
+	tests := map[string]bool{
+		"MIT":                                  true,
+		"GPL-2.0+":                             true,
+		"MIT OR Apache-2.0":                    true,
+		"(MIT OR Apache-2.0) AND BSD-3-Clause": true,
+		"GPL-2.0-only WITH Classpath-exception-2.0": true,
+		"LicenseRef-Proprietary":                    true,
+		"":                                          false,
+		"GPL (>= 2)":                                false,
+		"MIT OR":                                    false,
+		"(MIT":                                      false,
+		"MIT Apache-2.0":                            false,
+		"BSD License":                               false,
+	}
+	for license, want := range tests {
+		if got := isSPDXExpression(license); got != want {
+			t.Errorf("isSPDXExpression(%q) = %v, want %v", license, got, want)
+		}
+	}
+}
+
+func TestCycloneDXRoundTrip(t *testing.T) {
+	content, err := os.ReadFile("testdata/cargo/Cargo.lock")
+	if err != nil {
+		t.Fatalf("failed to read fixture: %v", err)
+	}
+	lockfile, err := Parse("Cargo.lock", content)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteCycloneDX(&buf, []*ParseResult{lockfile}, CycloneDXOptions{Name: "app"}); err != nil {
+		t.Fatalf("WriteCycloneDX failed: %v", err)
+	}
+	bom, err := Parse("bom.json", buf.Bytes())
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if bom.Ecosystem != "cyclonedx" || bom.Kind != Lockfile || bom.Name != "app" {
+		t.Errorf("got ecosystem %q, kind %q, name %q", bom.Ecosystem, bom.Kind, bom.Name)
+	}
+	if len(bom.Dependencies) != len(lockfile.Dependencies) {
+		t.Fatalf("expected %d dependencies, got %d", len(lockfile.Dependencies), len(bom.Dependencies))
+	}
+
+	want := make(map[string]Dependency)
+	for _, dep := range lockfile.Dependencies {
+		want[dep.Name+"@"+dep.Version] = dep
+	}
+	for _, dep := range bom.Dependencies {
+		orig, ok := want[dep.Name+"@"+dep.Version]
+		if !ok {
+			t.Errorf("unexpected dependency %s@%s", dep.Name, dep.Version)
+			continue
+		}
+		if dep.Ecosystem != "cargo" {
+			t.Errorf("%s: Ecosystem = %q, want cargo", dep.Name, dep.Ecosystem)
+		}
+		if dep.PURL != orig.PURL {
+			t.Errorf("%s: PURL = %q, want %q", dep.Name, dep.PURL, orig.PURL)
+		}
+		if dep.Integrity != orig.Integrity {
+			t.Errorf("%s: Integrity = %q, want %q", dep.Name, dep.Integrity, orig.Integrity)
+		}
+	}
+}
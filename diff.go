@@ -0,0 +1,484 @@
+package manifests
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/git-pkgs/vers"
+)
+
+// ChangeKind classifies a difference between two parses of a file.
+type ChangeKind string
+
+const (
+	// Added means the package or version appears only in the new result.
+	Added ChangeKind = "added"
+	// Removed means the package or version appears only in the old result.
+	Removed ChangeKind = "removed"
+	// Upgraded means the package moved to a higher version.
+	Upgraded ChangeKind = "upgraded"
+	// Downgraded means the package moved to a lower version.
+	Downgraded ChangeKind = "downgraded"
+	// Changed means the version changed but the two can't be ordered,
+	// e.g. git revisions or non-version requirements.
+	Changed ChangeKind = "changed"
+	// ScopeChanged means the package moved between scopes.
+	ScopeChanged ChangeKind = "scope-changed"
+	// IntegrityChanged means the same version now has a different hash.
+	IntegrityChanged ChangeKind = "integrity-changed"
+)
+
+// Bump classifies a version change by the most significant part that
+// changed. PrereleaseBump covers versions that differ only in their
+// suffix, such as 1.0.0-rc.1 to 1.0.0.
+type Bump string
+
+const (
+	MajorBump      Bump = "major"
+	MinorBump      Bump = "minor"
+	PatchBump      Bump = "patch"
+	PrereleaseBump Bump = "prerelease"
+)
+
+// Change is one difference between two parses.
+type Change struct {
+	Kind ChangeKind
+	Name string
+	// Bump is set for Upgraded and Downgraded changes between versions
+	// with numeric parts. Changes past the third part count as patch.
+	Bump Bump
+	// Old and New are the entries on each side. Old is nil for Added and
+	// New is nil for Removed.
+	Old *Dependency
+	New *Dependency
+}
+
+// Diff compares two parses of the same file, e.g. a lockfile before and
+// after a change, and reports what happened to each dependency. Either
+// result may be nil, which is treated as empty; both must come from the
+// same ecosystem.
+//
+// Packages are matched by name (normalised as in Pair), and a package
+// may hold several versions at once, as in Cargo.lock or
+// package-lock.json. Versions present on both sides are unchanged apart
+// from ScopeChanged and IntegrityChanged. Of the rest, an old version is
+// paired with a new version of the same major version where there is
+// one, and a lone old version with a lone new version otherwise; unpaired
+// versions are Removed or Added. A dependency whose version moved and
+// whose scope changed yields both changes.
+//
+// Manifest requirements are compared the same way, with a leading
+// operator ("^1.2", "~> 1.2", ">=1.2") ignored when ordering them.
+//
+// Changes are grouped by package, in the order packages first appear in
+// old and then new.
+func Diff(before, after *ParseResult) ([]Change, error) {
+	if before != nil && after != nil && before.Ecosystem != after.Ecosystem {
+		return nil, fmt.Errorf("diff: ecosystems differ: %s before, %s after", before.Ecosystem, after.Ecosystem)
+	}
+	var ecosystem string
+	var oldDeps, newDeps []Dependency
+	if before != nil {
+		ecosystem = before.Ecosystem
+		oldDeps = before.Dependencies
+	}
+	if after != nil {
+		ecosystem = after.Ecosystem
+		newDeps = after.Dependencies
+	}
+
+	var names []string
+	oldByName := diffVersions(ecosystem, oldDeps, &names)
+	newByName := diffVersions(ecosystem, newDeps, &names)
+
+	var changes []Change
+	for _, name := range names {
+		changes = diffPackage(changes, ecosystem, oldByName[name], newByName[name])
+	}
+	return changes, nil
+}
+
+// diffVersions groups dependencies by normalised name, keeping the first
+// entry for each version and sorting each group by version. Names not
+// yet in names are appended to it.
+func diffVersions(ecosystem string, deps []Dependency, names *[]string) map[string][]*Dependency {
+	byName := make(map[string][]*Dependency)
+	seen := make(map[[2]string]bool)
+	known := make(map[string]bool, len(*names))
+	for _, n := range *names {
+		known[n] = true
+	}
+	for i := range deps {
+		d := &deps[i]
+		key := projectKey(ecosystem, d.Name)
+		if seen[[2]string{key, d.Version}] {
+			continue
+		}
+		seen[[2]string{key, d.Version}] = true
+		byName[key] = append(byName[key], d)
+		if !known[key] {
+			known[key] = true
+			*names = append(*names, key)
+		}
+	}
+	for _, group := range byName {
+		sort.SliceStable(group, func(i, j int) bool {
+			return compareVersions(ecosystem, group[i].Version, group[j].Version) < 0
+		})
+	}
+	return byName
+}
+
+// diffPackage appends the changes between the old and new versions of
+// one package.
+func diffPackage(changes []Change, ecosystem string, oldDeps, newDeps []*Dependency) []Change {
+	newByVersion := make(map[string]*Dependency, len(newDeps))
+	for _, d := range newDeps {
+		newByVersion[d.Version] = d
+	}
+	oldVersions := make(map[string]bool, len(oldDeps))
+	var removed, added []*Dependency
+	for _, o := range oldDeps {
+		oldVersions[o.Version] = true
+		if n, ok := newByVersion[o.Version]; ok {
+			changes = appendAttributeChanges(changes, o, n)
+		} else {
+			removed = append(removed, o)
+		}
+	}
+	for _, n := range newDeps {
+		if !oldVersions[n.Version] {
+			added = append(added, n)
+		}
+	}
+
+	// Pair versions within a major line first, so 1.x -> 1.y and
+	// 2.x -> 2.y are upgrades even when both lines are locked.
+	pairedNew := make([]bool, len(added))
+	var unpaired []*Dependency
+	for _, o := range removed {
+		match := -1
+		if major, ok := majorVersion(o.Version); ok {
+			for j, n := range added {
+				if m, ok := majorVersion(n.Version); ok && !pairedNew[j] && m == major {
+					match = j
+					break
+				}
+			}
+		}
+		if match < 0 {
+			unpaired = append(unpaired, o)
+			continue
+		}
+		pairedNew[match] = true
+		changes = appendVersionChange(changes, ecosystem, o, added[match])
+	}
+	var remaining []*Dependency
+	for j, n := range added {
+		if !pairedNew[j] {
+			remaining = append(remaining, n)
+		}
+	}
+	if len(unpaired) == 1 && len(remaining) == 1 {
+		return appendVersionChange(changes, ecosystem, unpaired[0], remaining[0])
+	}
+	for _, o := range unpaired {
+		changes = append(changes, Change{Kind: Removed, Name: o.Name, Old: o})
+	}
+	for _, n := range remaining {
+		changes = append(changes, Change{Kind: Added, Name: n.Name, New: n})
+	}
+	return changes
+}
+
+func appendVersionChange(changes []Change, ecosystem string, o, n *Dependency) []Change {
+	c := Change{Kind: Changed, Name: n.Name, Old: o, New: n}
+	if _, _, ok := splitVersion(o.Version); ok {
+		if _, _, ok := splitVersion(n.Version); ok {
+			switch compareVersions(ecosystem, o.Version, n.Version) {
+			case -1:
+				c.Kind = Upgraded
+			case 1:
+				c.Kind = Downgraded
+			}
+			if c.Kind != Changed {
+				c.Bump = versionBump(o.Version, n.Version)
+			}
+		}
+	}
+	changes = append(changes, c)
+	if o.Scope != n.Scope {
+		changes = append(changes, Change{Kind: ScopeChanged, Name: n.Name, Old: o, New: n})
+	}
+	return changes
+}
+
+// appendAttributeChanges compares two entries for the same version. A
+// hash that appears or disappears isn't reported, only one that differs.
+func appendAttributeChanges(changes []Change, o, n *Dependency) []Change {
+	if o.Scope != n.Scope {
+		changes = append(changes, Change{Kind: ScopeChanged, Name: n.Name, Old: o, New: n})
+	}
+	if o.Integrity != "" && n.Integrity != "" && o.Integrity != n.Integrity {
+		changes = append(changes, Change{Kind: IntegrityChanged, Name: n.Name, Old: o, New: n})
+	}
+	return changes
+}
+
+// splitVersion splits a version into its numeric release parts and the
+// pre-release suffix that follows, ignoring a leading "v" or requirement
+// operator and any "+build" metadata. ok is false if the version doesn't
+// start with a number or is a compound requirement.
+func splitVersion(v string) (release []int, suffix string, ok bool) {
+	v = strings.TrimLeft(strings.TrimSpace(v), "^~=>v ")
+	if v == "" || v[0] < '0' || v[0] > '9' || strings.ContainsAny(v, " ,|<*") {
+		return nil, "", false
+	}
+	if i := strings.IndexByte(v, '+'); i >= 0 {
+		v = v[:i]
+	}
+	end := 0
+	for end < len(v) && (v[end] == '.' || v[end] >= '0' && v[end] <= '9') {
+		end++
+	}
+	for _, part := range strings.Split(strings.Trim(v[:end], "."), ".") {
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			return nil, "", false
+		}
+		release = append(release, n)
+	}
+	return release, strings.TrimLeft(v[end:], "-._"), true
+}
+
+func majorVersion(v string) (int, bool) {
+	release, _, ok := splitVersion(v)
+	if !ok {
+		return 0, false
+	}
+	return release[0], true
+}
+
+// versionBump returns the most significant part that differs between
+// two versions.
+func versionBump(a, b string) Bump {
+	ra, sa, _ := splitVersion(a)
+	rb, sb, _ := splitVersion(b)
+	for i := 0; i < len(ra) || i < len(rb); i++ {
+		if releasePart(ra, i) == releasePart(rb, i) {
+			continue
+		}
+		switch i {
+		case 0:
+			return MajorBump
+		case 1:
+			return MinorBump
+		default:
+			return PatchBump
+		}
+	}
+	if sa != sb {
+		return PrereleaseBump
+	}
+	return ""
+}
+
+func releasePart(release []int, i int) int {
+	if i < len(release) {
+		return release[i]
+	}
+	return 0
+}
+
+// versCompareEcosystems are the ecosystems whose versions vers orders
+// by the ecosystem's own rules: Maven and NuGet with their qualifier
+// rules, and the rest as semantic versions.
+var versCompareEcosystems = map[string]bool{
+	"maven":  true,
+	"nuget":  true,
+	"npm":    true,
+	"bower":  true,
+	"deno":   true,
+	"cargo":  true,
+	"golang": true,
+	"hex":    true,
+	"pub":    true,
+}
+
+// compareVersions orders two versions of a package, returning -1, 0 or
+// 1. Ecosystems vers knows the ordering of use it. Elsewhere release
+// parts compare numerically, a pre-release sorts before its release,
+// and PyPI versions follow PEP 440 for pre-, post- and dev-releases.
+// Versions that don't start with a number sort before those that do,
+// and among themselves by string.
+func compareVersions(ecosystem, a, b string) int {
+	if a == b {
+		return 0
+	}
+	ra, sa, okA := splitVersion(a)
+	rb, sb, okB := splitVersion(b)
+	switch {
+	case !okA && !okB:
+		return strings.Compare(a, b)
+	case !okA:
+		return -1
+	case !okB:
+		return 1
+	}
+	if versCompareEcosystems[ecosystem] {
+		return vers.CompareWithScheme(bareVersion(a), bareVersion(b), ecosystem)
+	}
+	for i := 0; i < len(ra) || i < len(rb); i++ {
+		if c := cmpInt(releasePart(ra, i), releasePart(rb, i)); c != 0 {
+			return c
+		}
+	}
+	if ecosystem == "pypi" {
+		if c, ok := comparePEP440Suffix(sa, sb); ok {
+			return c
+		}
+	}
+	if c := cmpInt(suffixRank(ecosystem, sa), suffixRank(ecosystem, sb)); c != 0 {
+		return c
+	}
+	return compareSuffix(sa, sb)
+}
+
+// bareVersion strips the requirement operators a manifest may put
+// before a version, as splitVersion does.
+func bareVersion(v string) string {
+	return strings.TrimLeft(strings.TrimSpace(v), "^~=> ")
+}
+
+// suffixRank places a version relative to its release: -1 for a
+// pre-release, 0 for the release itself, 1 for a post-release.
+func suffixRank(ecosystem, suffix string) int {
+	switch {
+	case suffix == "":
+		return 0
+	case ecosystem == "pypi" && strings.HasPrefix(suffix, "post"):
+		return 1
+	}
+	return -1
+}
+
+// pep440Suffix matches the pre-, post- and dev-release segments that
+// follow a PEP 440 release, once pep440Spellings has normalised them.
+var pep440Suffix = regexp.MustCompile(`^(?:(a|b|rc)[-_.]?(\d*))?[-_.]?(?:(post)[-_.]?(\d*))?[-_.]?(?:(dev)[-_.]?(\d*))?$`)
+
+// pep440Spellings maps the alternative spellings PEP 440 allows to
+// their normal form. At each position the first match in this list
+// wins, so "rc" and "post" are kept before "c" and "r" are rewritten.
+var pep440Spellings = strings.NewReplacer(
+	"rc", "rc", "post", "post", "preview", "rc", "pre", "rc", "alpha", "a", "beta", "b",
+	"rev", "post", "c", "rc", "r", "post",
+)
+
+// pep440Phases ranks pre-release phases.
+var pep440Phases = map[string]int{"a": 0, "b": 1, "rc": 2}
+
+// comparePEP440Suffix orders the suffixes of two PyPI versions with the
+// same release: a dev-release of the release first, then pre-releases
+// by phase, the release, and post-releases, with a dev-release before
+// whatever it's a dev-release of. ok is false if either suffix isn't
+// PEP 440.
+func comparePEP440Suffix(a, b string) (int, bool) {
+	ka, okA := pep440Key(a)
+	kb, okB := pep440Key(b)
+	if !okA || !okB {
+		return 0, false
+	}
+	for i := range ka {
+		if c := cmpInt(ka[i], kb[i]); c != 0 {
+			return c, true
+		}
+	}
+	return 0, true
+}
+
+// pep440Key returns a suffix's sort key: pre-release phase and number,
+// post-release number and dev-release number.
+func pep440Key(suffix string) ([4]int, bool) {
+	const (
+		absent = -1          // no post-release, or a bare dev-release
+		last   = math.MaxInt // no pre-release or dev-release
+	)
+	m := pep440Suffix.FindStringSubmatch(pep440Spellings.Replace(strings.ToLower(suffix)))
+	if m == nil {
+		return [4]int{}, false
+	}
+	pre, preN, post, postN, dev, devN := m[1], m[2], m[3], m[4], m[5], m[6]
+
+	key := [4]int{last, 0, absent, last}
+	switch {
+	case pre != "":
+		key[0] = pep440Phases[pre]
+		key[1], _ = strconv.Atoi(preN)
+	case post == "" && dev != "":
+		// X.devN precedes every pre-release of X
+		key[0] = absent
+	}
+	if post != "" {
+		key[2], _ = strconv.Atoi(postN)
+	}
+	if dev != "" {
+		key[3], _ = strconv.Atoi(devN)
+	}
+	return key, true
+}
+
+// compareSuffix compares pre-release suffixes, with runs of digits
+// compared numerically so that rc10 sorts after rc9.
+func compareSuffix(a, b string) int {
+	for a != "" && b != "" {
+		ca, restA := suffixChunk(a)
+		cb, restB := suffixChunk(b)
+		na, errA := strconv.Atoi(ca)
+		nb, errB := strconv.Atoi(cb)
+		var c int
+		switch {
+		case errA == nil && errB == nil:
+			c = cmpInt(na, nb)
+		case errA == nil:
+			c = -1
+		case errB == nil:
+			c = 1
+		default:
+			c = strings.Compare(ca, cb)
+		}
+		if c != 0 {
+			return c
+		}
+		a, b = restA, restB
+	}
+	return cmpInt(len(a), len(b))
+}
+
+// suffixChunk splits off a leading run of digits or of other
+// characters, skipping separators.
+func suffixChunk(s string) (string, string) {
+	s = strings.TrimLeft(s, "-._")
+	if s == "" {
+		return "", ""
+	}
+	digit := s[0] >= '0' && s[0] <= '9'
+	i := 1
+	for i < len(s) && s[i] != '-' && s[i] != '.' && s[i] != '_' && (s[i] >= '0' && s[i] <= '9') == digit {
+		i++
+	}
+	return s[:i], s[i:]
+}
+
+func cmpInt(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
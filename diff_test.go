@@ -0,0 +1,152 @@
+package manifests
+
+import "testing"
+
+func diffSummary(changes []Change) []string {
+	var out []string
+	for _, c := range changes {
+		s := string(c.Kind) + " " + c.Name
+		if c.Old != nil {
+			s += " " + c.Old.Version
+		}
+		if c.New != nil {
+			s += " -> " + c.New.Version
+		}
+		if c.Bump != "" {
+			s += " (" + string(c.Bump) + ")"
+		}
+		out = append(out, s)
+	}
+	return out
+}
+
+func TestDiff(t *testing.T) {
+	before := &ParseResult{
+		Ecosystem: "cargo",
+		Kind:      Lockfile,
+		Dependencies: []Dependency{
+			{Name: "serde", Version: "1.0.190", Scope: Runtime},
+			{Name: "rand", Version: "0.7.3", Scope: Runtime},
+			{Name: "rand", Version: "0.8.5", Scope: Runtime},
+			{Name: "syn", Version: "1.0.109", Scope: Runtime},
+			{Name: "syn", Version: "2.0.39", Scope: Runtime},
+			{Name: "log", Version: "0.4.20", Scope: Runtime, Integrity: "sha256-aaa"},
+			{Name: "tokio", Version: "1.35.0", Scope: Runtime},
+			{Name: "regex", Version: "1.10.2", Scope: Runtime},
+			{Name: "time", Version: "0.3.30", Scope: Runtime},
+			{Name: "mio", Version: "0.8.9", Scope: Runtime},
+		},
+	}
+	after := &ParseResult{
+		Ecosystem: "cargo",
+		Kind:      Lockfile,
+		Dependencies: []Dependency{
+			{Name: "serde", Version: "2.0.0", Scope: Runtime},
+			{Name: "rand", Version: "0.7.3", Scope: Runtime},
+			{Name: "rand", Version: "0.8.6", Scope: Runtime},
+			{Name: "syn", Version: "2.0.39", Scope: Runtime},
+			{Name: "log", Version: "0.4.20", Scope: Development, Integrity: "sha256-bbb"},
+			{Name: "tokio", Version: "1.34.0", Scope: Runtime},
+			{Name: "regex", Version: "1.11.0-rc.1", Scope: Runtime},
+			{Name: "time", Version: "0.3.30", Scope: Runtime},
+			{Name: "libc", Version: "0.2.150", Scope: Runtime},
+			{Name: "mio", Version: "0.8.9-alpha.2", Scope: Runtime},
+		},
+	}
+
+	changes, err := Diff(before, after)
+	if err != nil {
+		t.Fatalf("Diff failed: %v", err)
+	}
+	want := []string{
+		"upgraded serde 1.0.190 -> 2.0.0 (major)",
+		"upgraded rand 0.8.5 -> 0.8.6 (patch)",
+		"removed syn 1.0.109",
+		"scope-changed log 0.4.20 -> 0.4.20",
+		"integrity-changed log 0.4.20 -> 0.4.20",
+		"downgraded tokio 1.35.0 -> 1.34.0 (minor)",
+		"upgraded regex 1.10.2 -> 1.11.0-rc.1 (minor)",
+		"downgraded mio 0.8.9 -> 0.8.9-alpha.2 (prerelease)",
+		"added libc -> 0.2.150",
+	}
+	got := diffSummary(changes)
+	if len(got) != len(want) {
+		t.Fatalf("changes = %q, want %q", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("changes[%d] = %q, want %q", i, got[i], want[i])
+		}
+	}
+}
+
+func TestDiffVersionOrder(t *testing.T) {
+	tests := []struct {
+		ecosystem string
+		old, new  string
+		want      string
+	}{
+		{"npm", "1.2.3", "1.2.10", "upgraded x 1.2.3 -> 1.2.10 (patch)"},
+		{"npm", "1.0.0-rc.9", "1.0.0-rc.10", "upgraded x 1.0.0-rc.9 -> 1.0.0-rc.10 (prerelease)"},
+		{"npm", "^1.2.0", "^2.0.0", "upgraded x ^1.2.0 -> ^2.0.0 (major)"},
+		{"pypi", "2.0rc1", "2.0", "upgraded x 2.0rc1 -> 2.0 (prerelease)"},
+		{"pypi", "2.0.post1", "2.0", "downgraded x 2.0.post1 -> 2.0 (prerelease)"},
+		{"pypi", "1.0.dev1", "1.0a1", "upgraded x 1.0.dev1 -> 1.0a1 (prerelease)"},
+		{"pypi", "1.0a1", "1.0a1.post1", "upgraded x 1.0a1 -> 1.0a1.post1 (prerelease)"},
+		{"pypi", "1.0a2", "1.0b1", "upgraded x 1.0a2 -> 1.0b1 (prerelease)"},
+		{"pypi", "1.0b2", "1.0rc1", "upgraded x 1.0b2 -> 1.0rc1 (prerelease)"},
+		{"pypi", "1.0rc1", "1.0c2", "upgraded x 1.0rc1 -> 1.0c2 (prerelease)"},
+		{"pypi", "1.0rc1.dev3", "1.0rc1", "upgraded x 1.0rc1.dev3 -> 1.0rc1 (prerelease)"},
+		{"pypi", "1.0-alpha.2", "1.0a10", "upgraded x 1.0-alpha.2 -> 1.0a10 (prerelease)"},
+		{"pypi", "1.0.post1.dev1", "1.0.post1", "upgraded x 1.0.post1.dev1 -> 1.0.post1 (prerelease)"},
+		{"pypi", "1.0.post1.dev1", "1.0", "downgraded x 1.0.post1.dev1 -> 1.0 (prerelease)"},
+		{"pypi", "1.0.dev2", "1.0.dev10", "upgraded x 1.0.dev2 -> 1.0.dev10 (prerelease)"},
+		{"cargo", "1.0.0-alpha.9", "1.0.0-alpha.10", "upgraded x 1.0.0-alpha.9 -> 1.0.0-alpha.10 (prerelease)"},
+		{"golang", "v1.9.0", "v1.10.0", "upgraded x v1.9.0 -> v1.10.0 (minor)"},
+		{"nuget", "2.0.0-BETA", "2.0.0", "upgraded x 2.0.0-BETA -> 2.0.0 (prerelease)"},
+		{"gem", "7.0.8.1", "7.0.8.4", "upgraded x 7.0.8.1 -> 7.0.8.4 (patch)"},
+		{"maven", "1.0-SNAPSHOT", "1.0", "upgraded x 1.0-SNAPSHOT -> 1.0 (prerelease)"},
+		{"golang", "v0.0.0-20230101000000-abcdef", "v0.1.0", "upgraded x v0.0.0-20230101000000-abcdef -> v0.1.0 (minor)"},
+		{"npm", "git+https://github.com/a/x.git#abc", "git+https://github.com/a/x.git#def", "changed x git+https://github.com/a/x.git#abc -> git+https://github.com/a/x.git#def"},
+	}
+	for _, tt := range tests {
+		changes, err := Diff(
+			&ParseResult{Ecosystem: tt.ecosystem, Dependencies: []Dependency{{Name: "x", Version: tt.old}}},
+			&ParseResult{Ecosystem: tt.ecosystem, Dependencies: []Dependency{{Name: "x", Version: tt.new}}},
+		)
+		if err != nil {
+			t.Fatalf("Diff failed: %v", err)
+		}
+		got := diffSummary(changes)
+		if len(got) != 1 || got[0] != tt.want {
+			t.Errorf("%s %s -> %s: got %q, want %q", tt.ecosystem, tt.old, tt.new, got, tt.want)
+		}
+	}
+}
+
+func TestDiffLockfile(t *testing.T) {
+	before, err := Parse("package-lock.json", []byte(projectPackageLock))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	changes, err := Diff(nil, before)
+	if err != nil {
+		t.Fatalf("Diff failed: %v", err)
+	}
+	if len(changes) != 3 {
+		t.Errorf("expected every package added, got %q", diffSummary(changes))
+	}
+	for _, c := range changes {
+		if c.Kind != Added || c.Old != nil {
+			t.Errorf("expected added, got %+v", c)
+		}
+	}
+
+	if changes, _ := Diff(before, before); len(changes) != 0 {
+		t.Errorf("expected no changes diffing a result with itself, got %q", diffSummary(changes))
+	}
+	if _, err := Diff(before, &ParseResult{Ecosystem: "cargo"}); err == nil {
+		t.Error("expected error diffing different ecosystems")
+	}
+}
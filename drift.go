@@ -0,0 +1,124 @@
+package manifests
+
+// DriftKind classifies an inconsistency between a manifest and its
+// lockfile.
+type DriftKind string
+
+const (
+	// NotLocked means the manifest declares a dependency the lockfile
+	// doesn't contain.
+	NotLocked DriftKind = "not-locked"
+	// NotDeclared means the lockfile records a direct dependency the
+	// manifest no longer declares.
+	NotDeclared DriftKind = "not-declared"
+	// Unsatisfied means the locked version falls outside the declared
+	// version range.
+	Unsatisfied DriftKind = "unsatisfied"
+)
+
+// Drift is one inconsistency between a manifest and its lockfile.
+type Drift struct {
+	Kind DriftKind
+	Name string
+	// Constraint is the declared requirement, empty for NotDeclared.
+	Constraint string
+	// Version is the locked version, empty for NotLocked.
+	Version  string
+	Declared *Dependency
+	Locked   *Dependency
+}
+
+// CheckDrift pairs a manifest with its lockfile and reports where they
+// disagree. See Project.Drift.
+func CheckDrift(manifest, lockfile *ParseResult) ([]Drift, error) {
+	p, err := Pair(manifest, lockfile)
+	if err != nil {
+		return nil, err
+	}
+	return p.Drift(), nil
+}
+
+// Drift reports where the project's lockfile is out of step with its
+// manifest: declared dependencies missing from the lockfile, locked
+// versions that don't satisfy the declared range, and direct
+// dependencies the lockfile still records but the manifest has dropped.
+//
+// Range checks are skipped for requirements that aren't version ranges
+// (git, path, dist-tags). NotDeclared needs the lockfile to say which
+// packages are direct, either through its graph roots (package-lock.json,
+// pnpm-lock.yaml, Cargo.lock, Gemfile.lock) or its own direct flags
+// (pubspec.lock, uv.lock); for other lockfiles it isn't reported. In a
+// workspace the roots include every member's dependencies, so pair each
+// member's manifest with the shared lockfile to avoid false reports.
+//
+// Returns nil when the project has no manifest or no lockfile.
+func (p *Project) Drift() []Drift {
+	if p.Manifest == nil || p.Lockfile == nil {
+		return nil
+	}
+
+	var drift []Drift
+	for i := range p.Dependencies {
+		d := &p.Dependencies[i]
+		switch {
+		case d.Declared != nil && d.Locked == nil:
+			drift = append(drift, Drift{
+				Kind:       NotLocked,
+				Name:       d.Name,
+				Constraint: d.Constraint,
+				Declared:   d.Declared,
+			})
+		case d.Declared != nil && d.Declared.Range != nil && !d.Declared.Range.Satisfies(d.Locked.Version):
+			drift = append(drift, Drift{
+				Kind:       Unsatisfied,
+				Name:       d.Name,
+				Constraint: d.Constraint,
+				Version:    d.Locked.Version,
+				Declared:   d.Declared,
+				Locked:     d.Locked,
+			})
+		}
+	}
+
+	declared := make(map[string]bool, len(p.Manifest.Dependencies))
+	for _, d := range p.Manifest.Dependencies {
+		declared[projectKey(p.Ecosystem, d.Name)] = true
+	}
+	lockedDirect := p.lockedDirect()
+	for i := range p.Lockfile.Dependencies {
+		l := &p.Lockfile.Dependencies[i]
+		key := projectKey(p.Ecosystem, l.Name)
+		if !lockedDirect(l) || declared[key] {
+			continue
+		}
+		// Report each dropped package once, however many versions are locked
+		declared[key] = true
+		drift = append(drift, Drift{
+			Kind:    NotDeclared,
+			Name:    l.Name,
+			Version: l.Version,
+			Locked:  l,
+		})
+	}
+	return drift
+}
+
+// lockedDirect returns a predicate for whether the lockfile itself
+// records a package as a direct dependency.
+func (p *Project) lockedDirect() func(*Dependency) bool {
+	if p.Lockfile.Graph != nil && len(p.Lockfile.Graph.Roots) > 0 {
+		roots := make(map[Node]bool, len(p.Lockfile.Graph.Roots))
+		for _, r := range p.Lockfile.Graph.Roots {
+			roots[r] = true
+		}
+		return func(d *Dependency) bool {
+			return roots[Node{Name: d.Name, Version: d.Version}]
+		}
+	}
+	switch p.Ecosystem {
+	case "npm":
+		// package-lock.json marks hoisted packages as direct
+		return func(*Dependency) bool { return false }
+	}
+	return func(d *Dependency) bool { return d.Direct }
+}
@@ -0,0 +1,389 @@
+package manifests
+
+import (
+	"sort"
+	"testing"
+)
+
+func driftSummary(drift []Drift) []string {
+	var out []string
+	for _, d := range drift {
+		out = append(out, string(d.Kind)+" "+d.Name+" "+d.Constraint+" "+d.Version)
+	}
+	sort.Strings(out)
+	return out
+}
+
+func checkDrift(t *testing.T, manifestPath, manifest, lockfilePath, lockfile string, want []string) {
+	t.Helper()
+	m, err := Parse(manifestPath, []byte(manifest))
+	if err != nil {
+		t.Fatalf("Parse %s failed: %v", manifestPath, err)
+	}
+	l, err := Parse(lockfilePath, []byte(lockfile))
+	if err != nil {
+		t.Fatalf("Parse %s failed: %v", lockfilePath, err)
+	}
+	drift, err := CheckDrift(m, l)
+	if err != nil {
+		t.Fatalf("CheckDrift failed: %v", err)
+	}
+	got := driftSummary(drift)
+	if len(got) != len(want) {
+		t.Fatalf("drift = %q, want %q", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("drift[%d] = %q, want %q", i, got[i], want[i])
+		}
+	}
+}
+
+func TestDriftNPM(t *testing.T) {
+	manifest := `{
+  "name": "app",
+  "dependencies": {
+    "lodash": "^2.0.0",
+    "left-pad": "^1.0.0",
+    "react": "latest"
+  }
+}`
+	lockfile := `{
+  "name": "app",
+  "lockfileVersion": 3,
+  "packages": {
+    "": {
+      "name": "app",
+      "dependencies": {
+        "lodash": "^2.0.0",
+        "react": "latest",
+        "chalk": "^5.0.0"
+      }
+    },
+    "node_modules/lodash": {
+      "version": "1.9.3"
+    },
+    "node_modules/react": {
+      "version": "18.2.0"
+    },
+    "node_modules/chalk": {
+      "version": "5.3.0"
+    },
+    "node_modules/ansi-styles": {
+      "version": "6.2.1"
+    }
+  }
+}`
+	checkDrift(t, "package.json", manifest, "package-lock.json", lockfile, []string{
+		"not-declared chalk  5.3.0",
+		"not-locked left-pad ^1.0.0 ",
+		"unsatisfied lodash ^2.0.0 1.9.3",
+	})
+}
+
+func TestDriftBundler(t *testing.T) {
+	manifest := `source "https://rubygems.org"
+
+gem "rails", "~> 7.1"
+gem "puma"
+`
+	lockfile := `GEM
+  remote: https://rubygems.org/
+  specs:
+    nio4r (2.7.0)
+    puma (6.4.0)
+      nio4r (~> 2.0)
+    rails (7.0.8)
+    sidekiq (7.2.0)
+
+PLATFORMS
+  ruby
+
+DEPENDENCIES
+  puma
+  rails (~> 7.0)
+  sidekiq
+
+BUNDLED WITH
+   2.5.3
+`
+	checkDrift(t, "Gemfile", manifest, "Gemfile.lock", lockfile, []string{
+		"not-declared sidekiq  7.2.0",
+		"unsatisfied rails ~> 7.1 7.0.8",
+	})
+}
+
+func TestDriftPub(t *testing.T) {
+	manifest := `name: app
+dependencies:
+  http: ^1.0.0
+dev_dependencies:
+  test: ^1.24.0
+`
+	lockfile := `packages:
+  http:
+    dependency: "direct main"
+    description:
+      name: http
+      url: "https://pub.dev"
+    source: hosted
+    version: "0.13.6"
+  path:
+    dependency: "direct main"
+    description:
+      name: path
+      url: "https://pub.dev"
+    source: hosted
+    version: "1.9.0"
+  meta:
+    dependency: transitive
+    description:
+      name: meta
+      url: "https://pub.dev"
+    source: hosted
+    version: "1.11.0"
+sdks:
+  dart: ">=3.0.0 <4.0.0"
+`
+	checkDrift(t, "pubspec.yaml", manifest, "pubspec.lock", lockfile, []string{
+		"not-declared path  1.9.0",
+		"not-locked test ^1.24.0 ",
+		"unsatisfied http ^1.0.0 0.13.6",
+	})
+}
+
+func TestDriftInSync(t *testing.T) {
+	checkDrift(t, "package.json", projectPackageJSON, "package-lock.json", projectPackageLock, []string{
+		"not-locked left-pad ^1.0.0 ",
+	})
+
+	p, err := Pair(nil, &ParseResult{Ecosystem: "npm", Kind: Lockfile})
+	if err != nil {
+		t.Fatalf("Pair failed: %v", err)
+	}
+	if drift := p.Drift(); drift != nil {
+		t.Errorf("expected no drift without a manifest, got %v", drift)
+	}
+}
+
+func TestDriftYarn(t *testing.T) {
+	manifest := `{
+  "dependencies": {
+    "lodash": "^4.17.0",
+    "left-pad": "^1.0.0"
+  },
+  "devDependencies": {
+    "jest": "^29.0.0"
+  }
+}`
+	lockfile := `# THIS IS AN AUTOGENERATED FILE. DO NOT EDIT THIS FILE DIRECTLY.
+# yarn lockfile v1
+
+
+jest@^28.0.0:
+  version "28.1.3"
+  resolved "https://registry.yarnpkg.com/jest/-/jest-28.1.3.tgz"
+
+lodash@^4.17.0:
+  version "4.17.21"
+  resolved "https://registry.yarnpkg.com/lodash/-/lodash-4.17.21.tgz"
+`
+	checkDrift(t, "package.json", manifest, "yarn.lock", lockfile, []string{
+		"not-locked left-pad ^1.0.0 ",
+		"unsatisfied jest ^29.0.0 28.1.3",
+	})
+}
+
+func TestDriftPnpm(t *testing.T) {
+	manifest := `{
+  "dependencies": {
+    "react": "^18.0.0"
+  },
+  "devDependencies": {
+    "typescript": "^5.0.0"
+  }
+}`
+	lockfile := `lockfileVersion: '9.0'
+
+importers:
+
+  .:
+    dependencies:
+      react:
+        specifier: ^18.0.0
+        version: 17.0.2
+      lodash:
+        specifier: ^4.17.0
+        version: 4.17.21
+
+packages:
+
+  lodash@4.17.21:
+    resolution: {integrity: sha512-v2kDEe57lecTulaDIuNTPy3Ry4gLGJ6Z1O3vE1krgXZNrsQ+LFTGHVxVjcXPs17LhbZVGedAJv8XZ1tvj5FvSg==}
+
+  react@17.0.2:
+    resolution: {integrity: sha512-gnhPt75i/dq/z3/6q/0asP78D0u592D5L1pd7M8P+dck6Fu/jJeL6iVVK23fptSUZj8Vjf++7wXA8UNclGQcbA==}
+
+snapshots:
+
+  lodash@4.17.21: {}
+
+  react@17.0.2: {}
+`
+	checkDrift(t, "package.json", manifest, "pnpm-lock.yaml", lockfile, []string{
+		"not-declared lodash  4.17.21",
+		"not-locked typescript ^5.0.0 ",
+		"unsatisfied react ^18.0.0 17.0.2",
+	})
+}
+
+func TestDriftCargo(t *testing.T) {
+	manifest := `[package]
+name = "app"
+version = "0.1.0"
+
+[dependencies]
+serde = "1.0.150"
+rand = "0.8"
+log = "0.4"
+`
+	lockfile := `version = 3
+
+[[package]]
+name = "app"
+version = "0.1.0"
+dependencies = [
+ "serde",
+ "rand",
+ "anyhow",
+]
+
+[[package]]
+name = "anyhow"
+version = "1.0.75"
+source = "registry+https://github.com/rust-lang/crates.io-index"
+
+[[package]]
+name = "rand"
+version = "0.8.5"
+source = "registry+https://github.com/rust-lang/crates.io-index"
+
+[[package]]
+name = "serde"
+version = "1.0.140"
+source = "registry+https://github.com/rust-lang/crates.io-index"
+`
+	checkDrift(t, "Cargo.toml", manifest, "Cargo.lock", lockfile, []string{
+		"not-declared anyhow  1.0.75",
+		"not-locked log 0.4 ",
+		"unsatisfied serde 1.0.150 1.0.140",
+	})
+}
+
+func TestDriftComposer(t *testing.T) {
+	manifest := `{
+  "require": {
+    "php": ">=8.1",
+    "symfony/console": "^6.3",
+    "monolog/monolog": "^3.0",
+    "guzzlehttp/guzzle": "^7.0",
+    "symfony/yaml": "^6.0"
+  }
+}`
+	// Composer records tags as released, so versions may carry a "v"
+	lockfile := `{
+  "packages": [
+    {"name": "symfony/console", "version": "v6.4.1"},
+    {"name": "monolog/monolog", "version": "2.9.2"},
+    {"name": "symfony/yaml", "version": "v5.4.31"}
+  ],
+  "packages-dev": []
+}`
+	checkDrift(t, "composer.json", manifest, "composer.lock", lockfile, []string{
+		"not-locked guzzlehttp/guzzle ^7.0 ",
+		"unsatisfied monolog/monolog ^3.0 2.9.2",
+		"unsatisfied symfony/yaml ^6.0 v5.4.31",
+	})
+}
+
+func TestDriftPoetry(t *testing.T) {
+	manifest := `[tool.poetry]
+name = "app"
+version = "0.1.0"
+
+[tool.poetry.dependencies]
+python = "^3.11"
+requests = "^2.31"
+Flask = "^3.0"
+click = "^8.0"
+`
+	lockfile := `[[package]]
+name = "requests"
+version = "2.28.2"
+optional = false
+python-versions = ">=3.7"
+
+[[package]]
+name = "flask"
+version = "3.0.0"
+optional = false
+python-versions = ">=3.8"
+
+[metadata]
+lock-version = "2.0"
+python-versions = "^3.11"
+content-hash = "abc"
+`
+	checkDrift(t, "pyproject.toml", manifest, "poetry.lock", lockfile, []string{
+		"not-locked click ^8.0 ",
+		"unsatisfied requests ^2.31 2.28.2",
+	})
+}
+
+func TestDriftUv(t *testing.T) {
+	manifest := `[project]
+name = "app"
+version = "0.1.0"
+dependencies = [
+    "httpx>=0.27",
+    "rich>=13",
+    "shared-lib",
+]
+
+[tool.uv.sources]
+shared-lib = { path = "../shared-lib", editable = true }
+`
+	lockfile := `version = 1
+requires-python = ">=3.12"
+
+[[package]]
+name = "app"
+version = "0.1.0"
+source = { editable = "." }
+dependencies = [
+    { name = "httpx" },
+    { name = "shared-lib" },
+    { name = "pydantic" },
+]
+
+[[package]]
+name = "httpx"
+version = "0.26.0"
+source = { registry = "https://pypi.org/simple" }
+
+[[package]]
+name = "pydantic"
+version = "2.5.0"
+source = { registry = "https://pypi.org/simple" }
+
+[[package]]
+name = "shared-lib"
+version = "0.3.0"
+source = { editable = "../shared-lib" }
+`
+	checkDrift(t, "pyproject.toml", manifest, "uv.lock", lockfile, []string{
+		"not-declared pydantic  2.5.0",
+		"not-locked rich >=13 ",
+		"unsatisfied httpx >=0.27 0.26.0",
+	})
+}
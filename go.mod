@@ -7,10 +7,8 @@ require (
 	github.com/bazelbuild/buildtools v0.0.0-20260622120422-77b9b380c0a4
 	github.com/git-pkgs/pom v0.1.5
 	github.com/git-pkgs/purl v0.1.12
+	github.com/git-pkgs/vers v0.2.5
 	gopkg.in/yaml.v3 v3.0.1
 )
 
-require (
-	github.com/git-pkgs/vers v0.2.5 // indirect
-	github.com/package-url/packageurl-go v0.1.6 // indirect
-)
+require github.com/package-url/packageurl-go v0.1.6 // indirect
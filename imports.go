@@ -43,6 +43,7 @@ import (
 	_ "github.com/git-pkgs/manifests/internal/pypi"
 	_ "github.com/git-pkgs/manifests/internal/rebar"
 	_ "github.com/git-pkgs/manifests/internal/rpm"
+	_ "github.com/git-pkgs/manifests/internal/sbom"
 	_ "github.com/git-pkgs/manifests/internal/swift"
 	_ "github.com/git-pkgs/manifests/internal/vcpkg"
 )
@@ -24,50 +24,70 @@ var (
 	apkDepRegex = regexp.MustCompile(`^([a-zA-Z0-9_][a-zA-Z0-9_+.-]*)(>=|<=|>|<|=)?(.*)$`)
 )
 
+// apkDependencyVars are the variables apkbuildParser reads
+// dependencies from, with their scopes.
+var apkDependencyVars = []struct {
+	name  string
+	scope core.Scope
+}{
+	{"depends", core.Runtime},
+	{"depends_dev", core.Development},
+	{"makedepends", core.Build},
+	{"checkdepends", core.Test},
+}
+
 func (p *apkbuildParser) Parse(filename string, content []byte) (*core.Result, error) {
-	vars := parseApkbuildVars(string(content))
+	vars, lines := parseApkbuildVars(string(content))
 
 	var deps []core.Dependency
-
-	// Runtime dependencies
-	for _, dep := range parseApkDeps(vars["depends"]) {
-		dep.Scope = core.Runtime
-		dep.Direct = true
-		deps = append(deps, dep)
+	var diags []core.Diagnostic
+
+	for _, v := range apkDependencyVars {
+		parsed, refs := parseApkDeps(vars[v.name])
+		for _, dep := range parsed {
+			dep.Scope = v.scope
+			dep.Direct = true
+			deps = append(deps, dep)
+		}
+		for _, ref := range refs {
+			diags = append(diags, apkReferenceDiagnostic(lines[v.name], v.name, ref))
+		}
 	}
 
-	// Development dependencies
-	for _, dep := range parseApkDeps(vars["depends_dev"]) {
-		dep.Scope = core.Development
-		dep.Direct = true
-		deps = append(deps, dep)
+	metadata := core.Metadata{
+		Description: vars["pkgdesc"],
+		Homepage:    vars["url"],
+		License:     vars["license"],
 	}
 
-	// Build dependencies
-	for _, dep := range parseApkDeps(vars["makedepends"]) {
-		dep.Scope = core.Build
-		dep.Direct = true
-		deps = append(deps, dep)
-	}
+	return &core.Result{Name: vars["pkgname"], Version: vars["pkgver"], Metadata: metadata, Dependencies: deps, Diagnostics: diags}, nil
+}
 
-	// Test/check dependencies
-	for _, dep := range parseApkDeps(vars["checkdepends"]) {
-		dep.Scope = core.Test
-		dep.Direct = true
-		deps = append(deps, dep)
+// apkReferenceDiagnostic explains a $variable in a dependency list. A
+// reference to another dependency variable, as in
+// makedepends="$depends_dev ...", only loses the scope, since those
+// packages are reported under the other variable.
+func apkReferenceDiagnostic(line int, variable, ref string) core.Diagnostic {
+	name := strings.Trim(ref, "${}")
+	for _, v := range apkDependencyVars {
+		if v.name == name {
+			return core.Info(line, core.CodeUnresolvedVariable, "%s includes %s, whose packages are reported under %s only", variable, ref, name)
+		}
 	}
-
-	return &core.Result{Name: vars["pkgname"], Version: vars["pkgver"], Dependencies: deps}, nil
+	return core.Warning(line, core.CodeUnresolvedVariable, "%s includes %s, which is not expanded", variable, ref)
 }
 
-func parseApkbuildVars(content string) map[string]string {
+// parseApkbuildVars returns the variables assigned at the top level of
+// an APKBUILD, and the 1-based line each assignment starts on.
+func parseApkbuildVars(content string) (map[string]string, map[string]int) {
 	vars := make(map[string]string)
+	starts := make(map[string]int)
 	lines := strings.Split(content, "\n")
 
 	var currentVar string
 	var currentValue strings.Builder
 
-	for _, line := range lines {
+	for i, line := range lines {
 		// Skip comments
 		if strings.HasPrefix(strings.TrimSpace(line), "#") {
 			continue
@@ -92,6 +112,7 @@ func parseApkbuildVars(content string) map[string]string {
 		// Check for single-line variable
 		if match := apkVarRegex.FindStringSubmatch(line); match != nil {
 			vars[match[1]] = match[2]
+			starts[match[1]] = i + 1
 			continue
 		}
 
@@ -99,27 +120,31 @@ func parseApkbuildVars(content string) map[string]string {
 		if match := apkVarStartRegex.FindStringSubmatch(line); match != nil {
 			currentVar = match[1]
 			currentValue.WriteString(match[2])
+			starts[match[1]] = i + 1
 			continue
 		}
 
 		// Check for unquoted scalar (pkgname=foo, pkgver=1.0)
 		if match := apkScalarRegex.FindStringSubmatch(line); match != nil {
 			vars[match[1]] = match[2]
+			starts[match[1]] = i + 1
 		}
 	}
 
-	return vars
+	return vars, starts
 }
 
-func parseApkDeps(depStr string) []core.Dependency {
+// parseApkDeps splits a dependency list into packages and the $variable
+// references it contains, which are not expanded.
+func parseApkDeps(depStr string) ([]core.Dependency, []string) {
 	var deps []core.Dependency
+	var refs []string
 
-	// Expand $depends_dev and similar references (simplified - just skip them)
 	fields := strings.Fields(depStr)
 
 	for _, field := range fields {
-		// Skip variable references
 		if strings.HasPrefix(field, "$") {
+			refs = append(refs, field)
 			continue
 		}
 
@@ -137,5 +162,5 @@ func parseApkDeps(depStr string) []core.Dependency {
 		}
 	}
 
-	return deps
+	return deps, refs
 }
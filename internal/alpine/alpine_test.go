@@ -2,6 +2,7 @@ package alpine
 
 import (
 	"os"
+	"strings"
 	"testing"
 
 	"github.com/git-pkgs/manifests/internal/core"
@@ -120,3 +121,26 @@ func TestAPKBUILDWithVersions(t *testing.T) {
 		}
 	}
 }
+
+func TestAPKBUILDDiagnostics(t *testing.T) {
+	content := []byte(`pkgname=foo
+depends="musl $_py_deps"
+makedepends="$depends_dev cmake"
+`)
+	res, err := (&apkbuildParser{}).Parse("APKBUILD", content)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if len(res.Dependencies) != 2 {
+		t.Fatalf("expected 2 dependencies, got %+v", res.Dependencies)
+	}
+	if len(res.Diagnostics) != 2 {
+		t.Fatalf("expected 2 diagnostics, got %+v", res.Diagnostics)
+	}
+	if d := res.Diagnostics[0]; d.Severity != core.SeverityWarning || d.Line != 2 || !strings.Contains(d.Message, "$_py_deps") {
+		t.Errorf("unexpected diagnostic %+v", d)
+	}
+	if d := res.Diagnostics[1]; d.Severity != core.SeverityInfo || d.Line != 3 || d.Code != core.CodeUnresolvedVariable {
+		t.Errorf("unexpected diagnostic %+v", d)
+	}
+}
@@ -18,6 +18,10 @@ var (
 	pkgbuildArrayRegex = regexp.MustCompile(`^(\w+)=\(([^)]*)\)`)
 	// Matches scalar assignments: pkgname=foo or pkgver=1.0
 	pkgbuildScalarRegex = regexp.MustCompile(`^(\w+)=([^\s('"]+)`)
+	// Matches quoted scalar assignments: pkgdesc="GNU Hello"
+	pkgbuildQuotedRegex = regexp.MustCompile(`^(\w+)=(?:"([^"]*)"|'([^']*)')\s*$`)
+	// Matches one element of an array: 'GPL3' or "MIT"
+	pkgbuildElementRegex = regexp.MustCompile(`"([^"]*)"|'([^']*)'`)
 	// Matches package with optional version constraint: pkg>=1.0 or pkg
 	pkgbuildDepRegex = regexp.MustCompile(`^([a-zA-Z0-9_][a-zA-Z0-9_+@.-]*)(>=|<=|>|<|=)?([^'"]*)$`)
 )
@@ -55,7 +59,18 @@ func (p *pkgbuildParser) Parse(filename string, content []byte) (*core.Result, e
 		deps = append(deps, dep)
 	}
 
-	return &core.Result{Name: vars["pkgname"], Version: vars["pkgver"], Dependencies: deps}, nil
+	var licenses []string
+	for _, m := range pkgbuildElementRegex.FindAllStringSubmatch(vars["license"], -1) {
+		licenses = append(licenses, m[1]+m[2])
+	}
+	metadata := core.Metadata{
+		Description: vars["pkgdesc"],
+		Homepage:    vars["url"],
+		// Every license listed applies
+		License: core.JoinLicenses("AND", licenses...),
+	}
+
+	return &core.Result{Name: vars["pkgname"], Version: vars["pkgver"], Metadata: metadata, Dependencies: deps}, nil
 }
 
 func parsePkgbuildVars(content string) map[string]string {
@@ -75,6 +90,11 @@ func parsePkgbuildVars(content string) map[string]string {
 			continue
 		}
 
+		if match := pkgbuildQuotedRegex.FindStringSubmatch(line); match != nil {
+			vars[match[1]] = match[2] + match[3]
+			continue
+		}
+
 		if match := pkgbuildScalarRegex.FindStringSubmatch(line); match != nil {
 			vars[match[1]] = match[2]
 		}
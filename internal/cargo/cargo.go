@@ -1,6 +1,7 @@
 package cargo
 
 import (
+	"context"
 	"strings"
 
 	"github.com/BurntSushi/toml"
@@ -13,6 +14,8 @@ func init() {
 
 	// Cargo.lock - lockfile
 	core.Register("cargo", core.Lockfile, &cargoLockParser{}, core.ExactMatch("Cargo.lock"))
+
+	core.RegisterWorkspace("cargo", &cargoTomlParser{}, core.ExactMatch("Cargo.toml"))
 }
 
 // cargoTomlParser parses Cargo.toml files.
@@ -23,7 +26,20 @@ func (p *cargoTomlParser) Parse(filename string, content []byte) (*core.Result,
 		Package struct {
 			Name    string `toml:"name"`
 			Version string `toml:"version"`
+			// These can be inherited with { workspace = true }
+			Description any `toml:"description"`
+			License     any `toml:"license"`
+			Homepage    any `toml:"homepage"`
+			Repository  any `toml:"repository"`
+			Authors     any `toml:"authors"`
+			Keywords    any `toml:"keywords"`
+			RustVersion any `toml:"rust-version"`
 		} `toml:"package"`
+		Workspace struct {
+			Package struct {
+				RustVersion string `toml:"rust-version"`
+			} `toml:"package"`
+		} `toml:"workspace"`
 		Dependencies      map[string]any `toml:"dependencies"`
 		DevDependencies   map[string]any `toml:"dev-dependencies"`
 		BuildDependencies map[string]any `toml:"build-dependencies"`
@@ -34,45 +50,64 @@ func (p *cargoTomlParser) Parse(filename string, content []byte) (*core.Result,
 	}
 
 	var deps []core.Dependency
+	var diags []core.Diagnostic
 	pkgName := cargo.Package.Name
+	locator := core.NewLocator(content, locateCargoDependencies(content))
+	skipLocal := func(table, name string, value any) bool {
+		if !isLocalCargoDep(value) {
+			return false
+		}
+		pos, _ := locator.Locate(table, name)
+		diags = append(diags, core.Info(pos.StartLine, core.CodeSkippedDependency, "path dependency %s is not reported", name))
+		return true
+	}
 
 	for name, value := range cargo.Dependencies {
 		version := extractCargoVersion(value)
 		// Skip local path dependencies
-		if isLocalCargoDep(value) {
+		if skipLocal("dependencies", name, value) {
 			continue
 		}
+		pos, raw := locator.Locate("dependencies", name)
 		deps = append(deps, core.Dependency{
-			Name:    name,
-			Version: version,
-			Scope:   core.Runtime,
-			Direct:  true,
+			Name:     name,
+			Version:  version,
+			Scope:    core.Runtime,
+			Direct:   true,
+			Position: pos,
+			Raw:      raw,
 		})
 	}
 
 	for name, value := range cargo.DevDependencies {
 		version := extractCargoVersion(value)
-		if isLocalCargoDep(value) {
+		if skipLocal("dev-dependencies", name, value) {
 			continue
 		}
+		pos, raw := locator.Locate("dev-dependencies", name)
 		deps = append(deps, core.Dependency{
-			Name:    name,
-			Version: version,
-			Scope:   core.Development,
-			Direct:  true,
+			Name:     name,
+			Version:  version,
+			Scope:    core.Development,
+			Direct:   true,
+			Position: pos,
+			Raw:      raw,
 		})
 	}
 
 	for name, value := range cargo.BuildDependencies {
 		version := extractCargoVersion(value)
-		if isLocalCargoDep(value) {
+		if skipLocal("build-dependencies", name, value) {
 			continue
 		}
+		pos, raw := locator.Locate("build-dependencies", name)
 		deps = append(deps, core.Dependency{
-			Name:    name,
-			Version: version,
-			Scope:   core.Build,
-			Direct:  true,
+			Name:     name,
+			Version:  version,
+			Scope:    core.Build,
+			Direct:   true,
+			Position: pos,
+			Raw:      raw,
 		})
 	}
 
@@ -84,7 +119,226 @@ func (p *cargoTomlParser) Parse(filename string, content []byte) (*core.Result,
 		}
 	}
 
-	return &core.Result{Name: pkgName, Version: cargo.Package.Version, Dependencies: filtered}, nil
+	pkg := cargo.Package
+	metadata := core.Metadata{
+		Description: cargoString(pkg.Description),
+		License:     cargoString(pkg.License),
+		Homepage:    cargoString(pkg.Homepage),
+		Repository:  cargoString(pkg.Repository),
+		Authors:     cargoStrings(pkg.Authors),
+		Keywords:    cargoStrings(pkg.Keywords),
+	}
+
+	// A workspace root can set rust-version for its members to inherit
+	var runtimes []core.Requirement
+	rustVersion := cargoString(pkg.RustVersion)
+	if rustVersion == "" {
+		rustVersion = cargo.Workspace.Package.RustVersion
+	}
+	if rustVersion != "" {
+		runtimes = append(runtimes, core.Requirement{Name: "rust", Version: rustVersion, Source: "rust-version"})
+	}
+
+	return &core.Result{
+		Name:         pkgName,
+		Version:      pkg.Version,
+		Metadata:     metadata,
+		Dependencies: filtered,
+		Diagnostics:  diags,
+		Runtimes:     runtimes,
+	}, nil
+}
+
+// ReadWorkspace reads the [workspace] table's members and exclude
+// globs. A root Cargo.toml that also has a [package] is a member of its
+// own workspace.
+func (p *cargoTomlParser) ReadWorkspace(filename string, content []byte) (*core.WorkspaceDefinition, error) {
+	var cargo struct {
+		Package   *struct{} `toml:"package"`
+		Workspace *struct {
+			Members []string `toml:"members"`
+			Exclude []string `toml:"exclude"`
+		} `toml:"workspace"`
+	}
+	if _, err := toml.Decode(string(content), &cargo); err != nil {
+		return nil, &core.ParseError{Filename: filename, Err: err}
+	}
+	if cargo.Workspace == nil {
+		return nil, nil
+	}
+	def := &core.WorkspaceDefinition{
+		Members:   cargo.Workspace.Members,
+		Exclude:   cargo.Workspace.Exclude,
+		Manifests: []string{"Cargo.toml"},
+	}
+	if cargo.Package != nil {
+		def.Members = append([]string{"."}, def.Members...)
+	}
+	return def, nil
+}
+
+// cargoString returns a [package] field's value, or "" when it's
+// inherited from the workspace.
+func cargoString(v any) string {
+	s, _ := v.(string)
+	return s
+}
+
+// cargoStrings returns a [package] array's values, or nil when it's
+// inherited from the workspace.
+func cargoStrings(v any) []string {
+	list, _ := v.([]any)
+	var values []string
+	for _, item := range list {
+		if s, ok := item.(string); ok {
+			values = append(values, s)
+		}
+	}
+	return values
+}
+
+// EditVersion rewrites a dependency's version requirement, whether
+// given as the whole value or as the version key of an inline table or
+// [dependencies.name] table. Git and workspace dependencies without a
+// version key have none to change.
+func (p *cargoTomlParser) EditVersion(content []byte, decl core.Span, dep *core.Dependency, version string) (core.Edit, bool, error) {
+	span, quote, ok := core.TOMLVersionSpan(content, decl)
+	if !ok {
+		return core.Edit{}, false, nil
+	}
+	encoded, err := core.QuotedVersion(version, quote)
+	if err != nil {
+		return core.Edit{}, false, err
+	}
+	return core.Edit{Span: span, Text: encoded}, true, nil
+}
+
+// isCargoDependencyTable reports whether a top-level table name holds
+// dependencies that cargoTomlParser reads.
+func isCargoDependencyTable(name string) bool {
+	return name == "dependencies" || name == "dev-dependencies" || name == "build-dependencies"
+}
+
+// locateCargoDependencies finds where each dependency is declared, keyed
+// by table and then crate name. A declaration is either a key inside
+// [dependencies] ("serde = ..." or "serde.workspace = true"), running on
+// to the line that closes any inline table or array its value opens, or
+// a whole [dependencies.serde] table up to its last non-blank line.
+func locateCargoDependencies(content []byte) map[string]map[string]core.Span {
+	spans := make(map[string]map[string]core.Span)
+	record := func(table, name string, span core.Span) {
+		if spans[table] == nil {
+			spans[table] = make(map[string]core.Span)
+		}
+		if _, ok := spans[table][name]; !ok {
+			spans[table][name] = span
+		}
+	}
+
+	table := ""       // current [table] when it lists dependencies
+	subTable := ""    // current [table.name] when it declares one dependency
+	var sub core.Span // extent of subTable so far
+	flushSub := func() {
+		if subTable != "" {
+			record(table, subTable, sub)
+		}
+		subTable = ""
+	}
+
+	keyTable, keyName := "", "" // key whose value is still open
+	var key core.Span           // extent of that key so far
+	depth := 0                  // brackets its value leaves open
+
+	offset := 0
+	core.ForEachLine(string(content), func(line string) bool {
+		lineStart := offset
+		offset += len(line) + 1
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			return true
+		}
+		start := lineStart + strings.Index(line, trimmed)
+		end := start + len(trimmed)
+
+		if depth > 0 {
+			key.End = end
+			if depth += tomlBracketDepth(trimmed); depth <= 0 {
+				record(keyTable, keyName, key)
+			}
+			return true
+		}
+
+		if strings.HasPrefix(trimmed, "[") {
+			flushSub()
+			header := strings.TrimSpace(strings.Trim(trimmed, "[]"))
+			name, dep, _ := strings.Cut(header, ".")
+			table = ""
+			if isCargoDependencyTable(name) {
+				table = name
+				if dep != "" {
+					subTable = strings.Trim(dep, `"'`)
+					sub = core.Span{Start: start, End: end}
+				}
+			}
+			return true
+		}
+
+		if table == "" {
+			return true
+		}
+		if subTable != "" {
+			sub.End = end
+			return true
+		}
+
+		name, value, ok := strings.Cut(trimmed, "=")
+		if !ok {
+			return true
+		}
+		name = strings.TrimSpace(name)
+		if crate, _, dotted := strings.Cut(name, "."); dotted && !strings.HasPrefix(name, `"`) {
+			name = crate
+		}
+		keyTable, keyName = table, strings.Trim(name, `"'`)
+		key = core.Span{Start: start, End: end}
+		if depth = tomlBracketDepth(value); depth <= 0 {
+			record(keyTable, keyName, key)
+		}
+		return true
+	})
+	flushSub()
+	if depth > 0 {
+		record(keyTable, keyName, key)
+	}
+
+	return spans
+}
+
+// tomlBracketDepth returns how many more inline tables and arrays s
+// opens than it closes, ignoring brackets in strings and comments.
+func tomlBracketDepth(s string) int {
+	depth := 0
+	var quote byte
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote != 0:
+			if c == '\\' && quote == '"' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '#':
+			return depth
+		case c == '{' || c == '[':
+			depth++
+		case c == '}' || c == ']':
+			depth--
+		}
+	}
+	return depth
 }
 
 func extractCargoVersion(value any) string {
@@ -110,72 +364,179 @@ func isLocalCargoDep(value any) bool {
 // cargoLockParser parses Cargo.lock files using string ops for speed.
 type cargoLockParser struct{}
 
+// cargoLockPackage holds one [[package]] block from Cargo.lock.
+type cargoLockPackage struct {
+	name         string
+	version      string
+	source       string
+	checksum     string
+	dependencies []string
+}
+
+// latestCargoLockVersion is the newest Cargo.lock format version. Files
+// from before version 3 don't record one.
+const latestCargoLockVersion = 4
+
 func (p *cargoLockParser) Parse(filename string, content []byte) (*core.Result, error) {
+	return p.ParseLimited(context.Background(), filename, content, core.Limits{})
+}
+
+func (p *cargoLockParser) ParseLimited(ctx context.Context, filename string, content []byte, limits core.Limits) (*core.Result, error) {
 	text := string(content)
 	deps := make([]core.Dependency, 0, core.EstimateDeps(len(content)))
+	var packages []cargoLockPackage
+	var formatVersion string
+	var err error
 
-	var currentName, currentVersion, currentSource, currentChecksum string
+	var current cargoLockPackage
 	inPackage := false
+	inDependencies := false
+	registryPackages := 0 // packages that will be reported as dependencies
+
+	flush := func() {
+		if inPackage && current.name != "" {
+			packages = append(packages, current)
+			if current.source != "" {
+				registryPackages++
+			}
+		}
+	}
 
 	core.ForEachLine(text, func(line string) bool {
 		// Start of a package block
 		if line == "[[package]]" {
-			// Save previous package if it had a source (not local)
-			if inPackage && currentName != "" && currentSource != "" {
-				integrity := ""
-				if currentChecksum != "" {
-					integrity = "sha256-" + currentChecksum
-				}
-				deps = append(deps, core.Dependency{
-					Name:        currentName,
-					Version:     currentVersion,
-					Scope:       core.Runtime,
-					Integrity:   integrity,
-					Direct:      false,
-					RegistryURL: extractCargoRegistryURL(currentSource),
-				})
-			}
-			currentName = ""
-			currentVersion = ""
-			currentSource = ""
-			currentChecksum = ""
+			flush()
+			current = cargoLockPackage{}
 			inPackage = true
-			return true
+			inDependencies = false
+			err = limits.Check(ctx, filename, registryPackages)
+			return err == nil
 		}
 
 		if !inPackage {
+			if v, ok := strings.CutPrefix(line, "version = "); ok {
+				formatVersion = strings.TrimSpace(v)
+			}
+			return true
+		}
+
+		if inDependencies {
+			if strings.HasPrefix(line, "]") {
+				inDependencies = false
+				return true
+			}
+			if v := strings.Trim(strings.TrimSpace(line), `",`); v != "" {
+				current.dependencies = append(current.dependencies, v)
+			}
 			return true
 		}
 
 		if v, ok := core.ExtractQuotedValue(line, "name = "); ok {
-			currentName = v
+			current.name = v
 		} else if v, ok := core.ExtractQuotedValue(line, "version = "); ok {
-			currentVersion = v
+			current.version = v
 		} else if v, ok := core.ExtractQuotedValue(line, "source = "); ok {
-			currentSource = v
+			current.source = v
 		} else if v, ok := core.ExtractQuotedValue(line, "checksum = "); ok {
-			currentChecksum = v
+			current.checksum = v
+		} else if strings.HasPrefix(line, "dependencies = [") {
+			rest := strings.TrimPrefix(line, "dependencies = [")
+			if idx := strings.IndexByte(rest, ']'); idx >= 0 {
+				// Inline form: dependencies = ["a", "b 1.0"]
+				for _, v := range strings.Split(rest[:idx], ",") {
+					if v = strings.Trim(strings.TrimSpace(v), `"`); v != "" {
+						current.dependencies = append(current.dependencies, v)
+					}
+				}
+			} else {
+				inDependencies = true
+			}
 		}
 		return true
 	})
 
+	if err != nil {
+		return nil, err
+	}
 	// Don't forget the last package
-	if inPackage && currentName != "" && currentSource != "" {
+	flush()
+	if err := limits.Check(ctx, filename, registryPackages); err != nil {
+		return nil, err
+	}
+
+	if err := core.CheckFormatVersion(filename, formatVersion, latestCargoLockVersion); err != nil {
+		return nil, err
+	}
+
+	for _, pkg := range packages {
+		// Packages without a source are local workspace crates
+		if pkg.source == "" {
+			continue
+		}
 		integrity := ""
-		if currentChecksum != "" {
-			integrity = "sha256-" + currentChecksum
+		if pkg.checksum != "" {
+			integrity = "sha256-" + pkg.checksum
 		}
 		deps = append(deps, core.Dependency{
-			Name:        currentName,
-			Version:     currentVersion,
+			Name:        pkg.name,
+			Version:     pkg.version,
 			Scope:       core.Runtime,
 			Integrity:   integrity,
 			Direct:      false,
-			RegistryURL: extractCargoRegistryURL(currentSource),
+			RegistryURL: extractCargoRegistryURL(pkg.source),
 		})
 	}
 
-	return &core.Result{Dependencies: deps}, nil
+	return &core.Result{Dependencies: deps, Graph: buildCargoGraph(packages), FormatVersion: formatVersion}, nil
+}
+
+// buildCargoGraph links packages through their dependencies lists. Entries
+// are "name", "name version" or "name version (source)"; the version is
+// only present when several versions of a crate are locked. Dependencies
+// of local crates (no source) become the graph roots; local crates
+// themselves are left out, matching the dependency list.
+func buildCargoGraph(packages []cargoLockPackage) *core.Graph {
+	versions := make(map[string][]string, len(packages))
+	locals := make(map[core.Node]bool)
+	for _, pkg := range packages {
+		versions[pkg.name] = append(versions[pkg.name], pkg.version)
+		if pkg.source == "" {
+			locals[core.Node{Name: pkg.name, Version: pkg.version}] = true
+		}
+	}
+
+	graph := core.NewGraphBuilder()
+	for _, pkg := range packages {
+		from := core.Node{Name: pkg.name, Version: pkg.version}
+		local := pkg.source == ""
+		for _, entry := range pkg.dependencies {
+			to, ok := resolveCargoDependency(entry, versions)
+			if !ok || locals[to] {
+				continue
+			}
+			if local {
+				graph.AddRoot(to)
+			} else {
+				graph.AddEdge(from, to)
+			}
+		}
+	}
+	return graph.Graph()
+}
+
+func resolveCargoDependency(entry string, versions map[string][]string) (core.Node, bool) {
+	fields := strings.Fields(entry)
+	if len(fields) == 0 {
+		return core.Node{}, false
+	}
+	name := fields[0]
+	if len(fields) > 1 {
+		return core.Node{Name: name, Version: fields[1]}, true
+	}
+	if vs := versions[name]; len(vs) > 0 {
+		return core.Node{Name: name, Version: vs[0]}, true
+	}
+	return core.Node{}, false
 }
 
 // extractCargoRegistryURL extracts the registry URL from Cargo's source field.
@@ -1,7 +1,11 @@
 package cargo
 
 import (
+	"context"
+	"errors"
 	"os"
+	"slices"
+	"strings"
 	"testing"
 
 	"github.com/git-pkgs/manifests/internal/core"
@@ -56,6 +60,12 @@ func TestCargoToml(t *testing.T) {
 	if _, ok := depMap["local_crate"]; ok {
 		t.Error("local_crate path dependency should be filtered out")
 	}
+	if len(res.Diagnostics) != 1 {
+		t.Fatalf("expected 1 diagnostic, got %+v", res.Diagnostics)
+	}
+	if d := res.Diagnostics[0]; d.Severity != core.SeverityInfo || d.Code != core.CodeSkippedDependency || d.Line != 9 {
+		t.Errorf("unexpected diagnostic %+v", d)
+	}
 }
 
 func TestCargoLock(t *testing.T) {
@@ -150,3 +160,207 @@ func TestCargoLock(t *testing.T) {
 		t.Error("update root package should be filtered out")
 	}
 }
+
+func TestCargoLockGraph(t *testing.T) {
+	content, err := os.ReadFile("../../testdata/cargo/Cargo.lock")
+	if err != nil {
+		t.Fatalf("failed to read fixture: %v", err)
+	}
+
+	parser := &cargoLockParser{}
+	res, err := parser.Parse("Cargo.lock", content)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	if res.Graph == nil {
+		t.Fatal("expected a graph")
+	}
+
+	roots := make(map[string]bool)
+	for _, r := range res.Graph.Roots {
+		roots[r.Name] = true
+	}
+	for _, name := range []string{"regex", "rustc-serialize", "tempdir"} {
+		if !roots[name] {
+			t.Errorf("expected %s to be a root", name)
+		}
+	}
+	if roots["update"] || roots["local_crate"] {
+		t.Error("local crates should not be roots")
+	}
+
+	// "rand_core 0.3.1" entries name the version explicitly
+	children := res.Graph.Children(core.Node{Name: "rand_core", Version: "0.3.1"})
+	if len(children) != 1 || children[0] != (core.Node{Name: "rand_core", Version: "0.4.2"}) {
+		t.Errorf("rand_core 0.3.1 children = %v", children)
+	}
+
+	path := res.Graph.Path("rand_core", "0.4.2")
+	var names []string
+	for _, n := range path {
+		names = append(names, n.Name+"@"+n.Version)
+	}
+	want := "tempdir@0.3.7 rand@0.4.6 rand_core@0.3.1 rand_core@0.4.2"
+	if got := strings.Join(names, " "); got != want {
+		t.Errorf("Path = %q, want %q", got, want)
+	}
+}
+
+func TestLocateCargoDependencies(t *testing.T) {
+	content := `[package]
+name = "demo"
+
+[dependencies]
+serde = { version = "1", features = ["derive"] }
+tokio.workspace = true
+"quoted-name" = "0.1"
+clap = { version = "4",
+  features = ["derive"] }
+reqwest = { version = "0.12", features = [
+  # "}" in a comment or string doesn't close it
+  "json",
+] }
+regex = "1"
+
+[dependencies.rand]
+version = "0.8"
+default-features = false
+
+# trailing comment
+[dev-dependencies]
+tempdir = "0.3"
+
+[target.'cfg(unix)'.dependencies]
+nix = "0.26"
+`
+	spans := locateCargoDependencies([]byte(content))
+
+	tests := []struct {
+		table string
+		name  string
+		text  string
+	}{
+		{"dependencies", "serde", `serde = { version = "1", features = ["derive"] }`},
+		{"dependencies", "tokio", "tokio.workspace = true"},
+		{"dependencies", "quoted-name", `"quoted-name" = "0.1"`},
+		{"dependencies", "clap", "clap = { version = \"4\",\n  features = [\"derive\"] }"},
+		{"dependencies", "reqwest", "reqwest = { version = \"0.12\", features = [\n  # \"}\" in a comment or string doesn't close it\n  \"json\",\n] }"},
+		{"dependencies", "regex", `regex = "1"`},
+		{"dependencies", "rand", "[dependencies.rand]\nversion = \"0.8\"\ndefault-features = false"},
+		{"dev-dependencies", "tempdir", `tempdir = "0.3"`},
+	}
+	for _, tt := range tests {
+		span, ok := spans[tt.table][tt.name]
+		if !ok {
+			t.Errorf("%s.%s not located", tt.table, tt.name)
+			continue
+		}
+		if got := content[span.Start:span.End]; got != tt.text {
+			t.Errorf("%s.%s = %q, want %q", tt.table, tt.name, got, tt.text)
+		}
+	}
+
+	if _, ok := spans["dependencies"]["nix"]; ok {
+		t.Error("target-specific dependencies should not be located")
+	}
+}
+
+func TestCargoTomlMultiLinePosition(t *testing.T) {
+	content := "[dependencies]\nclap = { version = \"4\",\n  features = [\"derive\"] }\nregex = \"1\"\n"
+	res, err := (&cargoTomlParser{}).Parse("Cargo.toml", []byte(content))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	for _, dep := range res.Dependencies {
+		if dep.Name != "clap" {
+			continue
+		}
+		if dep.Position.StartLine != 2 || dep.Position.EndLine != 3 || dep.Position.EndColumn != 26 {
+			t.Errorf("unexpected position %+v", dep.Position)
+		}
+		if want := "clap = { version = \"4\",\n  features = [\"derive\"] }"; dep.Raw != want {
+			t.Errorf("Raw = %q, want %q", dep.Raw, want)
+		}
+		return
+	}
+	t.Fatal("clap not found")
+}
+
+func TestCargoTomlMetadata(t *testing.T) {
+	content := []byte(`[package]
+name = "app"
+version = "0.1.0"
+description = "An app"
+license = "MIT OR Apache-2.0"
+repository = "https://github.com/acme/app"
+authors = ["Jane Doe <jane@example.com>"]
+keywords = ["cli"]
+homepage.workspace = true
+`)
+	res, err := (&cargoTomlParser{}).Parse("Cargo.toml", content)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	m := res.Metadata
+	if m.Description != "An app" || m.License != "MIT OR Apache-2.0" || m.Repository != "https://github.com/acme/app" {
+		t.Errorf("unexpected metadata %+v", m)
+	}
+	// Fields inherited from the workspace aren't known here
+	if m.Homepage != "" {
+		t.Errorf("Homepage = %q, want empty", m.Homepage)
+	}
+	if len(m.Authors) != 1 || len(m.Keywords) != 1 {
+		t.Errorf("unexpected authors or keywords %+v", m)
+	}
+}
+
+func TestCargoTomlRustVersion(t *testing.T) {
+	tests := []struct {
+		name, content, want string
+	}{
+		{"package", "[package]\nname = \"a\"\nrust-version = \"1.74\"\n", "1.74"},
+		{"inherited", "[package]\nname = \"a\"\nrust-version.workspace = true\n\n[workspace.package]\nrust-version = \"1.70\"\n", "1.70"},
+		{"inherited elsewhere", "[package]\nname = \"a\"\nrust-version.workspace = true\n", ""},
+	}
+	for _, tt := range tests {
+		res, err := (&cargoTomlParser{}).Parse("Cargo.toml", []byte(tt.content))
+		if err != nil {
+			t.Fatalf("%s: Parse failed: %v", tt.name, err)
+		}
+		var want []core.Requirement
+		if tt.want != "" {
+			want = []core.Requirement{{Name: "rust", Version: tt.want, Source: "rust-version"}}
+		}
+		if !slices.Equal(res.Runtimes, want) {
+			t.Errorf("%s: Runtimes = %+v, want %+v", tt.name, res.Runtimes, want)
+		}
+	}
+}
+
+func TestCargoLockLimits(t *testing.T) {
+	content, err := os.ReadFile("../../testdata/cargo/Cargo.lock")
+	if err != nil {
+		t.Fatalf("failed to read fixture: %v", err)
+	}
+	parser := &cargoLockParser{}
+	full, err := parser.Parse("Cargo.lock", content)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	n := len(full.Dependencies)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := parser.ParseLimited(ctx, "Cargo.lock", content, core.Limits{}); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+	if _, err := parser.ParseLimited(context.Background(), "Cargo.lock", content, core.Limits{MaxDependencies: n - 1}); !errors.Is(err, core.ErrTooManyDependencies) {
+		t.Errorf("expected ErrTooManyDependencies, got %v", err)
+	}
+	// Local crates aren't reported, so they don't count towards the limit
+	res, err := parser.ParseLimited(context.Background(), "Cargo.lock", content, core.Limits{MaxDependencies: n})
+	if err != nil || len(res.Dependencies) != n {
+		t.Errorf("expected all %d dependencies within the limit, got %v", n, err)
+	}
+}
@@ -14,8 +14,20 @@ func init() {
 type composerJSONParser struct{}
 
 type composerJSON struct {
-	Name       string            `json:"name"`
-	Version    string            `json:"version"`
+	Name        string `json:"name"`
+	Version     string `json:"version"`
+	Description string `json:"description"`
+	// A string, or an array of alternatives
+	License  any      `json:"license"`
+	Homepage string   `json:"homepage"`
+	Keywords []string `json:"keywords"`
+	Authors  []struct {
+		Name  string `json:"name"`
+		Email string `json:"email"`
+	} `json:"authors"`
+	Support struct {
+		Source string `json:"source"`
+	} `json:"support"`
 	Require    map[string]string `json:"require"`
 	RequireDev map[string]string `json:"require-dev"`
 }
@@ -27,10 +39,16 @@ func (p *composerJSONParser) Parse(filename string, content []byte) (*core.Resul
 	}
 
 	var deps []core.Dependency
+	var runtimes []core.Requirement
+	locator := core.NewJSONLocator(content)
 
 	for name, version := range composer.Require {
-		// Skip PHP version requirement
-		if name == "php" || name == "php-64bit" {
+		// The PHP version is a runtime requirement, not a package
+		if name == "php" {
+			runtimes = append(runtimes, core.Requirement{Name: "php", Version: version, Source: "require"})
+			continue
+		}
+		if name == "php-64bit" {
 			continue
 		}
 		// Skip ext- requirements
@@ -42,24 +60,70 @@ func (p *composerJSONParser) Parse(filename string, content []byte) (*core.Resul
 			continue
 		}
 
+		pos, raw := locator.Locate("require", name)
 		deps = append(deps, core.Dependency{
-			Name:    name,
-			Version: version,
-			Scope:   core.Runtime,
-			Direct:  true,
+			Name:     name,
+			Version:  version,
+			Scope:    core.Runtime,
+			Direct:   true,
+			Position: pos,
+			Raw:      raw,
 		})
 	}
 
 	for name, version := range composer.RequireDev {
+		pos, raw := locator.Locate("require-dev", name)
 		deps = append(deps, core.Dependency{
-			Name:    name,
-			Version: version,
-			Scope:   core.Development,
-			Direct:  true,
+			Name:     name,
+			Version:  version,
+			Scope:    core.Development,
+			Direct:   true,
+			Position: pos,
+			Raw:      raw,
 		})
 	}
 
-	return &core.Result{Name: composer.Name, Version: composer.Version, Dependencies: deps}, nil
+	return &core.Result{
+		Name:         composer.Name,
+		Version:      composer.Version,
+		Metadata:     composer.metadata(),
+		Dependencies: deps,
+		Runtimes:     runtimes,
+	}, nil
+}
+
+func (c *composerJSON) metadata() core.Metadata {
+	m := core.Metadata{
+		Description: c.Description,
+		License:     composerLicense(c.License),
+		Homepage:    c.Homepage,
+		Repository:  c.Support.Source,
+		Keywords:    c.Keywords,
+	}
+	for _, a := range c.Authors {
+		if author := core.Author(a.Name, a.Email); author != "" {
+			m.Authors = append(m.Authors, author)
+		}
+	}
+	return m
+}
+
+// composerLicense reads a license given as a string or, in both
+// composer.json and composer.lock, as an array of alternatives.
+func composerLicense(license any) string {
+	switch license := license.(type) {
+	case string:
+		return license
+	case []any:
+		var licenses []string
+		for _, l := range license {
+			if s, ok := l.(string); ok {
+				licenses = append(licenses, s)
+			}
+		}
+		return core.JoinLicenses("OR", licenses...)
+	}
+	return ""
 }
 
 // composerLockParser parses composer.lock files.
@@ -73,6 +137,7 @@ type composerLock struct {
 type composerPackage struct {
 	Name    string `json:"name"`
 	Version string `json:"version"`
+	License any    `json:"license"`
 	Dist    struct {
 		URL    string `json:"url"`
 		SHA    string `json:"shasum"`
@@ -103,6 +168,7 @@ func (p *composerLockParser) Parse(filename string, content []byte) (*core.Resul
 			Integrity:   integrity,
 			Direct:      false, // composer.lock doesn't distinguish
 			RegistryURL: pkg.Dist.URL,
+			License:     composerLicense(pkg.License),
 		})
 	}
 
@@ -121,6 +187,7 @@ func (p *composerLockParser) Parse(filename string, content []byte) (*core.Resul
 			Integrity:   integrity,
 			Direct:      false,
 			RegistryURL: pkg.Dist.URL,
+			License:     composerLicense(pkg.License),
 		})
 	}
 
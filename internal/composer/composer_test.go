@@ -2,6 +2,7 @@ package composer
 
 import (
 	"os"
+	"slices"
 	"testing"
 
 	"github.com/git-pkgs/manifests/internal/core"
@@ -75,21 +76,22 @@ func TestComposerLock(t *testing.T) {
 		depMap[d.Name] = d
 	}
 
-	// All 10 packages with versions and scopes
+	// All 10 packages with versions, scopes and licenses
 	expected := map[string]struct {
 		version string
 		scope   core.Scope
+		license string
 	}{
-		"doctrine/annotations":       {"v1.2.1", core.Runtime},
-		"doctrine/cache":             {"v1.3.1", core.Runtime},
-		"doctrine/collections":       {"v1.2", core.Runtime},
-		"drupal/address":             {"1.9.0", core.Runtime},
-		"symfony/monolog-bundle":     {"v2.6.1", core.Runtime},
-		"symfony/swiftmailer-bundle": {"v2.3.8", core.Runtime},
-		"symfony/symfony":            {"v2.6.1", core.Runtime},
-		"twig/extensions":            {"v1.2.0", core.Runtime},
-		"twig/twig":                  {"v1.16.2", core.Runtime},
-		"sensio/generator-bundle":    {"v2.5.0", core.Development},
+		"doctrine/annotations":       {"v1.2.1", core.Runtime, "MIT"},
+		"doctrine/cache":             {"v1.3.1", core.Runtime, "MIT"},
+		"doctrine/collections":       {"v1.2", core.Runtime, "MIT"},
+		"drupal/address":             {"1.9.0", core.Runtime, "GPL-2.0-or-later"},
+		"symfony/monolog-bundle":     {"v2.6.1", core.Runtime, "MIT"},
+		"symfony/swiftmailer-bundle": {"v2.3.8", core.Runtime, "MIT"},
+		"symfony/symfony":            {"v2.6.1", core.Runtime, "MIT"},
+		"twig/extensions":            {"v1.2.0", core.Runtime, "MIT"},
+		"twig/twig":                  {"v1.16.2", core.Runtime, "BSD-3-Clause"},
+		"sensio/generator-bundle":    {"v2.5.0", core.Development, "MIT"},
 	}
 
 	for name, exp := range expected {
@@ -104,5 +106,23 @@ func TestComposerLock(t *testing.T) {
 		if dep.Scope != exp.scope {
 			t.Errorf("%s scope = %v, want %v", name, dep.Scope, exp.scope)
 		}
+		if dep.License != exp.license {
+			t.Errorf("%s license = %q, want %q", name, dep.License, exp.license)
+		}
+	}
+}
+
+func TestComposerJSONPHP(t *testing.T) {
+	content := []byte(`{"require": {"php": "^8.1", "php-64bit": "*", "ext-json": "*", "monolog/monolog": "^3.0"}}`)
+	res, err := (&composerJSONParser{}).Parse("composer.json", content)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	want := []core.Requirement{{Name: "php", Version: "^8.1", Source: "require"}}
+	if !slices.Equal(res.Runtimes, want) {
+		t.Errorf("Runtimes = %+v, want %+v", res.Runtimes, want)
+	}
+	if len(res.Dependencies) != 1 || res.Dependencies[0].Name != "monolog/monolog" {
+		t.Errorf("unexpected dependencies %+v", res.Dependencies)
 	}
 }
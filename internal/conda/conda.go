@@ -1,10 +1,10 @@
 package conda
 
 import (
+	"context"
 	"github.com/git-pkgs/manifests/internal/core"
+	"path"
 	"strings"
-
-	"gopkg.in/yaml.v3"
 )
 
 const condaChannelURLParts = 4 // scheme + empty + host + channel
@@ -25,9 +25,13 @@ type condaEnvironment struct {
 }
 
 func (p *condaEnvParser) Parse(filename string, content []byte) (*core.Result, error) {
+	return p.ParseLimited(context.Background(), filename, content, core.Limits{})
+}
+
+func (p *condaEnvParser) ParseLimited(ctx context.Context, filename string, content []byte, limits core.Limits) (*core.Result, error) {
 	var env condaEnvironment
-	if err := yaml.Unmarshal(content, &env); err != nil {
-		return nil, &core.ParseError{Filename: filename, Err: err}
+	if err := core.DecodeYAML(filename, content, &env, limits); err != nil {
+		return nil, err
 	}
 
 	var deps []core.Dependency
@@ -85,14 +89,36 @@ type condaLockHash struct {
 	SHA256 string `yaml:"sha256"`
 }
 
+// hashes returns the package's hashes, for the build file named at the
+// end of its URL.
+func (pkg *condaLockPkg) hashes() []core.Hash {
+	var artifact string
+	if pkg.URL != "" {
+		artifact = path.Base(pkg.URL)
+	}
+	var hashes []core.Hash
+	if pkg.Hash.SHA256 != "" {
+		hashes = append(hashes, core.Hash{Algorithm: "sha256", Value: pkg.Hash.SHA256, Artifact: artifact})
+	}
+	if pkg.Hash.MD5 != "" {
+		hashes = append(hashes, core.Hash{Algorithm: "md5", Value: pkg.Hash.MD5, Artifact: artifact})
+	}
+	return hashes
+}
+
 func (p *condaLockParser) Parse(filename string, content []byte) (*core.Result, error) {
+	return p.ParseLimited(context.Background(), filename, content, core.Limits{})
+}
+
+func (p *condaLockParser) ParseLimited(ctx context.Context, filename string, content []byte, limits core.Limits) (*core.Result, error) {
 	var lock condaLockFile
-	if err := yaml.Unmarshal(content, &lock); err != nil {
-		return nil, &core.ParseError{Filename: filename, Err: err}
+	if err := core.DecodeYAML(filename, content, &lock, limits); err != nil {
+		return nil, err
 	}
 
 	var deps []core.Dependency
-	seen := make(map[string]bool)
+	// index maps a name to its entry in deps
+	index := make(map[string]int)
 
 	for _, pkg := range lock.Package {
 		// Skip pip packages - they belong to pypi ecosystem
@@ -100,11 +126,12 @@ func (p *condaLockParser) Parse(filename string, content []byte) (*core.Result,
 			continue
 		}
 
-		// Deduplicate across platforms
-		if seen[pkg.Name] {
+		// Deduplicate across platforms, keeping each platform's hashes
+		if i, ok := index[pkg.Name]; ok {
+			deps[i].Hashes = append(deps[i].Hashes, pkg.hashes()...)
 			continue
 		}
-		seen[pkg.Name] = true
+		index[pkg.Name] = len(deps)
 
 		scope := core.Runtime
 		if pkg.Category == "dev" {
@@ -135,6 +162,7 @@ func (p *condaLockParser) Parse(filename string, content []byte) (*core.Result,
 			Integrity:   integrity,
 			Direct:      false,
 			RegistryURL: registryURL,
+			Hashes:      pkg.hashes(),
 		})
 	}
 
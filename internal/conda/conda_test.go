@@ -162,3 +162,44 @@ func TestCondaLock(t *testing.T) {
 		t.Error("expected black (pip package) to be excluded")
 	}
 }
+
+func TestCondaLockHashesAcrossPlatforms(t *testing.T) {
+	content := []byte(`version: 1
+package:
+  - name: zlib
+    version: 1.3.1
+    manager: conda
+    platform: linux-64
+    url: https://conda.anaconda.org/conda-forge/linux-64/zlib-1.3.1-h4ab18f5_1.conda
+    hash:
+      md5: 9653f1bf3766164d0e65fa723cabbc54
+      sha256: cee16ab07a11303de721915f0a269e8c7a54a5c834aa52f74b1cc3a59000ade8
+    category: main
+  - name: zlib
+    version: 1.3.1
+    manager: conda
+    platform: osx-arm64
+    url: https://conda.anaconda.org/conda-forge/osx-arm64/zlib-1.3.1-hfb2fe0b_1.conda
+    hash:
+      md5: 636077128927cf79fd933276dc3aed47
+      sha256: c34365dd37b0eab27b9693af32a1f7f284955517c2cc91f1b88a7ef4738ff03e
+    category: main
+`)
+	res, err := (&condaLockParser{}).Parse("conda-lock.yml", content)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if len(res.Dependencies) != 1 {
+		t.Fatalf("expected 1 dependency, got %d", len(res.Dependencies))
+	}
+	dep := res.Dependencies[0]
+	if dep.Integrity != "sha256-cee16ab07a11303de721915f0a269e8c7a54a5c834aa52f74b1cc3a59000ade8" {
+		t.Errorf("Integrity = %q", dep.Integrity)
+	}
+	if len(dep.Hashes) != 4 {
+		t.Fatalf("expected 4 hashes, got %+v", dep.Hashes)
+	}
+	if h := dep.Hashes[2]; h.Algorithm != "sha256" || h.Artifact != "zlib-1.3.1-hfb2fe0b_1.conda" {
+		t.Errorf("unexpected osx-arm64 hash %+v", h)
+	}
+}
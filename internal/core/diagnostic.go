@@ -0,0 +1,58 @@
+package core
+
+import "fmt"
+
+// Severity says how much a diagnostic affects the completeness of a
+// result.
+type Severity string
+
+const (
+	// SeverityWarning means the file declares something the parser could
+	// not report, so dependencies may be missing or incomplete.
+	SeverityWarning Severity = "warning"
+	// SeverityInfo means the parser left something out deliberately,
+	// such as a dependency on a local path, and nothing external is lost.
+	SeverityInfo Severity = "info"
+)
+
+// Diagnostic codes.
+const (
+	// CodeUnresolvedVariable is a value that refers to a variable,
+	// build argument or property the parser can't resolve.
+	CodeUnresolvedVariable = "unresolved-variable"
+	// CodeUnfollowedInclude is a reference to another file whose
+	// dependencies aren't read, such as requirements.txt's -r.
+	CodeUnfollowedInclude = "unfollowed-include"
+	// CodeSkippedDependency is a dependency declared in a form that isn't
+	// reported: a local path, a URL or an editable install.
+	CodeSkippedDependency = "skipped-dependency"
+	// CodeNonRegistrySource is a dependency installed from a path, URL
+	// or version control repository rather than a registry. It's reported,
+	// but its version isn't a registry version.
+	CodeNonRegistrySource = "non-registry-source"
+	// CodeUnrecognisedLine is a line the parser couldn't make sense of.
+	CodeUnrecognisedLine = "unrecognised-line"
+	// CodeUnknownFormatVersion is a lockfile that doesn't declare its
+	// format version, read as the oldest one. Versions newer than the
+	// parser knows are an UnsupportedFormatVersionError instead.
+	CodeUnknownFormatVersion = "unknown-format-version"
+)
+
+// Diagnostic records input a parser skipped or only partly understood.
+type Diagnostic struct {
+	Severity Severity
+	Code     string
+	Message  string
+	// Line is 1-based, or 0 when the parser doesn't know it.
+	Line int
+}
+
+// Warning returns a SeverityWarning diagnostic.
+func Warning(line int, code, format string, args ...any) Diagnostic {
+	return Diagnostic{Severity: SeverityWarning, Code: code, Message: fmt.Sprintf(format, args...), Line: line}
+}
+
+// Info returns a SeverityInfo diagnostic.
+func Info(line int, code, format string, args ...any) Diagnostic {
+	return Diagnostic{Severity: SeverityInfo, Code: code, Message: fmt.Sprintf(format, args...), Line: line}
+}
@@ -0,0 +1,153 @@
+package core
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+)
+
+// Edit replaces the bytes of Span with Text.
+type Edit struct {
+	Span
+	Text string
+}
+
+// VersionEditor is implemented by manifest parsers that can rewrite a
+// dependency's version in place.
+type VersionEditor interface {
+	// EditVersion returns the edit that sets dep's version to version,
+	// touching nothing but the version text. decl is the span of dep's
+	// declaration in content, from its Position. ok is false when the
+	// declaration has no version of its own, e.g. one managed elsewhere
+	// or left unconstrained. An error means version can't be written
+	// there.
+	EditVersion(content []byte, decl Span, dep *Dependency, version string) (edit Edit, ok bool, err error)
+}
+
+// ErrUnencodableVersion is returned by VersionEditors for versions
+// that can't be written at the declaration, such as one holding a
+// newline or the quote that encloses it.
+var ErrUnencodableVersion = errors.New("version can't be written in place")
+
+// ErrSharedVersion is returned by VersionEditors when the version is
+// set somewhere other dependencies also read it from, such as a POM
+// property, so changing it would change theirs too.
+var ErrSharedVersion = errors.New("version is shared with other dependencies")
+
+// QuotedVersion encodes version for a string literal delimited by
+// quote, as in JSON, TOML and Ruby. Double-quoted strings get
+// backslash escapes; single-quoted strings can't hold a single quote.
+func QuotedVersion(version string, quote byte) (string, error) {
+	if strings.ContainsAny(version, "\r\n") {
+		return "", ErrUnencodableVersion
+	}
+	switch quote {
+	case '"':
+		return strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(version), nil
+	case '\'':
+		if strings.ContainsRune(version, '\'') {
+			return "", ErrUnencodableVersion
+		}
+		return version, nil
+	}
+	return BareVersion(version)
+}
+
+// BareVersion checks that version can stand as an unquoted token, as in
+// go.mod or a Dockerfile FROM line.
+func BareVersion(version string) (string, error) {
+	if version == "" || strings.ContainsAny(version, " \t\r\n\"'") {
+		return "", ErrUnencodableVersion
+	}
+	return version, nil
+}
+
+// StringLiteralAt returns the span of the contents of the quoted string
+// starting at content[start], and its quote. Backslash escapes are
+// skipped over when quote is '"'. ok is false when there's no string
+// there or it's unterminated.
+func StringLiteralAt(content []byte, start int) (Span, byte, bool) {
+	if start >= len(content) || (content[start] != '"' && content[start] != '\'') {
+		return Span{}, 0, false
+	}
+	quote := content[start]
+	for i := start + 1; i < len(content); i++ {
+		switch content[i] {
+		case '\\':
+			if quote == '"' {
+				i++
+			}
+		case quote:
+			return Span{Start: start + 1, End: i}, quote, true
+		case '\n':
+			return Span{}, 0, false
+		}
+	}
+	return Span{}, 0, false
+}
+
+// FindAfter returns the span of the first occurrence of text within
+// content[within] that follows the first occurrence of after, such as a
+// version following the package name it belongs to.
+func FindAfter(content []byte, within Span, after, text string) (Span, bool) {
+	s := string(content[within.Start:within.End])
+	i := strings.Index(s, after)
+	if i < 0 || text == "" {
+		return Span{}, false
+	}
+	i += len(after)
+	j := strings.Index(s[i:], text)
+	if j < 0 {
+		return Span{}, false
+	}
+	start := within.Start + i + j
+	return Span{Start: start, End: start + len(text)}, true
+}
+
+// tomlVersionKey matches a version key inside an inline table or a
+// dependency's own table.
+var tomlVersionKey = regexp.MustCompile(`(?m)(?:^|[{,\s])version\s*=\s*`)
+
+// TOMLVersionSpan finds the version string of a TOML dependency
+// declared as name = "1.0", name = { version = "1.0" },
+// name.version = "1.0", or a [table.name] table with a version key.
+// decl spans the key line or the whole table. It returns the span of
+// the string's contents and its quote; ok is false when the declaration
+// has no version string.
+func TOMLVersionSpan(content []byte, decl Span) (Span, byte, bool) {
+	text := string(content[decl.Start:decl.End])
+	from := 0
+	if !strings.HasPrefix(text, "[") {
+		eq := strings.IndexByte(text, '=')
+		if eq < 0 {
+			return Span{}, 0, false
+		}
+		// A dotted key sets one field: name.version = "1.0"
+		key := strings.TrimSpace(text[:eq])
+		if strings.Contains(key, ".") && !strings.HasPrefix(key, `"`) && !strings.HasSuffix(key, ".version") {
+			return Span{}, 0, false
+		}
+		i := SkipSpaces(content, decl.Start+eq+1)
+		if span, quote, ok := StringLiteralAt(content, i); ok {
+			return span, quote, true
+		}
+		if i >= len(content) || content[i] != '{' {
+			return Span{}, 0, false
+		}
+		from = i - decl.Start
+	}
+	loc := tomlVersionKey.FindStringIndex(text[from:])
+	if loc == nil {
+		return Span{}, 0, false
+	}
+	return StringLiteralAt(content, decl.Start+from+loc[1])
+}
+
+// SkipSpaces returns the offset of the first byte at or after i that
+// isn't a space or tab.
+func SkipSpaces(content []byte, i int) int {
+	for i < len(content) && (content[i] == ' ' || content[i] == '\t') {
+		i++
+	}
+	return i
+}
@@ -0,0 +1,185 @@
+package core
+
+// Node identifies a single resolved package in a dependency graph.
+type Node struct {
+	Name    string
+	Version string
+}
+
+// Edge records that From depends on To.
+type Edge struct {
+	From Node
+	To   Node
+}
+
+// Graph holds the parent→child relationships recorded by a lockfile.
+type Graph struct {
+	// Roots are the packages the project itself depends on. Some
+	// lockfiles don't record this; Roots is then empty and path
+	// queries start from nodes that nothing else depends on.
+	Roots []Node
+	Nodes []Node
+	Edges []Edge
+}
+
+// Children returns the nodes n depends on, in the order they were recorded.
+func (g *Graph) Children(n Node) []Node {
+	if g == nil {
+		return nil
+	}
+	var out []Node
+	for _, e := range g.Edges {
+		if e.From == n {
+			out = append(out, e.To)
+		}
+	}
+	return out
+}
+
+// Parents returns the nodes that depend on n.
+func (g *Graph) Parents(n Node) []Node {
+	if g == nil {
+		return nil
+	}
+	var out []Node
+	for _, e := range g.Edges {
+		if e.To == n {
+			out = append(out, e.From)
+		}
+	}
+	return out
+}
+
+// Path returns the shortest chain of nodes from a root to the package
+// with the given name, answering "why is this package here". An empty
+// version matches any version. The first element is the root and the
+// last is the matched package. Returns nil when no node matches or the
+// node is unreachable.
+func (g *Graph) Path(name, version string) []Node {
+	if g == nil {
+		return nil
+	}
+
+	adj := make(map[Node][]Node, len(g.Nodes))
+	for _, e := range g.Edges {
+		adj[e.From] = append(adj[e.From], e.To)
+	}
+
+	starts := g.Roots
+	if len(starts) == 0 {
+		starts = g.sources()
+	}
+
+	parent := make(map[Node]Node, len(g.Nodes))
+	visited := make(map[Node]bool, len(g.Nodes))
+	queue := make([]Node, 0, len(starts))
+	for _, r := range starts {
+		if !visited[r] {
+			visited[r] = true
+			queue = append(queue, r)
+		}
+	}
+
+	for len(queue) > 0 {
+		n := queue[0]
+		queue = queue[1:]
+		if n.Name == name && (version == "" || n.Version == version) {
+			path := []Node{n}
+			for {
+				p, ok := parent[n]
+				if !ok {
+					break
+				}
+				path = append(path, p)
+				n = p
+			}
+			for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+				path[i], path[j] = path[j], path[i]
+			}
+			return path
+		}
+		for _, c := range adj[n] {
+			if !visited[c] {
+				visited[c] = true
+				parent[c] = n
+				queue = append(queue, c)
+			}
+		}
+	}
+	return nil
+}
+
+// sources returns nodes with no incoming edges.
+func (g *Graph) sources() []Node {
+	hasParent := make(map[Node]bool, len(g.Edges))
+	for _, e := range g.Edges {
+		hasParent[e.To] = true
+	}
+	var out []Node
+	for _, n := range g.Nodes {
+		if !hasParent[n] {
+			out = append(out, n)
+		}
+	}
+	return out
+}
+
+// GraphBuilder accumulates nodes and edges, dropping duplicates.
+type GraphBuilder struct {
+	graph Graph
+	nodes map[Node]bool
+	edges map[Edge]bool
+	roots map[Node]bool
+}
+
+// NewGraphBuilder returns an empty GraphBuilder.
+func NewGraphBuilder() *GraphBuilder {
+	return &GraphBuilder{
+		nodes: make(map[Node]bool),
+		edges: make(map[Edge]bool),
+		roots: make(map[Node]bool),
+	}
+}
+
+// AddNode records n if it hasn't been seen.
+func (b *GraphBuilder) AddNode(n Node) {
+	if b.nodes[n] {
+		return
+	}
+	b.nodes[n] = true
+	b.graph.Nodes = append(b.graph.Nodes, n)
+}
+
+// AddRoot records n as a direct dependency of the project.
+func (b *GraphBuilder) AddRoot(n Node) {
+	b.AddNode(n)
+	if b.roots[n] {
+		return
+	}
+	b.roots[n] = true
+	b.graph.Roots = append(b.graph.Roots, n)
+}
+
+// AddEdge records that from depends on to. Self-edges are ignored.
+func (b *GraphBuilder) AddEdge(from, to Node) {
+	if from == to {
+		return
+	}
+	b.AddNode(from)
+	b.AddNode(to)
+	e := Edge{From: from, To: to}
+	if b.edges[e] {
+		return
+	}
+	b.edges[e] = true
+	b.graph.Edges = append(b.graph.Edges, e)
+}
+
+// Graph returns the accumulated graph, or nil if nothing was added.
+func (b *GraphBuilder) Graph() *Graph {
+	if len(b.graph.Nodes) == 0 {
+		return nil
+	}
+	g := b.graph
+	return &g
+}
@@ -86,3 +86,43 @@ func ParseDockerImage(image string) (name, version string) {
 	// No tag or digest, default to latest
 	return image, "latest"
 }
+
+// StripJSONComments blanks out // and /* */ comments outside strings,
+// for the JSON-with-comments files some tools accept (rush.json,
+// global.json). Line breaks are kept so offsets in errors still point at
+// the right line.
+func StripJSONComments(content []byte) []byte {
+	out := make([]byte, len(content))
+	copy(out, content)
+	inString := false
+	for i := 0; i < len(out); i++ {
+		c := out[i]
+		switch {
+		case inString:
+			if c == '\\' {
+				i++
+			} else if c == '"' {
+				inString = false
+			}
+		case c == '"':
+			inString = true
+		case c == '/' && i+1 < len(out) && out[i+1] == '/':
+			for ; i < len(out) && out[i] != '\n'; i++ {
+				out[i] = ' '
+			}
+		case c == '/' && i+1 < len(out) && out[i+1] == '*':
+			end := i + 2
+			for end < len(out) && !(out[end] == '*' && end+1 < len(out) && out[end+1] == '/') {
+				end++
+			}
+			end = min(end+2, len(out))
+			for ; i < end; i++ {
+				if out[i] != '\n' {
+					out[i] = ' '
+				}
+			}
+			i--
+		}
+	}
+	return out
+}
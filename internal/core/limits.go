@@ -0,0 +1,235 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"strconv"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Limits bounds the work a parser does on one file. Zero fields are
+// unlimited.
+type Limits struct {
+	// MaxInputSize is the largest file, in bytes, that will be parsed.
+	MaxInputSize int
+	// MaxDependencies is the most dependencies a result may hold.
+	// LimitedParsers that call Check stop as soon as it's passed.
+	MaxDependencies int
+	// MaxDepth is the deepest nesting of YAML, JSON or XML structures
+	// that LimitedParsers and FSRootParsers will decode.
+	MaxDepth int
+	// MaxAliases is the most YAML alias expansions LimitedParsers will
+	// perform, counting an alias again each time the anchor containing it
+	// is expanded.
+	MaxAliases int
+}
+
+// Errors wrapped by LimitError, for use with errors.Is.
+var (
+	ErrInputTooLarge       = errors.New("input too large")
+	ErrTooManyDependencies = errors.New("too many dependencies")
+	ErrTooDeep             = errors.New("nesting too deep")
+	ErrTooManyAliases      = errors.New("too many YAML alias expansions")
+)
+
+// LimitError is returned when a file exceeds one of the Limits.
+type LimitError struct {
+	Filename string
+	// Err is ErrInputTooLarge, ErrTooManyDependencies, ErrTooDeep or
+	// ErrTooManyAliases.
+	Err error
+	// Limit is the value that was exceeded.
+	Limit int
+}
+
+func (e *LimitError) Error() string {
+	return e.Filename + ": " + e.Err.Error() + " (limit " + strconv.Itoa(e.Limit) + ")"
+}
+
+func (e *LimitError) Unwrap() error {
+	return e.Err
+}
+
+// LimitedParser is optionally implemented by parsers that decode nested
+// structures, so that MaxDepth and MaxAliases can be enforced before
+// the whole structure is built, or that read files large enough to be
+// worth stopping part way: those call Check as they go, so that ctx and
+// MaxDependencies end the parse early. Parse is equivalent to
+// ParseLimited with a background context and zero Limits.
+type LimitedParser interface {
+	ParseLimited(ctx context.Context, filename string, content []byte, limits Limits) (*Result, error)
+}
+
+// Check is called by LimitedParsers as they collect dependencies, with
+// the number collected so far. It returns a *LimitError once deps is
+// over MaxDependencies, or ctx's error once it's done.
+func (l Limits) Check(ctx context.Context, filename string, deps int) error {
+	if l.MaxDependencies > 0 && deps > l.MaxDependencies {
+		return &LimitError{Filename: filename, Err: ErrTooManyDependencies, Limit: l.MaxDependencies}
+	}
+	return ctx.Err()
+}
+
+// LimitedWorkspaceReader is optionally implemented by WorkspaceReaders
+// that decode YAML, so that MaxDepth and MaxAliases apply to workspace
+// definitions as they do to manifests.
+type LimitedWorkspaceReader interface {
+	ReadWorkspaceLimited(filename string, content []byte, limits Limits) (*WorkspaceDefinition, error)
+}
+
+// DecodeYAML unmarshals content into v, as yaml.Unmarshal does, after
+// checking the document against limits.MaxDepth and limits.MaxAliases.
+// Errors are a *LimitError or a *ParseError for filename.
+func DecodeYAML(filename string, content []byte, v any, limits Limits) error {
+	if limits.MaxDepth <= 0 && limits.MaxAliases <= 0 {
+		if err := yaml.Unmarshal(content, v); err != nil {
+			return &ParseError{Filename: filename, Err: err}
+		}
+		return nil
+	}
+
+	// A node tree keeps aliases unexpanded, so it's no bigger than the
+	// input, and the expanded shape can be measured without building it.
+	var doc yaml.Node
+	if err := yaml.Unmarshal(content, &doc); err != nil {
+		return &ParseError{Filename: filename, Err: err}
+	}
+	m := yamlMeasure{seen: make(map[*yaml.Node]yamlSize), open: make(map[*yaml.Node]bool)}
+	size, err := m.measure(&doc)
+	if err != nil {
+		return &ParseError{Filename: filename, Err: err}
+	}
+	if limits.MaxDepth > 0 && size.depth > limits.MaxDepth {
+		return &LimitError{Filename: filename, Err: ErrTooDeep, Limit: limits.MaxDepth}
+	}
+	if limits.MaxAliases > 0 && size.aliases > limits.MaxAliases {
+		return &LimitError{Filename: filename, Err: ErrTooManyAliases, Limit: limits.MaxAliases}
+	}
+	if len(doc.Content) == 0 {
+		return nil
+	}
+	if err := doc.Decode(v); err != nil {
+		return &ParseError{Filename: filename, Err: err}
+	}
+	return nil
+}
+
+// yamlSize is the shape of a YAML node with its aliases expanded.
+type yamlSize struct {
+	depth   int
+	aliases int
+}
+
+// yamlMeasure computes yamlSizes, remembering each node's so that an
+// anchor aliased many times is only walked once.
+type yamlMeasure struct {
+	seen map[*yaml.Node]yamlSize
+	// open holds the nodes being measured, to catch an anchor that
+	// contains an alias to itself.
+	open map[*yaml.Node]bool
+}
+
+// maxYAMLCount caps alias counts, which grow exponentially with nested
+// anchors, well short of overflow.
+const maxYAMLCount = 1 << 40
+
+func (m *yamlMeasure) measure(n *yaml.Node) (yamlSize, error) {
+	if size, ok := m.seen[n]; ok {
+		return size, nil
+	}
+	if m.open[n] {
+		return yamlSize{}, errors.New("anchor contains an alias to itself")
+	}
+	m.open[n] = true
+	defer delete(m.open, n)
+
+	var size yamlSize
+	if n.Kind == yaml.AliasNode {
+		if n.Alias != nil {
+			target, err := m.measure(n.Alias)
+			if err != nil {
+				return yamlSize{}, err
+			}
+			size = target
+		}
+		size.aliases = min(size.aliases+1, maxYAMLCount)
+	} else {
+		for _, child := range n.Content {
+			c, err := m.measure(child)
+			if err != nil {
+				return yamlSize{}, err
+			}
+			size.depth = max(size.depth, c.depth)
+			size.aliases = min(size.aliases+c.aliases, maxYAMLCount)
+		}
+		if n.Kind == yaml.MappingNode || n.Kind == yaml.SequenceNode {
+			size.depth++
+		}
+	}
+	m.seen[n] = size
+	return size, nil
+}
+
+// CheckJSONDepth returns a *LimitError if content nests JSON objects
+// and arrays deeper than maxDepth. It scans the bytes without decoding,
+// so it can run before an unmarshal that would build the whole tree.
+func CheckJSONDepth(filename string, content []byte, maxDepth int) error {
+	if maxDepth <= 0 {
+		return nil
+	}
+	depth := 0
+	inString := false
+	for i := 0; i < len(content); i++ {
+		c := content[i]
+		if inString {
+			switch c {
+			case '\\':
+				i++
+			case '"':
+				inString = false
+			}
+			continue
+		}
+		switch c {
+		case '"':
+			inString = true
+		case '{', '[':
+			depth++
+			if depth > maxDepth {
+				return &LimitError{Filename: filename, Err: ErrTooDeep, Limit: maxDepth}
+			}
+		case '}', ']':
+			depth--
+		}
+	}
+	return nil
+}
+
+// CheckXMLDepth returns a *LimitError if content nests XML elements
+// deeper than maxDepth, reading tokens without building a tree. Malformed
+// XML is left for the real parser to report.
+func CheckXMLDepth(filename string, content []byte, maxDepth int) error {
+	if maxDepth <= 0 {
+		return nil
+	}
+	d := xml.NewDecoder(bytes.NewReader(content))
+	depth := 0
+	for {
+		tok, err := d.RawToken()
+		if err != nil {
+			return nil
+		}
+		switch tok.(type) {
+		case xml.StartElement:
+			depth++
+			if depth > maxDepth {
+				return &LimitError{Filename: filename, Err: ErrTooDeep, Limit: maxDepth}
+			}
+		case xml.EndElement:
+			depth--
+		}
+	}
+}
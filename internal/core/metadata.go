@@ -0,0 +1,60 @@
+package core
+
+import "strings"
+
+// Metadata describes the package a manifest declares, beyond its name
+// and version. Fields the format doesn't have, or the file leaves out,
+// are empty.
+type Metadata struct {
+	// License is the declared license, as written. For formats that
+	// list licenses separately it's built with JoinLicenses.
+	License     string
+	Description string
+	Homepage    string
+	// Repository is the source repository URL.
+	Repository string
+	// Authors lists authors, or maintainers for formats that only name
+	// those, formatted with Author.
+	Authors  []string
+	Keywords []string
+}
+
+// IsZero reports whether no metadata was found.
+func (m *Metadata) IsZero() bool {
+	return m.License == "" && m.Description == "" && m.Homepage == "" &&
+		m.Repository == "" && len(m.Authors) == 0 && len(m.Keywords) == 0
+}
+
+// JoinLicenses combines licenses listed separately into one SPDX-style
+// expression joined by op ("OR" or "AND"). Compound entries are
+// parenthesised and empty ones dropped.
+func JoinLicenses(op string, licenses ...string) string {
+	var parts []string
+	for _, l := range licenses {
+		if l = strings.TrimSpace(l); l != "" {
+			parts = append(parts, l)
+		}
+	}
+	if len(parts) < 2 {
+		return strings.Join(parts, "")
+	}
+	for i, l := range parts {
+		if strings.Contains(l, " ") {
+			parts[i] = "(" + l + ")"
+		}
+	}
+	return strings.Join(parts, " "+op+" ")
+}
+
+// Author formats a person as "Name <email>", or just the name or email
+// when only one is known.
+func Author(name, email string) string {
+	name, email = strings.TrimSpace(name), strings.TrimSpace(email)
+	switch {
+	case email == "":
+		return name
+	case name == "":
+		return email
+	}
+	return name + " <" + email + ">"
+}
@@ -0,0 +1,207 @@
+package core
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+	"strings"
+)
+
+// Position locates a declaration in its source file. Lines and columns
+// are 1-based and columns count bytes. EndColumn is one past the last
+// byte of the declaration. The zero value means the position is unknown.
+type Position struct {
+	File        string
+	StartLine   int
+	StartColumn int
+	EndLine     int
+	EndColumn   int
+}
+
+// IsValid reports whether the position was filled in by a parser.
+func (p Position) IsValid() bool {
+	return p.StartLine > 0
+}
+
+// LineSpan returns the position and text of a line's content with leading
+// and trailing whitespace excluded. lineNo is 1-based.
+func LineSpan(lineNo int, line string) (Position, string) {
+	trimmed := strings.TrimSpace(line)
+	start := strings.Index(line, trimmed)
+	return Position{
+		StartLine:   lineNo,
+		StartColumn: start + 1,
+		EndLine:     lineNo,
+		EndColumn:   start + len(trimmed) + 1,
+	}, trimmed
+}
+
+// LineIndex converts byte offsets into line and column positions.
+type LineIndex struct {
+	starts []int
+}
+
+// NewLineIndex records the offset at which each line of content begins.
+func NewLineIndex(content []byte) *LineIndex {
+	starts := make([]int, 1, bytes.Count(content, []byte{'\n'})+1)
+	for i, b := range content {
+		if b == '\n' {
+			starts = append(starts, i+1)
+		}
+	}
+	return &LineIndex{starts: starts}
+}
+
+// Span converts the byte range [start, end) into a Position.
+func (li *LineIndex) Span(start, end int) Position {
+	sl, sc := li.lineCol(start)
+	el, ec := li.lineCol(end)
+	return Position{StartLine: sl, StartColumn: sc, EndLine: el, EndColumn: ec}
+}
+
+// Offsets converts a Position back into the byte range [start, end).
+func (li *LineIndex) Offsets(p Position) (int, int) {
+	return li.offset(p.StartLine, p.StartColumn), li.offset(p.EndLine, p.EndColumn)
+}
+
+func (li *LineIndex) offset(line, col int) int {
+	if line < 1 || line > len(li.starts) {
+		return 0
+	}
+	return li.starts[line-1] + col - 1
+}
+
+func (li *LineIndex) lineCol(offset int) (int, int) {
+	line := sort.Search(len(li.starts), func(i int) bool { return li.starts[i] > offset }) - 1
+	return line + 1, offset - li.starts[line] + 1
+}
+
+// Span is a byte range [Start, End) within a file.
+type Span struct {
+	Start int
+	End   int
+}
+
+// Locator maps declarations, grouped by section and keyed by name, to
+// their positions and raw text.
+type Locator struct {
+	content []byte
+	spans   map[string]map[string]Span
+	lines   *LineIndex
+}
+
+// NewLocator builds a Locator from spans a parser has already found.
+func NewLocator(content []byte, spans map[string]map[string]Span) *Locator {
+	return &Locator{content: content, spans: spans, lines: NewLineIndex(content)}
+}
+
+// NewJSONLocator indexes the members of a JSON document's top-level
+// objects, e.g. each package inside "dependencies".
+func NewJSONLocator(content []byte) *Locator {
+	return NewLocator(content, jsonMemberSpans(content))
+}
+
+// Locate returns the position and raw text of key inside section.
+// Returns a zero Position when not found.
+func (l *Locator) Locate(section, key string) (Position, string) {
+	span, ok := l.spans[section][key]
+	if !ok {
+		return Position{}, ""
+	}
+	return l.lines.Span(span.Start, span.End), string(l.content[span.Start:span.End])
+}
+
+// jsonMemberSpans scans a JSON document whose top level is an object and,
+// for every top-level member whose value is itself an object, records the
+// byte span of each of that object's members (from the opening quote of
+// the key to the end of the value). The result is keyed by top-level key
+// and then by member name, e.g. spans["dependencies"]["lodash"].
+// Malformed input yields whatever was collected before the error.
+func jsonMemberSpans(content []byte) map[string]map[string]Span {
+	spans := make(map[string]map[string]Span)
+	dec := json.NewDecoder(bytes.NewReader(content))
+
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+		return spans
+	}
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return spans
+		}
+		key, _ := tok.(string)
+
+		tok, err = dec.Token()
+		if err != nil {
+			return spans
+		}
+		if tok != json.Delim('{') {
+			if d, ok := tok.(json.Delim); ok {
+				if skipJSONValue(dec, d) != nil {
+					return spans
+				}
+			}
+			continue
+		}
+
+		members := make(map[string]Span)
+		spans[key] = members
+		for dec.More() {
+			start := skipJSONSeparators(content, int(dec.InputOffset()))
+			tok, err := dec.Token()
+			if err != nil {
+				return spans
+			}
+			name, _ := tok.(string)
+			tok, err = dec.Token()
+			if err != nil {
+				return spans
+			}
+			if d, ok := tok.(json.Delim); ok {
+				if skipJSONValue(dec, d) != nil {
+					return spans
+				}
+			}
+			members[name] = Span{Start: start, End: int(dec.InputOffset())}
+		}
+		if _, err := dec.Token(); err != nil { // closing }
+			return spans
+		}
+	}
+	return spans
+}
+
+// skipJSONValue consumes tokens up to the delimiter matching open.
+func skipJSONValue(dec *json.Decoder, open json.Delim) error {
+	if open != '{' && open != '[' {
+		return nil
+	}
+	depth := 1
+	for depth > 0 {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		switch tok {
+		case json.Delim('{'), json.Delim('['):
+			depth++
+		case json.Delim('}'), json.Delim(']'):
+			depth--
+		}
+	}
+	return nil
+}
+
+// skipJSONSeparators advances past whitespace, commas and colons, which
+// json.Decoder consumes silently between tokens.
+func skipJSONSeparators(content []byte, offset int) int {
+	for offset < len(content) {
+		switch content[offset] {
+		case ' ', '\t', '\r', '\n', ',', ':':
+			offset++
+		default:
+			return offset
+		}
+	}
+	return offset
+}
@@ -11,11 +11,18 @@ type Registration struct {
 	Kind      Kind
 	Parser    Parser
 	Match     func(filename string) bool
+	// Format marks a file format that isn't a package ecosystem, such as
+	// an SBOM, whose dependencies carry their own Ecosystem.
+	Format bool
 }
 
+// parsers is only appended to by Register from package init functions,
+// so it's read-only by the time anything parses and lookups need no
+// locking.
 var parsers []Registration
 
-// Register adds a parser to the registry.
+// Register adds a parser to the registry. It must only be called from
+// an init function.
 func Register(ecosystem string, kind Kind, parser Parser, match func(string) bool) {
 	parsers = append(parsers, Registration{
 		Ecosystem: ecosystem,
@@ -25,6 +32,20 @@ func Register(ecosystem string, kind Kind, parser Parser, match func(string) boo
 	})
 }
 
+// RegisterFormat adds a parser for a file format, such as an SBOM, that
+// lists packages from many ecosystems. It's identified and parsed like
+// any other, with name in place of the ecosystem, but isn't one of the
+// SupportedEcosystems. It must only be called from an init function.
+func RegisterFormat(name string, kind Kind, parser Parser, match func(string) bool) {
+	parsers = append(parsers, Registration{
+		Ecosystem: name,
+		Kind:      kind,
+		Parser:    parser,
+		Match:     match,
+		Format:    true,
+	})
+}
+
 // IdentifyParser returns the first matching parser for a filename.
 func IdentifyParser(filename string) (Parser, string, Kind) { //nolint:ireturn
 	base := filepath.Base(filename)
@@ -57,12 +78,13 @@ func IdentifyAllParsers(filename string) []Match {
 	return matches
 }
 
-// SupportedEcosystems returns all registered ecosystem types.
+// SupportedEcosystems returns all registered ecosystem types, leaving
+// out the formats added with RegisterFormat.
 func SupportedEcosystems() []string {
 	seen := make(map[string]bool)
 	var ecosystems []string
 	for _, reg := range parsers {
-		if !seen[reg.Ecosystem] {
+		if !reg.Format && !seen[reg.Ecosystem] {
 			seen[reg.Ecosystem] = true
 			ecosystems = append(ecosystems, reg.Ecosystem)
 		}
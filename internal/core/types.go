@@ -1,6 +1,12 @@
 // Package core provides shared types and the parser registry.
 package core
 
+import (
+	"context"
+	"strconv"
+	"strings"
+)
+
 // Kind distinguishes manifest files from lockfiles.
 type Kind string
 
@@ -30,6 +36,105 @@ type Dependency struct {
 	Direct      bool
 	PURL        string
 	RegistryURL string
+	// Position is where the dependency is declared, for parsers that
+	// track it. The zero value means unknown.
+	Position Position
+	// Raw is the declaration text exactly as it appears in the file.
+	Raw string
+	// Range is Version parsed with the ecosystem's range syntax. For
+	// lockfiles it holds exactly the resolved version. Nil when Version
+	// isn't a version range (a git URL, a path, a dist-tag and so on).
+	Range *VersionRange
+	// Ecosystem is the package's own ecosystem when it differs from the
+	// file's, as in SBOMs that list packages from several ecosystems.
+	// Empty otherwise.
+	Ecosystem string
+	// License is the package's declared license, for lockfiles that
+	// record one. Alternatives listed separately are joined with
+	// JoinLicenses. Empty when unknown.
+	License string
+	// Hashes lists every hash the lockfile records for the package,
+	// one per algorithm and artifact: each wheel and the sdist in
+	// Python lockfiles, each platform's build in conda-lock.yml.
+	// Integrity remains the single primary hash. Nil for formats that
+	// record at most one.
+	Hashes []Hash
+	// Workspace is true when the dependency is another member of the
+	// same monorepo workspace rather than a registry package. Parsers
+	// set it for explicit workspace references such as npm's
+	// "workspace:*"; workspace discovery sets it for any dependency
+	// naming a member.
+	Workspace bool
+	// Replacement is the directive that substituted this dependency for
+	// the one declared, such as a go.mod replace. Its Name and Version
+	// are the original; the dependency's own are the replacement's,
+	// except for a local directory, which has no version and leaves the
+	// dependency's name and version as declared. Nil when nothing was
+	// replaced.
+	Replacement *Replacement
+}
+
+// Hash is a digest of one of a package's artifacts.
+type Hash struct {
+	// Algorithm is the lowercase algorithm name, such as "sha256".
+	Algorithm string
+	// Value is the digest as the lockfile writes it, which for the
+	// formats that list several is lowercase hex.
+	Value string
+	// Artifact is the file name the digest is for, such as a wheel's.
+	// Empty when the lockfile doesn't say.
+	Artifact string
+}
+
+// Replacement is a directive substituting one package for another,
+// such as a replace directive in go.mod or go.work.
+type Replacement struct {
+	// Name and Version are the package replaced. An empty Version
+	// replaces every version.
+	Name    string
+	Version string
+	// NewName is the replacement package, or a local directory.
+	NewName string
+	// NewVersion is the replacement's version. Empty for a directory.
+	NewVersion string
+	// Position is where the directive is written; Raw is its text.
+	Position Position
+	Raw      string
+}
+
+// Exclusion rules a package version out of resolution, as go.mod's
+// exclude directive does.
+type Exclusion struct {
+	Name     string
+	Version  string
+	Position Position
+	Raw      string
+}
+
+// Retraction withdraws versions of the package itself, as go.mod's
+// retract directive does.
+type Retraction struct {
+	// Range holds the retracted versions: a single version or an
+	// inclusive interval.
+	Range *VersionRange
+	// Rationale is the comment on or above the directive. Empty when
+	// there is none.
+	Rationale string
+	Position  Position
+	Raw       string
+}
+
+// Requirement is a runtime or toolchain version a package needs, such
+// as the go directive of go.mod.
+type Requirement struct {
+	// Name is the runtime: "go", "node", "python", and so on.
+	Name string
+	// Version is the version or constraint as written, minus any
+	// prefix naming the runtime ("go1.22.1" becomes "1.22.1").
+	Version string
+	// Source is the field it was read from, such as "go" or
+	// "toolchain", since one file can name a runtime more than once.
+	Source string
 }
 
 // Result is the output of a single parser.
@@ -39,11 +144,39 @@ type Result struct {
 	// that only list dependencies (Gemfile, requirements.txt, etc.).
 	Name string
 	// Version is the package's own version as declared in the manifest.
-	Version      string
+	Version string
+	// Metadata is the rest of what the manifest declares about the
+	// package itself: license, description, links, authors, keywords.
+	Metadata     Metadata
 	Dependencies []Dependency
+	// Graph holds the parent→child relationships between dependencies,
+	// for lockfiles that record them. Nil otherwise.
+	Graph *Graph
+	// Diagnostics lists input the parser skipped or only partly
+	// understood.
+	Diagnostics []Diagnostic
+	// FormatVersion is the lockfile format version recorded in the
+	// file, for formats that record one. Empty otherwise.
+	FormatVersion string
+	// Workspace is the workspace the file declares, for formats
+	// parsed as a workspace in their own right (go.work). Nil otherwise.
+	Workspace *WorkspaceDefinition
+	// Replacements lists the file's package substitutions in file
+	// order.
+	Replacements []Replacement
+	// Excludes and Retractions list go.mod's exclude and retract
+	// directives in file order.
+	Excludes    []Exclusion
+	Retractions []Retraction
+	// Runtimes lists the runtime and toolchain versions the file
+	// requires.
+	Runtimes []Requirement
 }
 
 // Parser is the interface implemented by all manifest parsers.
+// Parsing must be a pure function of its arguments: one Parser value is
+// shared by every caller, so implementations keep no state between
+// calls and must be safe for concurrent use.
 type Parser interface {
 	Parse(filename string, content []byte) (*Result, error)
 }
@@ -51,9 +184,9 @@ type Parser interface {
 // FSRootParser is optionally implemented by parsers that can consult
 // neighbouring files on disk (e.g. pom.xml following <relativePath> to a
 // parent). The fsRoot argument bounds that lookup; an empty string means
-// no filesystem access.
+// no filesystem access. Limits apply as they do to a LimitedParser.
 type FSRootParser interface {
-	ParseInRoot(filename string, content []byte, fsRoot string) (*Result, error)
+	ParseInRoot(ctx context.Context, filename string, content []byte, fsRoot string, limits Limits) (*Result, error)
 }
 
 // ParseError is returned when parsing fails.
@@ -69,3 +202,29 @@ func (e *ParseError) Error() string {
 func (e *ParseError) Unwrap() error {
 	return e.Err
 }
+
+// UnsupportedFormatVersionError is returned when a lockfile declares a
+// format version newer than the parser knows. Reading it with the rules
+// for an older version could silently miss dependencies.
+type UnsupportedFormatVersionError struct {
+	Filename string
+	// Version is the format version found in the file.
+	Version string
+	// Latest is the newest major format version the parser reads.
+	Latest int
+}
+
+func (e *UnsupportedFormatVersionError) Error() string {
+	return "unsupported format version " + e.Version + " in " + e.Filename + " (latest supported is " + strconv.Itoa(e.Latest) + ")"
+}
+
+// CheckFormatVersion returns an *UnsupportedFormatVersionError when the
+// major part of version (9 in pnpm's "9.0") is newer than latest.
+// Versions that don't start with a number are let through.
+func CheckFormatVersion(filename, version string, latest int) error {
+	major, _, _ := strings.Cut(version, ".")
+	if n, err := strconv.Atoi(major); err == nil && n > latest {
+		return &UnsupportedFormatVersionError{Filename: filename, Version: version, Latest: latest}
+	}
+	return nil
+}
@@ -0,0 +1,315 @@
+package core
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/git-pkgs/vers"
+)
+
+// VersionRange is a dependency's version requirement in structured form,
+// parsed with the range syntax of its ecosystem.
+type VersionRange struct {
+	// VERS is the range as a vers URI, e.g. "vers:npm/>=1.2.0|<2.0.0".
+	VERS string
+	// Intervals are the normalised version intervals. A version
+	// satisfies the range if it lies in any of them and isn't excluded.
+	Intervals []VersionInterval
+	// Exclusions are individual versions ruled out, e.g. PEP 440 "!=".
+	Exclusions []string
+
+	r *vers.Range
+}
+
+// VersionInterval is a contiguous span of versions. An empty Min or Max
+// means the interval is unbounded on that side.
+type VersionInterval struct {
+	Min          string
+	Max          string
+	MinInclusive bool
+	MaxInclusive bool
+}
+
+// Satisfies reports whether version lies within the range. A nil range
+// satisfies nothing.
+func (vr *VersionRange) Satisfies(version string) bool {
+	if vr == nil || version == "" {
+		return false
+	}
+	if vr.r == nil {
+		r := &vers.Range{Exclusions: vr.Exclusions}
+		for _, iv := range vr.Intervals {
+			r.Intervals = append(r.Intervals, vers.NewInterval(iv.Min, iv.Max, iv.MinInclusive, iv.MaxInclusive))
+		}
+		return r.Contains(version)
+	}
+	return vr.r.Contains(version)
+}
+
+// versSchemes maps ecosystems to the vers parser whose native syntax
+// they share, where it differs from the ecosystem name.
+var versSchemes = map[string]string{
+	"cocoapods": "gem",
+	"bower":     "npm",
+	"deno":      "npm",
+	"pub":       "npm",
+	"crystal":   "gem",
+}
+
+// refEcosystems pin image tags or git refs rather than versions.
+var refEcosystems = map[string]bool{
+	"docker":         true,
+	"github-actions": true,
+	"git":            true,
+	"nix":            true,
+	"pre-commit":     true,
+}
+
+// ParseVersionRange parses a declared version requirement using the
+// ecosystem's range syntax: npm and Cargo caret/tilde ranges, RubyGems
+// and CocoaPods "~>", PEP 440 specifiers (and Poetry caret/tilde),
+// Maven and NuGet bracket ranges, Composer "~"/"^"/"||", go.mod minimum
+// versions, and so on. An
+// empty requirement matches any version. Returns nil when the
+// requirement isn't a version range, e.g. a git URL, a path, an npm
+// dist-tag or an unresolved Maven property, and for ecosystems that pin
+// image tags or git refs.
+func ParseVersionRange(ecosystem, constraint string) *VersionRange {
+	if refEcosystems[ecosystem] {
+		return nil
+	}
+	constraint = strings.TrimSpace(constraint)
+	if constraint == "" || constraint == "*" {
+		return newVersionRange(ecosystem, vers.Unbounded())
+	}
+
+	scheme := ecosystem
+	if s, ok := versSchemes[ecosystem]; ok {
+		scheme = s
+	}
+
+	switch ecosystem {
+	case "cargo":
+		constraint = cargoVersConstraint(constraint)
+	case "pypi":
+		constraint, scheme = pypiVersConstraint(constraint)
+	case "composer":
+		constraint, scheme = composerVersConstraint(constraint), "npm"
+	case "golang":
+		// A go.mod requirement is a minimum under minimal version
+		// selection. Gopkg.toml and glide constraints don't start
+		// with "v" and keep their operators.
+		if strings.HasPrefix(constraint, "v") && isVersionNumber(constraint) {
+			constraint = ">=" + constraint
+		}
+	case "pub":
+		if constraint == "any" {
+			return newVersionRange(ecosystem, vers.Unbounded())
+		}
+	}
+	if constraint == "" {
+		return nil
+	}
+
+	var r *vers.Range
+	var err error
+	if scheme == "gem" {
+		r, err = gemVersRange(constraint)
+	} else {
+		r, err = vers.ParseNative(constraint, scheme)
+	}
+	if err != nil || !validVersRange(r) {
+		return nil
+	}
+	return newVersionRange(ecosystem, r)
+}
+
+// gemVersRange parses a RubyGems requirement list such as
+// "~> 7.0, >= 7.0.1". vers reads a list starting with "~>" as a single
+// pessimistic version, so each requirement is parsed on its own and the
+// ranges intersected.
+func gemVersRange(constraint string) (*vers.Range, error) {
+	var r *vers.Range
+	for _, term := range strings.Split(constraint, ",") {
+		tr, err := vers.ParseNative(strings.TrimSpace(term), "gem")
+		if err != nil {
+			return nil, err
+		}
+		if r == nil {
+			r = tr
+		} else {
+			r = r.Intersect(tr)
+		}
+	}
+	return r, nil
+}
+
+// ExactVersionRange returns the range holding only version, as recorded
+// by a lockfile. Returns nil if version isn't a version number.
+func ExactVersionRange(ecosystem, version string) *VersionRange {
+	if refEcosystems[ecosystem] || !isVersionNumber(version) {
+		return nil
+	}
+	// Built directly rather than through vers, since lockfiles can hold
+	// thousands of entries.
+	return &VersionRange{
+		VERS:      "vers:" + ecosystem + "/" + versEscaper.Replace(version),
+		Intervals: []VersionInterval{{Min: version, Max: version, MinInclusive: true, MaxInclusive: true}},
+	}
+}
+
+// IntervalVersionRange returns the range of versions from min to max,
+// both inclusive, as written by formats that list spans of versions
+// rather than requirements (go.mod's retract). Returns nil if either
+// isn't a version number or min is above max.
+func IntervalVersionRange(ecosystem, min, max string) *VersionRange {
+	if min == max {
+		return ExactVersionRange(ecosystem, min)
+	}
+	if !isVersionNumber(min) || !isVersionNumber(max) {
+		return nil
+	}
+	if vers.CompareWithScheme(min, max, ecosystem) > 0 {
+		return nil
+	}
+	return &VersionRange{
+		VERS:      "vers:" + ecosystem + "/>=" + versEscaper.Replace(min) + "|<=" + versEscaper.Replace(max),
+		Intervals: []VersionInterval{{Min: min, Max: max, MinInclusive: true, MaxInclusive: true}},
+	}
+}
+
+// versEscaper percent-encodes the characters vers reserves.
+var versEscaper = strings.NewReplacer(
+	"|", "%7C", ">", "%3E", "<", "%3C", "=", "%3D",
+	"!", "%21", "/", "%2F", "*", "%2A", " ", "%20",
+)
+
+func newVersionRange(ecosystem string, r *vers.Range) *VersionRange {
+	vr := &VersionRange{
+		VERS:       vers.ToVersString(r, ecosystem),
+		Exclusions: r.Exclusions,
+		r:          r,
+	}
+	vr.Intervals = make([]VersionInterval, len(r.Intervals))
+	for i, iv := range r.Intervals {
+		vr.Intervals[i] = VersionInterval{
+			Min:          iv.Min,
+			Max:          iv.Max,
+			MinInclusive: iv.MinInclusive,
+			MaxInclusive: iv.MaxInclusive,
+		}
+	}
+	return vr
+}
+
+// validVersRange rejects ranges built from things that aren't versions.
+// vers treats any unrecognised string as an exact version, so "latest"
+// or "${project.version}" would otherwise parse.
+func validVersRange(r *vers.Range) bool {
+	if r == nil || len(r.Intervals) == 0 && len(r.Exclusions) == 0 {
+		return false
+	}
+	for _, iv := range r.Intervals {
+		if iv.Min != "" && !isVersionNumber(iv.Min) {
+			return false
+		}
+		if iv.Max != "" && !isVersionNumber(iv.Max) {
+			return false
+		}
+	}
+	for _, v := range r.Exclusions {
+		if !isVersionNumber(v) {
+			return false
+		}
+	}
+	return true
+}
+
+// isVersionNumber reports whether s looks like a version: an optional
+// "v" followed by a digit, with no spaces or URL characters.
+func isVersionNumber(s string) bool {
+	s = strings.TrimPrefix(s, "v")
+	if s == "" || s[0] < '0' || s[0] > '9' {
+		return false
+	}
+	return !strings.ContainsAny(s, " /:@$")
+}
+
+// cargoVersConstraint rewrites a Cargo requirement in npm range syntax.
+// A bare version is a caret requirement in Cargo, and comparators are
+// separated by commas rather than spaces. Wildcards ("1.2.*") become
+// x-ranges, as does a partial exact version: "=1.2" matches any 1.2.x.
+func cargoVersConstraint(s string) string {
+	var terms []string
+	for _, term := range strings.Split(s, ",") {
+		term = strings.TrimSpace(term)
+		switch {
+		case strings.Contains(term, "*"):
+			term = strings.TrimPrefix(strings.ReplaceAll(term, "*", "x"), "=")
+		case strings.HasPrefix(term, "=") && !strings.ContainsAny(term, "-+") && strings.Count(term, ".") < 2:
+			term = strings.TrimSpace(term[1:]) + ".x"
+		case term != "" && term[0] >= '0' && term[0] <= '9':
+			term = "^" + term
+		}
+		terms = append(terms, term)
+	}
+	return strings.Join(terms, " ")
+}
+
+// pypiVersConstraint rewrites a PEP 440 specifier for vers. "==" and
+// "===" become "=", "==1.2.*" becomes an x-range, and Poetry's caret and
+// tilde requirements use Cargo semantics.
+func pypiVersConstraint(s string) (string, string) {
+	if strings.HasPrefix(s, "^") || (strings.HasPrefix(s, "~") && !strings.HasPrefix(s, "~=")) {
+		return s, "cargo"
+	}
+	if strings.HasPrefix(s, "==") && strings.HasSuffix(s, ".*") && !strings.Contains(s, ",") {
+		return strings.TrimLeft(s, "="), "npm"
+	}
+	s = strings.ReplaceAll(s, "===", "=")
+	s = strings.ReplaceAll(s, "==", "=")
+	return strings.ReplaceAll(s, " ", ""), "pypi"
+}
+
+// composerVersConstraint rewrites a Composer requirement in npm range
+// syntax. Composer's "~1.2" allows any 1.x at or above 1.2, which npm
+// spells "^1.2"; with three parts it matches npm's tilde. Stability
+// flags such as "@dev" are dropped. Branch requirements ("dev-main")
+// yield an empty string.
+func composerVersConstraint(s string) string {
+	var alternatives []string
+	for _, alt := range strings.Split(strings.ReplaceAll(s, "||", "|"), "|") {
+		var terms []string
+		for _, term := range strings.FieldsFunc(alt, func(r rune) bool { return r == ',' || r == ' ' }) {
+			if i := strings.IndexByte(term, '@'); i >= 0 {
+				term = term[:i]
+			}
+			if term == "" {
+				continue
+			}
+			if strings.HasPrefix(term, "dev-") {
+				return ""
+			}
+			if strings.HasPrefix(term, "~") && strings.Count(term, ".") == 1 {
+				term = composerTildeRange(term[1:])
+			}
+			terms = append(terms, strings.TrimPrefix(term, "v"))
+		}
+		if len(terms) == 0 {
+			terms = []string{"*"}
+		}
+		alternatives = append(alternatives, strings.Join(terms, " "))
+	}
+	return strings.Join(alternatives, " || ")
+}
+
+// composerTildeRange expands "~X.Y" to ">=X.Y <X+1", including when X
+// is 0, where npm's caret would stop at the next minor.
+func composerTildeRange(version string) string {
+	major, _, _ := strings.Cut(version, ".")
+	n, err := strconv.Atoi(major)
+	if err != nil {
+		return "~" + version
+	}
+	return ">=" + version + " <" + strconv.Itoa(n+1) + ".0.0"
+}
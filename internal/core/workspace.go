@@ -0,0 +1,61 @@
+package core
+
+import "path/filepath"
+
+// WorkspaceDefinition is what a file declares about a monorepo
+// workspace's members.
+type WorkspaceDefinition struct {
+	// Members are slash-separated paths or glob patterns, relative to
+	// the directory of the file, naming member directories. Patterns
+	// use path.Match syntax, plus "**" for any number of directories.
+	// "." makes the directory holding the file a member itself.
+	Members []string
+	// Exclude are patterns for directories Members matches that aren't
+	// members after all.
+	Exclude []string
+	// Manifests are the file names that make a directory a member, in
+	// order of preference: package.json for npm workspaces, Cargo.toml
+	// for Cargo, and so on.
+	Manifests []string
+}
+
+// WorkspaceReader reads workspace definitions. ReadWorkspace returns
+// nil and no error when the file doesn't declare a workspace, as with a
+// package.json that has no "workspaces" field.
+type WorkspaceReader interface {
+	ReadWorkspace(filename string, content []byte) (*WorkspaceDefinition, error)
+}
+
+// WorkspaceRegistration holds workspace reader metadata.
+type WorkspaceRegistration struct {
+	Ecosystem string
+	Reader    WorkspaceReader
+	Match     func(filename string) bool
+}
+
+var workspaceReaders []WorkspaceRegistration
+
+// RegisterWorkspace adds a workspace reader to the registry. Like
+// Register, it must only be called from an init function. Files that
+// declare workspaces need not be registered with Register as well; most
+// (lerna.json, go.work, settings.gradle) list no dependencies.
+func RegisterWorkspace(ecosystem string, reader WorkspaceReader, match func(string) bool) {
+	workspaceReaders = append(workspaceReaders, WorkspaceRegistration{
+		Ecosystem: ecosystem,
+		Reader:    reader,
+		Match:     match,
+	})
+}
+
+// IdentifyWorkspaceReaders returns the workspace readers matching a
+// filename.
+func IdentifyWorkspaceReaders(filename string) []WorkspaceRegistration {
+	base := filepath.Base(filename)
+	var matches []WorkspaceRegistration
+	for _, reg := range workspaceReaders {
+		if reg.Match(filename) || reg.Match(base) {
+			matches = append(matches, reg)
+		}
+	}
+	return matches
+}
@@ -1,13 +1,12 @@
 package cpan
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"github.com/git-pkgs/manifests/internal/core"
 	"regexp"
 	"strings"
-
-	"gopkg.in/yaml.v3"
 )
 
 const perlPackage = "perl"
@@ -386,9 +385,13 @@ type metaYML struct {
 }
 
 func (p *metaYMLParser) Parse(filename string, content []byte) (*core.Result, error) {
+	return p.ParseLimited(context.Background(), filename, content, core.Limits{})
+}
+
+func (p *metaYMLParser) ParseLimited(ctx context.Context, filename string, content []byte, limits core.Limits) (*core.Result, error) {
 	var meta metaYML
-	if err := yaml.Unmarshal(content, &meta); err != nil {
-		return nil, &core.ParseError{Filename: filename, Err: err}
+	if err := core.DecodeYAML(filename, content, &meta, limits); err != nil {
+		return nil, err
 	}
 
 	var deps []core.Dependency
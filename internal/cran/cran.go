@@ -2,6 +2,7 @@ package cran
 
 import (
 	"encoding/json"
+	"regexp"
 	"strings"
 
 	"github.com/git-pkgs/manifests/internal/core"
@@ -70,7 +71,133 @@ func (p *descriptionParser) Parse(filename string, content []byte) (*core.Result
 		}
 	}
 
-	return &core.Result{Name: fields["Package"], Version: fields["Version"], Dependencies: deps}, nil
+	return &core.Result{Name: fields["Package"], Version: fields["Version"], Metadata: descriptionMetadata(fields), Dependencies: deps}, nil
+}
+
+// descriptionMetadata reads package metadata from DESCRIPTION fields.
+// Authors come from Authors@R; the older Author field is free text, so
+// without Authors@R only the Maintainer is reported.
+func descriptionMetadata(fields map[string]string) core.Metadata {
+	m := core.Metadata{
+		License:     fields["License"],
+		Description: fields["Title"],
+		Authors:     parseAuthorsR(fields["Authors@R"]),
+	}
+	if m.Description == "" {
+		m.Description = fields["Description"]
+	}
+	if m.Authors == nil && fields["Maintainer"] != "" {
+		m.Authors = []string{fields["Maintainer"]}
+	}
+	urls := strings.FieldsFunc(fields["URL"], func(r rune) bool { return r == ',' || r == ' ' })
+	if len(urls) > 0 {
+		m.Homepage = urls[0]
+	}
+	for _, u := range append(urls, fields["BugReports"]) {
+		if repo := forgeRepository(u); repo != "" {
+			m.Repository = repo
+			break
+		}
+	}
+	return m
+}
+
+// forgeRepository trims a GitHub, GitLab or Codeberg URL such as a wiki
+// or issues page down to the repository, or returns "" for other URLs.
+func forgeRepository(u string) string {
+	for _, host := range []string{"github.com/", "gitlab.com/", "codeberg.org/"} {
+		i := strings.Index(u, "://"+host)
+		if i < 0 {
+			continue
+		}
+		base := u[:i+3+len(host)]
+		parts := strings.SplitN(u[len(base):], "/", 3)
+		if len(parts) < 2 || parts[0] == "" || parts[1] == "" {
+			return ""
+		}
+		return base + parts[0] + "/" + strings.TrimSuffix(parts[1], ".git")
+	}
+	return ""
+}
+
+var (
+	rPersonGiven  = regexp.MustCompile(`^\s*(?:given\s*=\s*)?(?:c\()?"([^"]*)"`)
+	rPersonFamily = regexp.MustCompile(`family\s*=\s*"([^"]*)"`)
+	rPersonEmail  = regexp.MustCompile(`email\s*=\s*"([^"]*)"`)
+	rPersonRole   = regexp.MustCompile(`"(aut|cre|ctb|cph|ths|trl|fnd)"`)
+	rQuoted       = regexp.MustCompile(`"([^"]*)"`)
+)
+
+// parseAuthorsR returns the authors and maintainers in an Authors@R
+// value such as c(person("Hadley", "Wickham", , "h@rstudio.com",
+// c("aut", "cre")), person("RStudio", role = "cph")). People whose roles
+// don't include "aut" or "cre" are left out; a person without a role
+// counts as an author, as in R.
+func parseAuthorsR(value string) []string {
+	var authors []string
+	for _, args := range rPersonArgs(value) {
+		roles := rPersonRole.FindAllStringSubmatch(args, -1)
+		isAuthor := len(roles) == 0
+		for _, r := range roles {
+			isAuthor = isAuthor || r[1] == "aut" || r[1] == "cre"
+		}
+		if !isAuthor {
+			continue
+		}
+
+		var given, family, email string
+		if named := rPersonFamily.FindStringSubmatch(args); named != nil {
+			family = named[1]
+		}
+		if named := rPersonEmail.FindStringSubmatch(args); named != nil {
+			email = named[1]
+		}
+		if g := rPersonGiven.FindStringSubmatch(args); g != nil {
+			given = g[1]
+		}
+		if family == "" && email == "" && !strings.Contains(args, "=") {
+			// Positional: person(given, family, middle, email, role)
+			positional := strings.Split(args, ",")
+			at := func(i int) string {
+				if i < len(positional) {
+					if q := rQuoted.FindStringSubmatch(positional[i]); q != nil {
+						return q[1]
+					}
+				}
+				return ""
+			}
+			family, email = at(1), at(3)
+		}
+		if name := strings.TrimSpace(given + " " + family); name != "" || email != "" {
+			authors = append(authors, core.Author(name, email))
+		}
+	}
+	return authors
+}
+
+// rPersonArgs returns the argument text of each person(...) call in
+// value, matching nested parentheses.
+func rPersonArgs(value string) []string {
+	var calls []string
+	for {
+		i := strings.Index(value, "person(")
+		if i < 0 {
+			return calls
+		}
+		value = value[i+len("person("):]
+		depth, end := 1, len(value)
+		for j := 0; j < len(value) && depth > 0; j++ {
+			switch value[j] {
+			case '(':
+				depth++
+			case ')':
+				depth--
+				end = j
+			}
+		}
+		calls = append(calls, value[:end])
+		value = value[end:]
+	}
 }
 
 // parseDescriptionFields parses DESCRIPTION file key-value pairs.
@@ -167,6 +294,7 @@ type renvPackage struct {
 	Package string `json:"Package"`
 	Version string `json:"Version"`
 	Hash    string `json:"Hash"`
+	License string `json:"License"`
 }
 
 func (p *renvLockParser) Parse(filename string, content []byte) (*core.Result, error) {
@@ -189,6 +317,7 @@ func (p *renvLockParser) Parse(filename string, content []byte) (*core.Result, e
 			Scope:     core.Runtime,
 			Integrity: integrity,
 			Direct:    false,
+			License:   pkg.License,
 		})
 	}
 
@@ -131,6 +131,27 @@ func TestRenvLock(t *testing.T) {
 	}
 }
 
+func TestRenvLockLicense(t *testing.T) {
+	// renv 1.0 records the package's DESCRIPTION fields, License included
+	content := []byte(`{
+  "R": {"Version": "4.3.2"},
+  "Packages": {
+    "cli": {"Package": "cli", "Version": "3.6.2", "Source": "Repository", "License": "MIT + file LICENSE"},
+    "rlang": {"Package": "rlang", "Version": "1.1.3", "Source": "Repository"}
+  }
+}`)
+	res, err := (&renvLockParser{}).Parse("renv.lock", content)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	want := map[string]string{"cli": "MIT + file LICENSE", "rlang": ""}
+	for _, dep := range res.Dependencies {
+		if dep.License != want[dep.Name] {
+			t.Errorf("%s license = %q, want %q", dep.Name, dep.License, want[dep.Name])
+		}
+	}
+}
+
 func TestDescription2(t *testing.T) {
 	content, err := os.ReadFile("../../testdata/cran/DESCRIPTION2")
 	if err != nil {
@@ -193,3 +214,39 @@ func TestDescription2(t *testing.T) {
 		}
 	}
 }
+
+func TestDescriptionMetadata(t *testing.T) {
+	content := []byte(`Package: acme
+Version: 1.0.0
+Title: Does Things
+Authors@R: c(
+    person(given = "Jane", family = "Doe", email = "jane@example.com", role = c("aut", "cre")),
+    person("John", "Roe", role = "ctb"))
+License: MIT + file LICENSE
+URL: https://acme.example.com, https://github.com/acme/acme/tree/main
+`)
+	res, err := (&descriptionParser{}).Parse("DESCRIPTION", content)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	m := res.Metadata
+	if m.Description != "Does Things" || m.License != "MIT + file LICENSE" {
+		t.Errorf("unexpected metadata %+v", m)
+	}
+	if m.Homepage != "https://acme.example.com" || m.Repository != "https://github.com/acme/acme" {
+		t.Errorf("unexpected urls %+v", m)
+	}
+	// Contributors aren't authors
+	if len(m.Authors) != 1 || m.Authors[0] != "Jane Doe <jane@example.com>" {
+		t.Errorf("Authors = %q", m.Authors)
+	}
+
+	// Without Authors@R, the maintainer stands in
+	res, err = (&descriptionParser{}).Parse("DESCRIPTION", []byte("Package: acme\nAuthor: Jane Doe and others\nMaintainer: Jane Doe <jane@example.com>\n"))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if len(res.Metadata.Authors) != 1 || res.Metadata.Authors[0] != "Jane Doe <jane@example.com>" {
+		t.Errorf("Authors = %q", res.Metadata.Authors)
+	}
+}
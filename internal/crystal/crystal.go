@@ -1,10 +1,9 @@
 package crystal
 
 import (
+	"context"
 	"github.com/git-pkgs/manifests/internal/core"
 	"strings"
-
-	"gopkg.in/yaml.v3"
 )
 
 func init() {
@@ -37,6 +36,10 @@ type shardYMLParser struct{}
 type shardYML struct {
 	Name                    string              `yaml:"name"`
 	Version                 string              `yaml:"version"`
+	Description             string              `yaml:"description"`
+	License                 string              `yaml:"license"`
+	Repository              string              `yaml:"repository"`
+	Authors                 []string            `yaml:"authors"`
 	Dependencies            map[string]shardDep `yaml:"dependencies"`
 	DevelopmentDependencies map[string]shardDep `yaml:"development_dependencies"`
 }
@@ -53,9 +56,13 @@ type shardDep struct {
 }
 
 func (p *shardYMLParser) Parse(filename string, content []byte) (*core.Result, error) {
+	return p.ParseLimited(context.Background(), filename, content, core.Limits{})
+}
+
+func (p *shardYMLParser) ParseLimited(ctx context.Context, filename string, content []byte, limits core.Limits) (*core.Result, error) {
 	var shard shardYML
-	if err := yaml.Unmarshal(content, &shard); err != nil {
-		return nil, &core.ParseError{Filename: filename, Err: err}
+	if err := core.DecodeYAML(filename, content, &shard, limits); err != nil {
+		return nil, err
 	}
 
 	var deps []core.Dependency
@@ -78,7 +85,13 @@ func (p *shardYMLParser) Parse(filename string, content []byte) (*core.Result, e
 		})
 	}
 
-	return &core.Result{Name: shard.Name, Version: shard.Version, Dependencies: deps}, nil
+	metadata := core.Metadata{
+		Description: strings.TrimSpace(shard.Description),
+		License:     shard.License,
+		Repository:  shard.Repository,
+		Authors:     shard.Authors,
+	}
+	return &core.Result{Name: shard.Name, Version: shard.Version, Metadata: metadata, Dependencies: deps}, nil
 }
 
 func getShardVersion(dep shardDep) string {
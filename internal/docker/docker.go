@@ -1,11 +1,10 @@
 package docker
 
 import (
+	"context"
 	"github.com/git-pkgs/manifests/internal/core"
 	"regexp"
 	"strings"
-
-	"gopkg.in/yaml.v3"
 )
 
 func init() {
@@ -27,9 +26,11 @@ var (
 
 func (p *dockerfileParser) Parse(filename string, content []byte) (*core.Result, error) {
 	var deps []core.Dependency
+	var diags []core.Diagnostic
 	lines := strings.Split(string(content), "\n")
 
-	for _, line := range lines {
+	for i, line := range lines {
+		pos, raw := core.LineSpan(i+1, line)
 		line = strings.TrimSpace(line)
 
 		// Skip comments
@@ -41,22 +42,62 @@ func (p *dockerfileParser) Parse(filename string, content []byte) (*core.Result,
 			image := match[1]
 			// Skip ARG references like ${BASE_IMAGE}
 			if strings.Contains(image, "$") {
+				diags = append(diags, core.Warning(i+1, core.CodeUnresolvedVariable, "base image %s depends on a build argument", image))
 				continue
 			}
 
 			name, version := core.ParseDockerImage(image)
 			if name != "" {
 				deps = append(deps, core.Dependency{
-					Name:    name,
-					Version: version,
-					Scope:   core.Runtime,
-					Direct:  true,
+					Name:     name,
+					Version:  version,
+					Scope:    core.Runtime,
+					Direct:   true,
+					Position: pos,
+					Raw:      raw,
 				})
 			}
 		}
 	}
 
-	return &core.Result{Dependencies: deps}, nil
+	return &core.Result{Dependencies: deps, Diagnostics: diags}, nil
+}
+
+// EditVersion rewrites the tag or digest of a FROM line's image. An
+// image without either, which the parser reports as "latest", gets one
+// appended. Tags and digests can't be swapped for one another, as the
+// tag is dropped from the reported version when a digest follows it.
+func (p *dockerfileParser) EditVersion(content []byte, decl core.Span, dep *core.Dependency, version string) (core.Edit, bool, error) {
+	m := dockerFromRegex.FindSubmatchIndex(content[decl.Start:decl.End])
+	if m == nil {
+		return core.Edit{}, false, nil
+	}
+	image := string(content[decl.Start+m[2] : decl.Start+m[3]])
+	end := decl.Start + m[3]
+	encoded, err := core.BareVersion(version)
+	if err != nil {
+		return core.Edit{}, false, err
+	}
+	digest := strings.Contains(version, ":")
+	switch {
+	case strings.HasSuffix(image, "@"+dep.Version):
+		if !digest {
+			return core.Edit{}, false, core.ErrUnencodableVersion
+		}
+	case strings.HasSuffix(image, ":"+dep.Version):
+		if digest || strings.Contains(version, "@") {
+			return core.Edit{}, false, core.ErrUnencodableVersion
+		}
+	case dep.Version == "latest":
+		sep := ":"
+		if digest {
+			sep = "@"
+		}
+		return core.Edit{Span: core.Span{Start: end, End: end}, Text: sep + encoded}, true, nil
+	default:
+		return core.Edit{}, false, nil
+	}
+	return core.Edit{Span: core.Span{Start: end - len(dep.Version), End: end}, Text: encoded}, true, nil
 }
 
 // dockerComposeParser parses docker-compose.yml files.
@@ -77,21 +118,27 @@ type dockerImageKey struct {
 }
 
 func (p *dockerComposeParser) Parse(filename string, content []byte) (*core.Result, error) {
+	return p.ParseLimited(context.Background(), filename, content, core.Limits{})
+}
+
+func (p *dockerComposeParser) ParseLimited(ctx context.Context, filename string, content []byte, limits core.Limits) (*core.Result, error) {
 	var compose dockerCompose
-	if err := yaml.Unmarshal(content, &compose); err != nil {
-		return nil, &core.ParseError{Filename: filename, Err: err}
+	if err := core.DecodeYAML(filename, content, &compose, limits); err != nil {
+		return nil, err
 	}
 
 	var deps []core.Dependency
+	var diags []core.Diagnostic
 	seen := make(map[dockerImageKey]bool)
 
-	for _, service := range compose.Services {
+	for serviceName, service := range compose.Services {
 		if service.Image == "" {
 			continue
 		}
 
-		// Skip ARG references
+		// Skip variable references
 		if strings.Contains(service.Image, "$") {
+			diags = append(diags, core.Warning(0, core.CodeUnresolvedVariable, "service %s image %s depends on an environment variable", serviceName, service.Image))
 			continue
 		}
 
@@ -115,5 +162,5 @@ func (p *dockerComposeParser) Parse(filename string, content []byte) (*core.Resu
 		})
 	}
 
-	return &core.Result{Dependencies: deps}, nil
+	return &core.Result{Dependencies: deps, Diagnostics: diags}, nil
 }
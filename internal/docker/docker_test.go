@@ -82,3 +82,21 @@ func TestDockerCompose(t *testing.T) {
 		}
 	}
 }
+
+func TestDockerfileDiagnostics(t *testing.T) {
+	content := []byte("ARG BASE=node:20\nFROM ${BASE} AS build\nFROM nginx:1.25\n")
+	res, err := (&dockerfileParser{}).Parse("Dockerfile", content)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if len(res.Dependencies) != 1 || res.Dependencies[0].Name != "nginx" {
+		t.Fatalf("expected only nginx, got %+v", res.Dependencies)
+	}
+	if len(res.Diagnostics) != 1 {
+		t.Fatalf("expected 1 diagnostic, got %+v", res.Diagnostics)
+	}
+	d := res.Diagnostics[0]
+	if d.Severity != core.SeverityWarning || d.Code != core.CodeUnresolvedVariable || d.Line != 2 {
+		t.Errorf("unexpected diagnostic %+v", d)
+	}
+}
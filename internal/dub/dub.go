@@ -18,6 +18,10 @@ type dubJSONParser struct{}
 type dubJSON struct {
 	Name         string         `json:"name"`
 	Version      string         `json:"version"`
+	Description  string         `json:"description"`
+	License      string         `json:"license"`
+	Homepage     string         `json:"homepage"`
+	Authors      []string       `json:"authors"`
 	Dependencies map[string]any `json:"dependencies"`
 }
 
@@ -53,7 +57,14 @@ func (p *dubJSONParser) Parse(filename string, content []byte) (*core.Result, er
 		})
 	}
 
-	return &core.Result{Name: dub.Name, Version: dub.Version, Dependencies: deps}, nil
+	metadata := core.Metadata{
+		Description: dub.Description,
+		License:     dub.License,
+		Homepage:    dub.Homepage,
+		Authors:     dub.Authors,
+	}
+
+	return &core.Result{Name: dub.Name, Version: dub.Version, Metadata: metadata, Dependencies: deps}, nil
 }
 
 // dubSDLParser parses dub.sdl files.
@@ -16,6 +16,8 @@ type elmJSONParser struct{}
 type elmJSON struct {
 	Name             string          `json:"name"`
 	Version          string          `json:"version"`
+	Summary          string          `json:"summary"`
+	License          string          `json:"license"`
 	Dependencies     elmDependencies `json:"dependencies"`
 	TestDependencies elmDependencies `json:"test-dependencies"`
 }
@@ -73,7 +75,8 @@ func (p *elmJSONParser) Parse(filename string, content []byte) (*core.Result, er
 		})
 	}
 
-	return &core.Result{Name: elm.Name, Version: elm.Version, Dependencies: deps}, nil
+	metadata := core.Metadata{Description: elm.Summary, License: elm.License}
+	return &core.Result{Name: elm.Name, Version: elm.Version, Metadata: metadata, Dependencies: deps}, nil
 }
 
 // elmPackageJSONParser parses elm-package.json files (Elm 0.18 and earlier).
@@ -2,6 +2,7 @@ package gem
 
 import (
 	"os"
+	"slices"
 	"testing"
 
 	"github.com/git-pkgs/manifests/internal/core"
@@ -165,7 +166,7 @@ func TestGemspec(t *testing.T) {
 		"orm_adapter": {"~> 0.1", core.Development},
 		"bcrypt":      {"~> 3.0", core.Runtime},
 		"thread_safe": {"~> 0.1", core.Runtime},
-		"railties":    {">= 3.2.6", core.Runtime}, // parser only captures first constraint
+		"railties":    {">= 3.2.6, < 5", core.Runtime}, // parser only captures first constraint
 		"responders":  {"", core.Runtime},
 	})
 }
@@ -307,6 +308,57 @@ func TestMastodonGemfileLock(t *testing.T) {
 	}
 }
 
+func TestGemfileLockGraph(t *testing.T) {
+	content, err := os.ReadFile("../../testdata/gem/mastodon/Gemfile.lock")
+	if err != nil {
+		t.Fatalf("failed to read fixture: %v", err)
+	}
+
+	parser := &gemfileLockParser{}
+	res, err := parser.Parse("Gemfile.lock", content)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	if res.Graph == nil {
+		t.Fatal("expected a graph")
+	}
+
+	railties := core.Node{Name: "railties", Version: "8.0.3"}
+	children := make(map[core.Node]bool)
+	for _, c := range res.Graph.Children(railties) {
+		children[c] = true
+	}
+	for _, want := range []core.Node{
+		{Name: "actionpack", Version: "8.0.3"},
+		{Name: "thor", Version: "1.4.0"},
+		{Name: "zeitwerk", Version: "2.7.3"},
+	} {
+		if !children[want] {
+			t.Errorf("expected railties -> %s %s edge", want.Name, want.Version)
+		}
+	}
+
+	roots := make(map[core.Node]bool)
+	for _, r := range res.Graph.Roots {
+		roots[r] = true
+	}
+	if !roots[core.Node{Name: "rails", Version: "8.0.3"}] {
+		t.Error("expected rails to be a root")
+	}
+
+	path := res.Graph.Path("zeitwerk", "")
+	if len(path) < 2 {
+		t.Fatalf("Path(zeitwerk) = %v, want a chain from a root", path)
+	}
+	if !roots[path[0]] {
+		t.Errorf("Path starts at %v, which is not a root", path[0])
+	}
+	if last := path[len(path)-1]; last.Name != "zeitwerk" {
+		t.Errorf("Path ends at %v, want zeitwerk", last)
+	}
+}
+
 func TestGemfileLockWithPlatforms(t *testing.T) {
 	content, err := os.ReadFile("../../testdata/gem/GemfileWithPlatforms.lock")
 	if err != nil {
@@ -351,3 +403,67 @@ func TestGemfileLockWithPlatforms(t *testing.T) {
 		}
 	}
 }
+
+func TestGemfileLockDiagnostics(t *testing.T) {
+	content := []byte(`GEM
+  remote: https://rubygems.org/
+  specs:
+    rack (3.0.8)
+    rake
+      minitest (~> 5.0)
+
+PLATFORMS
+  ruby
+
+DEPENDENCIES
+  rack
+
+RUBY VERSION
+   ruby 3.2.2p53
+
+MYSTERY
+  something
+`)
+	res, err := (&gemfileLockParser{}).Parse("Gemfile.lock", content)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if len(res.Dependencies) != 1 || res.Dependencies[0].Name != "rack" {
+		t.Errorf("expected only rack, got %+v", res.Dependencies)
+	}
+	if len(res.Diagnostics) != 2 {
+		t.Fatalf("expected 2 diagnostics, got %+v", res.Diagnostics)
+	}
+	if d := res.Diagnostics[0]; d.Line != 5 || d.Code != core.CodeUnrecognisedLine {
+		t.Errorf("unexpected diagnostic %+v", d)
+	}
+	if d := res.Diagnostics[1]; d.Line != 17 || d.Code != core.CodeUnrecognisedLine {
+		t.Errorf("unexpected diagnostic %+v", d)
+	}
+}
+
+func TestGemfileRuby(t *testing.T) {
+	content := []byte(`source "https://rubygems.org"
+ruby ">= 3.1", "< 4", engine: "jruby", engine_version: "9.4"
+gem "rails", "~> 7.1"
+`)
+	res, err := (&gemfileParser{}).Parse("Gemfile", content)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	want := []core.Requirement{{Name: "ruby", Version: ">= 3.1, < 4", Source: "ruby"}}
+	if !slices.Equal(res.Runtimes, want) {
+		t.Errorf("Runtimes = %+v, want %+v", res.Runtimes, want)
+	}
+	if len(res.Dependencies) != 1 {
+		t.Errorf("expected 1 dependency, got %+v", res.Dependencies)
+	}
+
+	res, err = (&gemfileParser{}).Parse("Gemfile", []byte("ruby file: \".ruby-version\"\n"))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if len(res.Runtimes) != 0 {
+		t.Errorf("expected no runtimes from a version file, got %+v", res.Runtimes)
+	}
+}
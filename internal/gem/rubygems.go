@@ -1,6 +1,7 @@
 package gem
 
 import (
+	"bytes"
 	"github.com/git-pkgs/manifests/internal/core"
 	"strings"
 )
@@ -66,7 +67,7 @@ func init() {
 type gemfileParser struct{}
 
 // extractGemDecl extracts gem name and version from a gem declaration line
-// Handles: gem "name" or gem "name", "version"
+// Handles: gem "name", gem "name", "version" and gem "name", "~> 7.0", ">= 7.0.1"
 func extractGemDecl(line string) (name, version string, ok bool) {
 	trimmed := strings.TrimSpace(line)
 	if !strings.HasPrefix(trimmed, "gem ") && !strings.HasPrefix(trimmed, "gem\t") {
@@ -86,23 +87,71 @@ func extractGemDecl(line string) (name, version string, ok bool) {
 		return "", "", false
 	}
 	name = trimmed[start+1 : start+1+end]
+	return name, gemRequirements(trimmed[start+1+end+1:]), true
+}
 
-	// Look for version
-	rest := trimmed[start+1+end+1:]
-	if idx := strings.IndexByte(rest, ','); idx >= 0 {
-		rest = rest[idx+1:]
-		// Find version quote
-		vstart := strings.IndexAny(rest, "'\"")
-		if vstart >= 0 {
-			vquote := rest[vstart]
-			vend := strings.IndexByte(rest[vstart+1:], vquote)
-			if vend >= 0 {
-				version = rest[vstart+1 : vstart+1+vend]
-			}
+// gemRequirements returns the version requirements given as string
+// arguments after a gem's name, joined with ", " as Bundler combines
+// them: `, "~> 7.0", ">= 7.0.1", require: false` is "~> 7.0, >= 7.0.1".
+// Options such as require: end the list.
+func gemRequirements(rest string) string {
+	var reqs []string
+	for {
+		rest = strings.TrimLeft(rest, " \t")
+		if !strings.HasPrefix(rest, ",") {
+			break
+		}
+		rest = strings.TrimLeft(rest[1:], " \t")
+		if rest == "" || (rest[0] != '"' && rest[0] != '\'') {
+			break
+		}
+		end := strings.IndexByte(rest[1:], rest[0])
+		if end < 0 {
+			break
 		}
+		reqs = append(reqs, rest[1:1+end])
+		rest = rest[1+end+1:]
 	}
+	return strings.Join(reqs, ", ")
+}
 
-	return name, version, true
+// extractRubyDecl extracts the version requirement from a ruby
+// declaration: ruby "3.2.2" or ruby ">= 3.1", "< 4", engine: "jruby".
+// Declarations that only name a file (ruby file: ".ruby-version") have
+// no version.
+func extractRubyDecl(line string) (version string, ok bool) {
+	trimmed := strings.TrimSpace(line)
+	args, ok := strings.CutPrefix(trimmed, "ruby ")
+	if !ok {
+		return "", false
+	}
+	var versions []string
+	for _, arg := range strings.Split(args, ",") {
+		arg = strings.TrimSpace(arg)
+		if len(arg) < 2 || (arg[0] != '"' && arg[0] != '\'') || arg[len(arg)-1] != arg[0] {
+			// Options such as engine: come after the versions
+			break
+		}
+		versions = append(versions, arg[1:len(arg)-1])
+	}
+	return strings.Join(versions, ", "), true
+}
+
+// extractEvalGemfile extracts the file an eval_gemfile line loads, as
+// written: eval_gemfile "Gemfile.local" or eval_gemfile(File.join(...)).
+func extractEvalGemfile(line string) (path string, ok bool) {
+	args, ok := strings.CutPrefix(strings.TrimSpace(line), "eval_gemfile")
+	if !ok || (args != "" && args[0] != ' ' && args[0] != '(') {
+		return "", false
+	}
+	args = strings.TrimSpace(args)
+	if strings.HasPrefix(args, "(") && strings.HasSuffix(args, ")") {
+		args = strings.TrimSpace(args[1 : len(args)-1])
+	}
+	if len(args) >= 2 && (args[0] == '"' || args[0] == '\'') && args[len(args)-1] == args[0] {
+		args = args[1 : len(args)-1]
+	}
+	return args, true
 }
 
 // extractGemfileGroup extracts scope from group declaration
@@ -129,12 +178,28 @@ func (p *gemfileParser) Parse(filename string, content []byte) (*core.Result, er
 	text := string(content)
 	deps := make([]core.Dependency, 0, core.EstimateDeps(len(content)))
 
+	var runtimes []core.Requirement
+	var diags []core.Diagnostic
 	currentScope := core.Runtime
 	groupDepth := 0
+	lineNo := 0
 
 	core.ForEachLine(text, func(line string) bool {
+		lineNo++
 		trimmed := strings.TrimSpace(line)
 
+		if version, ok := extractRubyDecl(line); ok {
+			if version != "" {
+				runtimes = append(runtimes, core.Requirement{Name: "ruby", Version: version, Source: "ruby"})
+			}
+			return true
+		}
+
+		if path, ok := extractEvalGemfile(line); ok {
+			diags = append(diags, core.Warning(lineNo, core.CodeUnfollowedInclude, "gems from %s are not read", path))
+			return true
+		}
+
 		// Track group blocks
 		if scope, ok := extractGemfileGroup(line); ok {
 			groupDepth++
@@ -152,17 +217,68 @@ func (p *gemfileParser) Parse(filename string, content []byte) (*core.Result, er
 
 		// Parse gem declarations
 		if name, version, ok := extractGemDecl(line); ok {
+			pos, raw := core.LineSpan(lineNo, line)
 			deps = append(deps, core.Dependency{
-				Name:    name,
-				Version: version,
-				Scope:   currentScope,
-				Direct:  true,
+				Name:     name,
+				Version:  version,
+				Scope:    currentScope,
+				Direct:   true,
+				Position: pos,
+				Raw:      raw,
 			})
 		}
 		return true
 	})
 
-	return &core.Result{Dependencies: deps}, nil
+	return &core.Result{Dependencies: deps, Runtimes: runtimes, Diagnostics: diags}, nil
+}
+
+// EditVersion rewrites the version requirements that follow a gem's
+// name, which Parse reports together as the dependency's version.
+// Several requirements can be given separated by commas, as in
+// ">= 7.0, < 8"; they replace all of the existing ones, keeping the
+// quote style of the first. Options such as require: or git: are left
+// alone, and a gem with none has no version to change.
+func (p *gemfileParser) EditVersion(content []byte, decl core.Span, dep *core.Dependency, version string) (core.Edit, bool, error) {
+	start := bytes.IndexAny(content[decl.Start:decl.End], `'"`)
+	if start < 0 {
+		return core.Edit{}, false, nil
+	}
+	name, _, ok := core.StringLiteralAt(content, decl.Start+start)
+	if !ok {
+		return core.Edit{}, false, nil
+	}
+	var versions []core.Span
+	var quote byte
+	for i := name.End + 1; ; {
+		i = core.SkipSpaces(content, i)
+		if i >= decl.End || content[i] != ',' {
+			break
+		}
+		span, q, ok := core.StringLiteralAt(content, core.SkipSpaces(content, i+1))
+		if !ok {
+			break
+		}
+		if len(versions) == 0 {
+			quote = q
+		}
+		versions = append(versions, span)
+		i = span.End + 1
+	}
+	if len(versions) == 0 {
+		return core.Edit{}, false, nil
+	}
+
+	var quoted []string
+	for _, v := range strings.Split(version, ",") {
+		encoded, err := core.QuotedVersion(strings.TrimSpace(v), quote)
+		if err != nil {
+			return core.Edit{}, false, err
+		}
+		quoted = append(quoted, string(quote)+encoded+string(quote))
+	}
+	span := core.Span{Start: versions[0].Start - 1, End: versions[len(versions)-1].End + 1}
+	return core.Edit{Span: span, Text: strings.Join(quoted, ", ")}, true, nil
 }
 
 // gemfileLockParser parses Gemfile.lock files.
@@ -237,12 +353,16 @@ func detectSection(trimmed string) (section string, ok bool) {
 		return "checksums", true
 	case "BUNDLED WITH":
 		return "bundled", true
+	case "RUBY VERSION":
+		return "ruby", true
+	case "PLUGIN SOURCE":
+		return sectionSource, true
 	}
 	return "", false
 }
 
 // collectSpec adds a gem from the specs section if not already seen.
-func collectSpec(line string, remote string, seen map[gemDepKey]bool, deps *[]core.Dependency) {
+func collectSpec(line string, lineNo int, remote string, seen map[gemDepKey]bool, deps *[]core.Dependency) {
 	name, version, ok := extractGemSpec(line)
 	if !ok {
 		return
@@ -252,15 +372,64 @@ func collectSpec(line string, remote string, seen map[gemDepKey]bool, deps *[]co
 		return
 	}
 	seen[key] = true
+	pos, raw := core.LineSpan(lineNo, line)
 	*deps = append(*deps, core.Dependency{
 		Name:        name,
 		Version:     version,
 		Scope:       core.Runtime,
 		Direct:      false,
 		RegistryURL: remote,
+		Position:    pos,
+		Raw:         raw,
 	})
 }
 
+// extractGemSpecDependency extracts the name from a "      name (>= 1.0)"
+// line nested under a spec.
+func extractGemSpecDependency(line string) (string, bool) {
+	const depIndent = 6
+	if len(line) <= depIndent || strings.TrimLeft(line[:depIndent], " ") != "" || line[depIndent] == ' ' {
+		return "", false
+	}
+	name := line[depIndent:]
+	if idx := strings.IndexByte(name, ' '); idx > 0 {
+		name = name[:idx]
+	}
+	return strings.TrimSpace(name), true
+}
+
+// gemSpecEdge is a spec's dependency, named but not yet resolved to a version.
+type gemSpecEdge struct {
+	from core.Node
+	to   string
+}
+
+// buildGemGraph resolves spec dependencies by name against the locked
+// specs. Gems from the DEPENDENCIES section become the roots.
+func buildGemGraph(deps []core.Dependency, edges []gemSpecEdge) *core.Graph {
+	versions := make(map[string]string, len(deps))
+	graph := core.NewGraphBuilder()
+	for _, d := range deps {
+		if _, ok := versions[d.Name]; !ok {
+			versions[d.Name] = d.Version
+		}
+		node := core.Node{Name: d.Name, Version: d.Version}
+		if d.Direct {
+			graph.AddRoot(node)
+		} else {
+			graph.AddNode(node)
+		}
+	}
+	for _, e := range edges {
+		version, ok := versions[e.to]
+		if !ok {
+			continue
+		}
+		graph.AddEdge(e.from, core.Node{Name: e.to, Version: version})
+	}
+	return graph.Graph()
+}
+
 // collectDirectDep records a dependency name from the DEPENDENCIES section.
 func collectDirectDep(trimmed string, directDeps map[string]bool) {
 	name := trimmed
@@ -289,8 +458,13 @@ func (p *gemfileLockParser) Parse(filename string, content []byte) (*core.Result
 
 	section := ""
 	currentRemote := ""
+	var currentSpec core.Node
+	var edges []gemSpecEdge
+	var diags []core.Diagnostic
+	lineNo := 0
 
 	core.ForEachLine(text, func(line string) bool {
+		lineNo++
 		trimmed := strings.TrimSpace(line)
 
 		if s, ok := detectSection(trimmed); ok {
@@ -300,6 +474,11 @@ func (p *gemfileLockParser) Parse(filename string, content []byte) (*core.Result
 			}
 			return true
 		}
+		if trimmed != "" && trimmed == line {
+			diags = append(diags, core.Warning(lineNo, core.CodeUnrecognisedLine, "unknown section %s", trimmed))
+			section = ""
+			return true
+		}
 
 		// In source sections, look for remote: line
 		if section == sectionSource {
@@ -314,7 +493,14 @@ func (p *gemfileLockParser) Parse(filename string, content []byte) (*core.Result
 		}
 
 		if section == "specs" {
-			collectSpec(line, currentRemote, seen, &deps)
+			if name, version, ok := extractGemSpec(line); ok {
+				currentSpec = core.Node{Name: name, Version: version}
+			} else if name, ok := extractGemSpecDependency(line); ok && currentSpec.Name != "" {
+				edges = append(edges, gemSpecEdge{from: currentSpec, to: name})
+			} else if trimmed != "" {
+				diags = append(diags, core.Warning(lineNo, core.CodeUnrecognisedLine, "unrecognised spec %q", trimmed))
+			}
+			collectSpec(line, lineNo, currentRemote, seen, &deps)
 		}
 
 		if section == "dependencies" && trimmed != "" {
@@ -331,7 +517,7 @@ func (p *gemfileLockParser) Parse(filename string, content []byte) (*core.Result
 
 	applyDirectAndChecksums(deps, directDeps, checksums)
 
-	return &core.Result{Dependencies: deps}, nil
+	return &core.Result{Dependencies: deps, Graph: buildGemGraph(deps, edges), Diagnostics: diags}, nil
 }
 
 // gemspecParser parses .gemspec files.
@@ -399,54 +585,139 @@ func extractGemspecDep(line string) (name, version string, isDev bool, ok bool)
 		return "", "", false, false
 	}
 	name = rest[start+1 : start+1+end]
-
-	// Look for version
-	rest = rest[start+1+end+1:]
-	if idx := strings.IndexByte(rest, ','); idx >= 0 {
-		rest = rest[idx+1:]
-		vstart := strings.IndexAny(rest, "'\"")
-		if vstart >= 0 {
-			vquote := rest[vstart]
-			vend := strings.IndexByte(rest[vstart+1:], vquote)
-			if vend >= 0 {
-				version = rest[vstart+1 : vstart+1+vend]
-			}
-		}
-	}
-
-	return name, version, isDev, true
+	return name, gemRequirements(rest[start+1+end+1:]), isDev, true
 }
 
 func (p *gemspecParser) Parse(filename string, content []byte) (*core.Result, error) {
 	text := string(content)
 	deps := make([]core.Dependency, 0, core.EstimateDeps(len(content)))
 
-	var selfName, selfVersion string
-	core.ForEachLine(text, func(line string) bool {
-		if selfName == "" {
-			if v, ok := extractGemspecAttr(line, "name"); ok {
-				selfName = v
+	var selfName, selfVersion, description string
+	var metadata core.Metadata
+	var licenses []string
+	// setString records the first literal assigned to attr
+	setString := func(dst *string, line, attr string) {
+		if *dst == "" {
+			if v, ok := extractGemspecAttr(line, attr); ok {
+				*dst = v
 			}
 		}
-		if selfVersion == "" {
-			if v, ok := extractGemspecAttr(line, "version"); ok {
-				selfVersion = v
-			}
+	}
+	lineNo := 0
+	core.ForEachLine(text, func(line string) bool {
+		lineNo++
+		setString(&selfName, line, "name")
+		setString(&selfVersion, line, "version")
+		setString(&metadata.Description, line, "summary")
+		setString(&description, line, "description")
+		setString(&metadata.Homepage, line, "homepage")
+		if v, ok := extractGemspecAttr(line, "license"); ok {
+			licenses = append(licenses, v)
+		}
+		if v, ok := extractGemspecList(line, "licenses"); ok {
+			licenses = append(licenses, v...)
+		}
+		if v, ok := extractGemspecList(line, "authors"); ok && metadata.Authors == nil {
+			metadata.Authors = v
+		} else if v, ok := extractGemspecAttr(line, "author"); ok && metadata.Authors == nil {
+			metadata.Authors = []string{v}
+		}
+		if metadata.Repository == "" {
+			metadata.Repository, _ = extractGemspecMetadata(line, "source_code_uri")
 		}
 		if name, version, isDev, ok := extractGemspecDep(line); ok {
 			scope := core.Runtime
 			if isDev {
 				scope = core.Development
 			}
+			pos, raw := core.LineSpan(lineNo, line)
 			deps = append(deps, core.Dependency{
-				Name:    name,
-				Version: version,
-				Scope:   scope,
-				Direct:  true,
+				Name:     name,
+				Version:  version,
+				Scope:    scope,
+				Direct:   true,
+				Position: pos,
+				Raw:      raw,
 			})
 		}
 		return true
 	})
 
-	return &core.Result{Name: selfName, Version: selfVersion, Dependencies: deps}, nil
+	if metadata.Description == "" {
+		metadata.Description = description
+	}
+	// RubyGems doesn't say how several licenses combine
+	metadata.License = core.JoinLicenses("AND", licenses...)
+
+	return &core.Result{Name: selfName, Version: selfVersion, Metadata: metadata, Dependencies: deps}, nil
+}
+
+// extractGemspecList extracts the string literals from lines like
+// `s.authors = ["A", "B"]` or `s.licenses = %w[MIT Ruby]`. Only lists
+// that open and close on the same line are read.
+func extractGemspecList(line, attr string) ([]string, bool) {
+	idx := strings.Index(line, "."+attr)
+	if idx < 0 {
+		return nil, false
+	}
+	rest := strings.TrimSpace(line[idx+len(attr)+1:])
+	if !strings.HasPrefix(rest, "=") {
+		return nil, false
+	}
+	rest = strings.TrimSpace(rest[1:])
+	if words, ok := strings.CutPrefix(rest, "%w"); ok && len(words) > 0 {
+		closing := map[byte]byte{'[': ']', '(': ')', '{': '}'}[words[0]]
+		end := strings.IndexByte(words, closing)
+		if closing == 0 || end < 0 {
+			return nil, false
+		}
+		return strings.Fields(words[1:end]), true
+	}
+	if !strings.HasPrefix(rest, "[") {
+		return nil, false
+	}
+	end := strings.IndexByte(rest, ']')
+	if end < 0 {
+		return nil, false
+	}
+	var values []string
+	for _, item := range strings.Split(rest[1:end], ",") {
+		item = strings.TrimSpace(item)
+		if len(item) >= 2 && (item[0] == '"' || item[0] == '\'') && item[len(item)-1] == item[0] {
+			values = append(values, item[1:len(item)-1])
+		}
+	}
+	return values, true
+}
+
+// extractGemspecMetadata extracts a metadata entry written either as
+// `s.metadata["key"] = "value"` or as `"key" => "value"` inside a
+// metadata hash.
+func extractGemspecMetadata(line, key string) (string, bool) {
+	idx := strings.Index(line, `"`+key+`"`)
+	if idx < 0 {
+		idx = strings.Index(line, "'"+key+"'")
+	}
+	if idx < 0 {
+		return "", false
+	}
+	rest := strings.TrimSpace(line[idx+len(key)+2:])
+	rest = strings.TrimPrefix(rest, "]")
+	rest = strings.TrimSpace(rest)
+	if r, ok := strings.CutPrefix(rest, "=>"); ok {
+		rest = r
+	} else if r, ok := strings.CutPrefix(rest, "="); ok {
+		rest = r
+	} else {
+		return "", false
+	}
+	rest = strings.TrimSpace(rest)
+	if len(rest) == 0 || (rest[0] != '"' && rest[0] != '\'') {
+		return "", false
+	}
+	end := strings.IndexByte(rest[1:], rest[0])
+	if end < 0 {
+		return "", false
+	}
+	return rest[1 : end+1], true
 }
@@ -1,11 +1,10 @@
 package github_actions
 
 import (
+	"context"
 	"github.com/git-pkgs/manifests/internal/core"
 	"path/filepath"
 	"strings"
-
-	"gopkg.in/yaml.v3"
 )
 
 func init() {
@@ -53,9 +52,13 @@ type githubStep struct {
 }
 
 func (p *githubWorkflowParser) Parse(filename string, content []byte) (*core.Result, error) {
+	return p.ParseLimited(context.Background(), filename, content, core.Limits{})
+}
+
+func (p *githubWorkflowParser) ParseLimited(ctx context.Context, filename string, content []byte, limits core.Limits) (*core.Result, error) {
 	var workflow githubWorkflow
-	if err := yaml.Unmarshal(content, &workflow); err != nil {
-		return nil, &core.ParseError{Filename: filename, Err: err}
+	if err := core.DecodeYAML(filename, content, &workflow, limits); err != nil {
+		return nil, err
 	}
 
 	var deps []core.Dependency
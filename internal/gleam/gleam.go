@@ -13,8 +13,16 @@ func init() {
 type gleamTomlParser struct{}
 
 type gleamToml struct {
-	Name            string            `toml:"name"`
-	Version         string            `toml:"version"`
+	Name        string   `toml:"name"`
+	Version     string   `toml:"version"`
+	Description string   `toml:"description"`
+	Licences    []string `toml:"licences"`
+	Repository  struct {
+		Type string `toml:"type"`
+		User string `toml:"user"`
+		Repo string `toml:"repo"`
+		URL  string `toml:"url"`
+	} `toml:"repository"`
 	Dependencies    map[string]string `toml:"dependencies"`
 	DevDependencies map[string]string `toml:"dev-dependencies"`
 }
@@ -45,5 +53,25 @@ func (p *gleamTomlParser) Parse(filename string, content []byte) (*core.Result,
 		})
 	}
 
-	return &core.Result{Name: gleam.Name, Version: gleam.Version, Dependencies: deps}, nil
+	metadata := core.Metadata{
+		Description: gleam.Description,
+		// As on Hex, several licences all apply
+		License:    core.JoinLicenses("AND", gleam.Licences...),
+		Repository: gleam.Repository.URL,
+	}
+	if host, ok := gleamRepositoryHosts[gleam.Repository.Type]; ok && gleam.Repository.User != "" {
+		metadata.Repository = host + gleam.Repository.User + "/" + gleam.Repository.Repo
+	}
+
+	return &core.Result{Name: gleam.Name, Version: gleam.Version, Metadata: metadata, Dependencies: deps}, nil
+}
+
+// gleamRepositoryHosts maps [repository] types that name a user and repo
+// on a public forge to the forge's URL prefix.
+var gleamRepositoryHosts = map[string]string{
+	"github":    "https://github.com/",
+	"gitlab":    "https://gitlab.com/",
+	"bitbucket": "https://bitbucket.org/",
+	"codeberg":  "https://codeberg.org/",
+	"sourcehut": "https://git.sr.ht/~",
 }
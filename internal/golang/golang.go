@@ -3,6 +3,8 @@ package golang
 import (
 	"github.com/git-pkgs/manifests/internal/core"
 	"regexp"
+	"slices"
+	"strconv"
 	"strings"
 )
 
@@ -37,7 +39,7 @@ var (
 func (p *goModParser) Parse(filename string, content []byte) (*core.Result, error) {
 	lines := strings.Split(string(content), "\n")
 	tools := collectToolPaths(lines)
-	deps := collectRequireDeps(lines, tools)
+	deps, diags := collectRequireDeps(lines, tools)
 
 	var modulePath string
 	for _, line := range lines {
@@ -48,7 +50,121 @@ func (p *goModParser) Parse(filename string, content []byte) (*core.Result, erro
 		}
 	}
 
-	return &core.Result{Name: modulePath, Dependencies: deps}, nil
+	res := &core.Result{Name: modulePath, Dependencies: deps, Diagnostics: diags}
+	collectGoModDirectives(res, content)
+	return res, nil
+}
+
+// goRequireVersion matches the module path and version of a require
+// line, with or without the require keyword of a single-line directive.
+var goRequireVersion = regexp.MustCompile(`^(?:require\s+)?(\S+)\s+(\S+)`)
+
+// EditVersion rewrites the version of a require line. A replace
+// directive that applies to the module is left alone, so a replaced
+// dependency keeps building from its replacement.
+func (p *goModParser) EditVersion(content []byte, decl core.Span, dep *core.Dependency, version string) (core.Edit, bool, error) {
+	m := goRequireVersion.FindSubmatchIndex(content[decl.Start:decl.End])
+	if m == nil {
+		return core.Edit{}, false, nil
+	}
+	encoded, err := core.BareVersion(version)
+	if err != nil {
+		return core.Edit{}, false, err
+	}
+	return core.Edit{Span: core.Span{Start: decl.Start + m[4], End: decl.Start + m[5]}, Text: encoded}, true, nil
+}
+
+// collectGoModDirectives reads the replace, exclude, retract, go and
+// toolchain directives of go.mod into res, then applies the
+// replacements to its dependencies.
+func collectGoModDirectives(res *core.Result, content []byte) {
+	for _, d := range goDirectives(content) {
+		switch d.verb {
+		case "replace":
+			r, ok := goReplacement(d)
+			if !ok {
+				res.Diagnostics = append(res.Diagnostics, d.malformed())
+				continue
+			}
+			res.Replacements = append(res.Replacements, r)
+		case "exclude":
+			if len(d.args) != 2 {
+				res.Diagnostics = append(res.Diagnostics, d.malformed())
+				continue
+			}
+			pos, raw := core.LineSpan(d.line, d.raw)
+			res.Excludes = append(res.Excludes, core.Exclusion{Name: d.args[0], Version: d.args[1], Position: pos, Raw: raw})
+		case "retract":
+			r, ok := goRetraction(d)
+			if !ok {
+				res.Diagnostics = append(res.Diagnostics, d.malformed())
+				continue
+			}
+			res.Retractions = append(res.Retractions, r)
+		case "go", "toolchain":
+			r, ok := goRuntime(d)
+			if !ok {
+				res.Diagnostics = append(res.Diagnostics, d.malformed())
+				continue
+			}
+			res.Runtimes = append(res.Runtimes, r)
+		}
+	}
+
+	for i := range res.Dependencies {
+		dep := &res.Dependencies[i]
+		r := goReplacementFor(res.Replacements, dep.Name, dep.Version)
+		if r == nil {
+			continue
+		}
+		dep.Replacement = r
+		// A directory stands in for the module without changing its path
+		// or required version, so only Replacement records it
+		if r.NewVersion != "" {
+			dep.Name, dep.Version = r.NewName, r.NewVersion
+		}
+	}
+}
+
+// goReplacementFor returns the replacement that applies to a module
+// version. As in the go command, a replace naming the version wins over
+// one for every version.
+func goReplacementFor(replacements []core.Replacement, name, version string) *core.Replacement {
+	var found *core.Replacement
+	for i := range replacements {
+		r := &replacements[i]
+		if r.Name != name {
+			continue
+		}
+		if r.Version == version {
+			return r
+		}
+		if r.Version == "" {
+			found = r
+		}
+	}
+	return found
+}
+
+// goRetraction reads a retract directive: a version, or an interval
+// written "[low, high]".
+func goRetraction(d goDirective) (core.Retraction, bool) {
+	spec := strings.Join(d.args, "")
+	low, high := spec, spec
+	if inner, ok := strings.CutPrefix(spec, "["); ok {
+		if inner, ok = strings.CutSuffix(inner, "]"); !ok {
+			return core.Retraction{}, false
+		}
+		if low, high, ok = strings.Cut(inner, ","); !ok {
+			return core.Retraction{}, false
+		}
+	}
+	vr := core.IntervalVersionRange("golang", low, high)
+	if vr == nil {
+		return core.Retraction{}, false
+	}
+	pos, raw := core.LineSpan(d.line, d.raw)
+	return core.Retraction{Range: vr, Rationale: d.comment, Position: pos, Raw: raw}, true
 }
 
 // collectToolPaths scans go.mod lines for tool directives (both single-line and block form)
@@ -92,12 +208,14 @@ func collectToolPaths(lines []string) map[string]bool {
 }
 
 // collectRequireDeps scans go.mod lines for require directives (both single-line and block form)
-// and returns dependencies, marking tool-related modules as development scope.
-func collectRequireDeps(lines []string, tools map[string]bool) []core.Dependency {
+// and returns dependencies, marking tool-related modules as development scope, along with
+// diagnostics for require lines it couldn't read.
+func collectRequireDeps(lines []string, tools map[string]bool) ([]core.Dependency, []core.Diagnostic) {
 	var deps []core.Dependency
+	var diags []core.Diagnostic
 	inRequireBlock := false
 
-	for _, line := range lines {
+	for i, line := range lines {
 		trimmed := strings.TrimSpace(line)
 
 		if trimmed == "" || strings.HasPrefix(trimmed, "//") {
@@ -116,34 +234,41 @@ func collectRequireDeps(lines []string, tools map[string]bool) []core.Dependency
 
 		if strings.HasPrefix(trimmed, "require ") && !strings.Contains(trimmed, "(") {
 			if match := singleRequireRegex.FindStringSubmatch(trimmed); match != nil {
-				deps = append(deps, newRequireDep(match[1], match[2], line, tools))
+				deps = append(deps, newRequireDep(match[1], match[2], i+1, line, tools))
+			} else {
+				diags = append(diags, core.Warning(i+1, core.CodeUnrecognisedLine, "malformed require %q", trimmed))
 			}
 			continue
 		}
 
 		if inRequireBlock {
 			if match := requireEntryRegex.FindStringSubmatch(trimmed); match != nil {
-				deps = append(deps, newRequireDep(match[1], match[2], line, tools))
+				deps = append(deps, newRequireDep(match[1], match[2], i+1, line, tools))
+			} else {
+				diags = append(diags, core.Warning(i+1, core.CodeUnrecognisedLine, "malformed require %q", trimmed))
 			}
 		}
 	}
 
-	return deps
+	return deps, diags
 }
 
 // newRequireDep builds a Dependency from a parsed require entry, determining
 // scope based on whether the module is used by a tool directive.
-func newRequireDep(name, version, rawLine string, tools map[string]bool) core.Dependency {
+func newRequireDep(name, version string, lineNo int, rawLine string, tools map[string]bool) core.Dependency {
 	direct := !strings.Contains(rawLine, "// indirect")
 	scope := core.Runtime
 	if isToolModule(name, tools) {
 		scope = core.Development
 	}
+	pos, raw := core.LineSpan(lineNo, rawLine)
 	return core.Dependency{
-		Name:    name,
-		Version: version,
-		Scope:   scope,
-		Direct:  direct,
+		Name:     name,
+		Version:  version,
+		Scope:    scope,
+		Direct:   direct,
+		Position: pos,
+		Raw:      raw,
 	}
 }
 
@@ -163,6 +288,124 @@ func isToolModule(module string, tools map[string]bool) bool {
 	return false
 }
 
+// goDirective is one directive of a go.mod or go.work file. Blocks such
+// as "use ( ... )" are expanded into a directive per line, each with
+// the block's verb.
+type goDirective struct {
+	verb string
+	args []string
+	line int
+	raw  string
+	// comment is the text of the comment ending the line or, failing
+	// that, of the comment lines directly above it.
+	comment string
+}
+
+func (d goDirective) malformed() core.Diagnostic {
+	return core.Warning(d.line, core.CodeUnrecognisedLine, "malformed %s %q", d.verb, strings.TrimSpace(d.raw))
+}
+
+// goDirectives splits go.mod syntax into directives, unquoting
+// arguments.
+func goDirectives(content []byte) []goDirective {
+	var directives []goDirective
+	var above []string
+	block := ""
+	for i, line := range strings.Split(string(content), "\n") {
+		fields, comment := goFields(line)
+		if len(fields) == 0 {
+			if comment != "" {
+				above = append(above, comment)
+			} else {
+				above = nil
+			}
+			continue
+		}
+		if comment == "" {
+			comment = strings.Join(above, "\n")
+		}
+		above = nil
+
+		d := goDirective{verb: block, args: fields, line: i + 1, raw: line, comment: comment}
+		switch {
+		case block != "":
+			if fields[0] == ")" {
+				block = ""
+				continue
+			}
+		case len(fields) == 2 && fields[1] == "(":
+			block = fields[0]
+			continue
+		case len(fields) == 1 && strings.HasSuffix(fields[0], "("):
+			block = strings.TrimSuffix(fields[0], "(")
+			continue
+		default:
+			d.verb, d.args = fields[0], fields[1:]
+		}
+		directives = append(directives, d)
+	}
+	return directives
+}
+
+// goFields splits a line into whitespace-separated tokens, returning
+// the text of any // comment that ends it separately. Quoted tokens may
+// hold spaces and are unquoted.
+func goFields(line string) (fields []string, comment string) {
+	for {
+		line = strings.TrimLeft(line, " \t\r")
+		if line == "" {
+			return fields, ""
+		}
+		if c, ok := strings.CutPrefix(line, "//"); ok {
+			return fields, strings.TrimSpace(c)
+		}
+		end := strings.IndexAny(line, " \t\r")
+		if line[0] == '"' || line[0] == '`' {
+			if prefix, err := strconv.QuotedPrefix(line); err == nil {
+				unquoted, _ := strconv.Unquote(prefix)
+				fields = append(fields, unquoted)
+				line = line[len(prefix):]
+				continue
+			}
+		}
+		if end < 0 {
+			return append(fields, line), ""
+		}
+		fields = append(fields, line[:end])
+		line = line[end:]
+	}
+}
+
+// goReplacement reads a replace directive: old [version] => new [version].
+func goReplacement(d goDirective) (core.Replacement, bool) {
+	arrow := slices.Index(d.args, "=>")
+	if arrow < 1 || arrow > 2 || len(d.args)-arrow < 2 || len(d.args)-arrow > 3 {
+		return core.Replacement{}, false
+	}
+	pos, raw := core.LineSpan(d.line, d.raw)
+	r := core.Replacement{Name: d.args[0], NewName: d.args[arrow+1], Position: pos, Raw: raw}
+	if arrow == 2 {
+		r.Version = d.args[1]
+	}
+	if len(d.args) == arrow+3 {
+		r.NewVersion = d.args[arrow+2]
+	}
+	return r, true
+}
+
+// goRuntime reads a go or toolchain directive. Toolchain names such as
+// "go1.22.1" are reported by their version.
+func goRuntime(d goDirective) (core.Requirement, bool) {
+	if len(d.args) != 1 {
+		return core.Requirement{}, false
+	}
+	version := d.args[0]
+	if d.verb == "toolchain" {
+		version = strings.TrimPrefix(version, "go")
+	}
+	return core.Requirement{Name: "go", Version: version, Source: d.verb}, true
+}
+
 // goSumParser parses go.sum files.
 type goSumParser struct{}
 
@@ -234,10 +477,12 @@ func (p *goGraphParser) Parse(filename string, content []byte) (*core.Result, er
 	var deps []core.Dependency
 	seen := make(map[string]bool)
 	directDeps := make(map[string]bool)
+	graph := core.NewGraphBuilder()
 	lines := strings.Split(string(content), "\n")
 
 	// First pass: identify direct dependencies (those required by the main module)
-	// The main module appears without a version in the first column
+	// and record every edge. The main module appears without a version in the
+	// first column.
 	for _, line := range lines {
 		line = strings.TrimSpace(line)
 		if line == "" {
@@ -253,12 +498,20 @@ func (p *goGraphParser) Parse(filename string, content []byte) (*core.Result, er
 		parent := parts[0]
 		dep := parts[1]
 
+		child, ok := splitGraphModule(dep)
+		if !ok {
+			continue
+		}
+
 		// If parent has no @version, it's the main module
 		if !strings.Contains(parent, "@") {
-			// Extract just the name from dep (before @)
-			if idx := strings.LastIndex(dep, "@"); idx > 0 {
-				directDeps[dep[:idx]] = true
-			}
+			directDeps[child.Name] = true
+			graph.AddRoot(child)
+			continue
+		}
+
+		if from, ok := splitGraphModule(parent); ok {
+			graph.AddEdge(from, child)
 		}
 	}
 
@@ -299,5 +552,14 @@ func (p *goGraphParser) Parse(filename string, content []byte) (*core.Result, er
 		})
 	}
 
-	return &core.Result{Dependencies: deps}, nil
+	return &core.Result{Dependencies: deps, Graph: graph.Graph()}, nil
+}
+
+// splitGraphModule splits a "path@version" entry from go mod graph output.
+func splitGraphModule(s string) (core.Node, bool) {
+	idx := strings.LastIndex(s, "@")
+	if idx <= 0 {
+		return core.Node{}, false
+	}
+	return core.Node{Name: s[:idx], Version: s[idx+1:]}, true
 }
@@ -2,6 +2,7 @@ package golang
 
 import (
 	"os"
+	"slices"
 	"testing"
 
 	"github.com/git-pkgs/manifests/internal/core"
@@ -52,11 +53,13 @@ func TestGoMod(t *testing.T) {
 		}
 	}
 
-	// Check single-line require
-	if net, ok := depMap["golang.org/x/net"]; !ok {
-		t.Error("expected golang.org/x/net dependency")
-	} else if net.Version != "v1.2.3" {
-		t.Errorf("net version = %q, want %q", net.Version, "v1.2.3")
+	// Check single-line require, replaced by the fork that names its version
+	if net, ok := depMap["example.com/fork/net"]; !ok {
+		t.Error("expected golang.org/x/net to be replaced by example.com/fork/net")
+	} else if net.Version != "v1.4.5" {
+		t.Errorf("net version = %q, want %q", net.Version, "v1.4.5")
+	} else if r := net.Replacement; r == nil || r.Name != "golang.org/x/net" || r.Version != "v1.2.3" {
+		t.Errorf("net replacement = %+v", r)
 	}
 
 	// Check tool dependencies are marked as Development scope
@@ -508,3 +511,251 @@ func TestGoGraph(t *testing.T) {
 		}
 	}
 }
+
+func TestGoGraphEdges(t *testing.T) {
+	content, err := os.ReadFile("../../testdata/golang/go.graph")
+	if err != nil {
+		t.Fatalf("failed to read fixture: %v", err)
+	}
+
+	parser := &goGraphParser{}
+	res, err := parser.Parse("go.graph", content)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	if res.Graph == nil {
+		t.Fatal("expected a graph")
+	}
+	if len(res.Graph.Roots) != 3 {
+		t.Errorf("expected 3 roots, got %d", len(res.Graph.Roots))
+	}
+	if len(res.Graph.Edges) != 5 {
+		t.Errorf("expected 5 edges, got %d", len(res.Graph.Edges))
+	}
+
+	path := res.Graph.Path("gopkg.in/yaml.v3", "")
+	want := []core.Node{
+		{Name: "github.com/stretchr/testify", Version: "v1.8.4"},
+		{Name: "gopkg.in/yaml.v3", Version: "v3.0.1"},
+	}
+	if len(path) != len(want) {
+		t.Fatalf("Path = %v, want %v", path, want)
+	}
+	for i := range want {
+		if path[i] != want[i] {
+			t.Errorf("Path[%d] = %v, want %v", i, path[i], want[i])
+		}
+	}
+
+	if p := res.Graph.Path("gopkg.in/yaml.v3", "v2.0.0"); p != nil {
+		t.Errorf("Path with wrong version = %v, want nil", p)
+	}
+}
+
+func TestGoModDiagnostics(t *testing.T) {
+	content := []byte(`module example.com/app
+
+require github.com/pkg/errors
+
+require (
+	golang.org/x/text v0.14.0
+	golang.org/x/net v0.20.0 extra
+)
+`)
+	res, err := (&goModParser{}).Parse("go.mod", content)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if len(res.Dependencies) != 1 {
+		t.Errorf("expected 1 dependency, got %+v", res.Dependencies)
+	}
+	if len(res.Diagnostics) != 2 || res.Diagnostics[0].Line != 3 || res.Diagnostics[1].Line != 7 {
+		t.Fatalf("unexpected diagnostics %+v", res.Diagnostics)
+	}
+	for _, d := range res.Diagnostics {
+		if d.Severity != core.SeverityWarning || d.Code != core.CodeUnrecognisedLine {
+			t.Errorf("unexpected diagnostic %+v", d)
+		}
+	}
+}
+
+func TestGoWork(t *testing.T) {
+	content, err := os.ReadFile("../../testdata/golang/go.work")
+	if err != nil {
+		t.Fatalf("failed to read fixture: %v", err)
+	}
+
+	res, err := (&goWorkParser{}).Parse("go.work", content)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	if len(res.Dependencies) != 0 {
+		t.Errorf("expected no dependencies, got %d", len(res.Dependencies))
+	}
+	if res.Workspace == nil {
+		t.Fatal("expected a workspace")
+	}
+	wantUse := []string{"./api", "./internal/tools", "./web app", "./cmd/cli"}
+	if !slices.Equal(res.Workspace.Members, wantUse) {
+		t.Errorf("Members = %q, want %q", res.Workspace.Members, wantUse)
+	}
+
+	wantRuntimes := []core.Requirement{
+		{Name: "go", Version: "1.23.0", Source: "go"},
+		{Name: "go", Version: "1.23.4", Source: "toolchain"},
+	}
+	if !slices.Equal(res.Runtimes, wantRuntimes) {
+		t.Errorf("Runtimes = %+v, want %+v", res.Runtimes, wantRuntimes)
+	}
+
+	wantReplace := []struct{ name, version, newName, newVersion string }{
+		{"golang.org/x/net", "v0.20.0", "golang.org/x/net", "v0.21.0"},
+		{"github.com/acme/logger", "", "github.com/acme-forks/logger", "v1.4.1-fork.2"},
+		{"example.com/shared", "", "../shared", ""},
+	}
+	if len(res.Replacements) != len(wantReplace) {
+		t.Fatalf("expected %d replacements, got %+v", len(wantReplace), res.Replacements)
+	}
+	for i, want := range wantReplace {
+		r := res.Replacements[i]
+		if r.Name != want.name || r.Version != want.version || r.NewName != want.newName || r.NewVersion != want.newVersion {
+			t.Errorf("replacement %d = %+v, want %+v", i, r, want)
+		}
+	}
+	if r := res.Replacements[0]; r.Position.StartLine != 15 || r.Raw != "replace golang.org/x/net v0.20.0 => golang.org/x/net v0.21.0" {
+		t.Errorf("replacement position = %+v, raw %q", r.Position, r.Raw)
+	}
+
+	if len(res.Diagnostics) != 1 || res.Diagnostics[0].Line != 20 {
+		t.Errorf("expected a diagnostic for line 20, got %+v", res.Diagnostics)
+	}
+}
+
+func TestGoWorkWithoutUse(t *testing.T) {
+	def, err := (&goWorkParser{}).ReadWorkspace("go.work", []byte("go 1.22\n"))
+	if err != nil || def != nil {
+		t.Errorf("expected no workspace, got %+v, %v", def, err)
+	}
+}
+
+func TestGoWorkSum(t *testing.T) {
+	_, _, kind := core.IdentifyParser("go.work.sum")
+	if kind != core.Supplement {
+		t.Errorf("go.work.sum kind = %q, want supplement", kind)
+	}
+}
+
+func TestGoModDirectives(t *testing.T) {
+	content, err := os.ReadFile("../../testdata/golang/go.mod")
+	if err != nil {
+		t.Fatalf("failed to read fixture: %v", err)
+	}
+
+	res, err := (&goModParser{}).Parse("go.mod", content)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	wantRuntimes := []core.Requirement{
+		{Name: "go", Version: "1.24", Source: "go"},
+		{Name: "go", Version: "1.24.0", Source: "toolchain"},
+	}
+	if !slices.Equal(res.Runtimes, wantRuntimes) {
+		t.Errorf("Runtimes = %+v, want %+v", res.Runtimes, wantRuntimes)
+	}
+
+	if len(res.Excludes) != 2 {
+		t.Fatalf("expected 2 excludes, got %+v", res.Excludes)
+	}
+	if e := res.Excludes[1]; e.Name != "older/thing" || e.Version != "4.5.6" || e.Position.StartLine != 24 {
+		t.Errorf("unexpected exclude %+v", e)
+	}
+
+	if len(res.Replacements) != 5 {
+		t.Fatalf("expected 5 replacements, got %d", len(res.Replacements))
+	}
+	if r := res.Replacements[0]; r.Name != "bad/thing" || r.Version != "v1.4.5" || r.NewName != "good/thing" || r.NewVersion != "v1.4.5" {
+		t.Errorf("unexpected replacement %+v", r)
+	}
+	if r := res.Replacements[4]; r.Name != "golang.org/x/net" || r.Version != "" || r.NewName != "./fork/net" || r.NewVersion != "" {
+		t.Errorf("unexpected replacement %+v", r)
+	}
+
+	// retract [v2.0.0, v1.9.9] on line 38 is inverted and skipped
+	if len(res.Retractions) != 3 {
+		t.Fatalf("expected 3 retractions, got %+v", res.Retractions)
+	}
+	single, interval := res.Retractions[0], res.Retractions[2]
+	if single.Rationale != "this is the single-line retract directive" || !single.Range.Satisfies("v1.0.0") || single.Range.Satisfies("v1.0.1") {
+		t.Errorf("unexpected retraction %+v", single)
+	}
+	if interval.Range.VERS != "vers:golang/>=v1.0.0|<=v1.9.9" || !interval.Range.Satisfies("v1.5.0") || interval.Range.Satisfies("v2.0.0") {
+		t.Errorf("unexpected retraction %+v", interval.Range)
+	}
+	if res.Retractions[1].Rationale != "this is the multi-line retract directive" {
+		t.Errorf("rationale = %q", res.Retractions[1].Rationale)
+	}
+
+	if len(res.Diagnostics) != 1 || res.Diagnostics[0].Line != 38 || res.Diagnostics[0].Severity != core.SeverityWarning {
+		t.Errorf("expected a warning for line 38, got %+v", res.Diagnostics)
+	}
+}
+
+func TestGoModReplaceApplied(t *testing.T) {
+	content := []byte(`module example.com/app
+
+require (
+	github.com/acme/logger v1.2.0
+	github.com/acme/config v0.3.0
+	github.com/acme/metrics v0.1.0
+	golang.org/x/text v0.14.0
+)
+
+replace github.com/acme/logger => github.com/acme-forks/logger v1.2.1-fork
+
+replace github.com/acme/config v0.3.0 => ../config
+
+replace github.com/acme/metrics v0.0.9 => github.com/acme-forks/metrics v0.0.9
+
+// Deprecated path, pinned while v1 is unreleased
+retract [v0.1.0, v0.1.3]
+`)
+	res, err := (&goModParser{}).Parse("go.mod", content)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	want := []struct{ name, version, replaced string }{
+		{"github.com/acme-forks/logger", "v1.2.1-fork", "github.com/acme/logger"},
+		{"github.com/acme/config", "v0.3.0", "github.com/acme/config"},
+		{"github.com/acme/metrics", "v0.1.0", ""},
+		{"golang.org/x/text", "v0.14.0", ""},
+	}
+	if len(res.Dependencies) != len(want) {
+		t.Fatalf("expected %d dependencies, got %d", len(want), len(res.Dependencies))
+	}
+	for i, w := range want {
+		dep := res.Dependencies[i]
+		if dep.Name != w.name || dep.Version != w.version {
+			t.Errorf("dependency %d = %s@%s, want %s@%s", i, dep.Name, dep.Version, w.name, w.version)
+		}
+		if w.replaced == "" {
+			if dep.Replacement != nil {
+				t.Errorf("%s: unexpected replacement %+v", dep.Name, dep.Replacement)
+			}
+		} else if dep.Replacement == nil || dep.Replacement.Name != w.replaced {
+			t.Errorf("%s: replacement = %+v, want one of %s", dep.Name, dep.Replacement, w.replaced)
+		}
+	}
+	if r := res.Dependencies[1].Replacement; r != &res.Replacements[1] {
+		t.Error("replacement should point into Replacements")
+	}
+	if len(res.Retractions) != 1 || res.Retractions[0].Rationale != "Deprecated path, pinned while v1 is unreleased" {
+		t.Errorf("unexpected retractions %+v", res.Retractions)
+	}
+	if r := res.Dependencies[1].Replacement; r.NewName != "../config" || r.NewVersion != "" {
+		t.Errorf("unexpected directory replacement %+v", r)
+	}
+}
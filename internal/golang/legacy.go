@@ -1,12 +1,12 @@
 package golang
 
 import (
+	"context"
 	"encoding/json"
 	"strings"
 
 	"github.com/BurntSushi/toml"
 	"github.com/git-pkgs/manifests/internal/core"
-	"gopkg.in/yaml.v3"
 )
 
 const basePackageSegments = 3 // e.g. github.com/owner/repo
@@ -94,9 +94,13 @@ type glideYAML struct {
 }
 
 func (p *glideYAMLParser) Parse(filename string, content []byte) (*core.Result, error) {
+	return p.ParseLimited(context.Background(), filename, content, core.Limits{})
+}
+
+func (p *glideYAMLParser) ParseLimited(ctx context.Context, filename string, content []byte, limits core.Limits) (*core.Result, error) {
 	var glide glideYAML
-	if err := yaml.Unmarshal(content, &glide); err != nil {
-		return nil, &core.ParseError{Filename: filename, Err: err}
+	if err := core.DecodeYAML(filename, content, &glide, limits); err != nil {
+		return nil, err
 	}
 
 	var deps []core.Dependency
@@ -127,9 +131,13 @@ type glideLock struct {
 }
 
 func (p *glideLockParser) Parse(filename string, content []byte) (*core.Result, error) {
+	return p.ParseLimited(context.Background(), filename, content, core.Limits{})
+}
+
+func (p *glideLockParser) ParseLimited(ctx context.Context, filename string, content []byte, limits core.Limits) (*core.Result, error) {
 	var lock glideLock
-	if err := yaml.Unmarshal(content, &lock); err != nil {
-		return nil, &core.ParseError{Filename: filename, Err: err}
+	if err := core.DecodeYAML(filename, content, &lock, limits); err != nil {
+		return nil, err
 	}
 
 	var deps []core.Dependency
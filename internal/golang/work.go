@@ -0,0 +1,62 @@
+package golang
+
+import (
+	"github.com/git-pkgs/manifests/internal/core"
+)
+
+func init() {
+	// go.work - manifest (a workspace of modules; no dependencies of its own)
+	core.Register("golang", core.Manifest, &goWorkParser{}, core.ExactMatch("go.work"))
+	core.RegisterWorkspace("golang", &goWorkParser{}, core.ExactMatch("go.work"))
+
+	// go.work.sum - supplement (hashes the workspace needs beyond its modules' go.sum)
+	core.Register("golang", core.Supplement, &goSumParser{}, core.ExactMatch("go.work.sum"))
+}
+
+// goWorkParser parses go.work files. Their use directives become the
+// result's Workspace, with replace directives as Replacements and the go
+// and toolchain directives as Runtimes.
+type goWorkParser struct{}
+
+func (p *goWorkParser) Parse(filename string, content []byte) (*core.Result, error) {
+	res := &core.Result{}
+	def := &core.WorkspaceDefinition{Manifests: []string{"go.mod"}}
+	for _, d := range goDirectives(content) {
+		switch d.verb {
+		case "use":
+			if len(d.args) != 1 {
+				res.Diagnostics = append(res.Diagnostics, d.malformed())
+				continue
+			}
+			def.Members = append(def.Members, d.args[0])
+		case "replace":
+			r, ok := goReplacement(d)
+			if !ok {
+				res.Diagnostics = append(res.Diagnostics, d.malformed())
+				continue
+			}
+			res.Replacements = append(res.Replacements, r)
+		case "go", "toolchain":
+			r, ok := goRuntime(d)
+			if !ok {
+				res.Diagnostics = append(res.Diagnostics, d.malformed())
+				continue
+			}
+			res.Runtimes = append(res.Runtimes, r)
+		}
+	}
+	if len(def.Members) > 0 {
+		res.Workspace = def
+	}
+	return res, nil
+}
+
+// ReadWorkspace returns the module directories go.work uses. Each is a
+// path, never a pattern.
+func (p *goWorkParser) ReadWorkspace(filename string, content []byte) (*core.WorkspaceDefinition, error) {
+	res, err := p.Parse(filename, content)
+	if err != nil {
+		return nil, err
+	}
+	return res.Workspace, nil
+}
@@ -1,11 +1,10 @@
 package hackage
 
 import (
+	"context"
 	"github.com/git-pkgs/manifests/internal/core"
 	"regexp"
 	"strings"
-
-	"gopkg.in/yaml.v3"
 )
 
 func init() {
@@ -127,9 +126,13 @@ type stackLockPackage struct {
 }
 
 func (p *stackLockParser) Parse(filename string, content []byte) (*core.Result, error) {
+	return p.ParseLimited(context.Background(), filename, content, core.Limits{})
+}
+
+func (p *stackLockParser) ParseLimited(ctx context.Context, filename string, content []byte, limits core.Limits) (*core.Result, error) {
 	var lock stackLock
-	if err := yaml.Unmarshal(content, &lock); err != nil {
-		return nil, &core.ParseError{Filename: filename, Err: err}
+	if err := core.DecodeYAML(filename, content, &lock, limits); err != nil {
+		return nil, err
 	}
 
 	var deps []core.Dependency
@@ -15,6 +15,11 @@ type haxelibJSONParser struct{}
 type haxelibJSON struct {
 	Name         string            `json:"name"`
 	Version      string            `json:"version"`
+	Description  string            `json:"description"`
+	License      string            `json:"license"`
+	URL          string            `json:"url"`
+	Contributors []string          `json:"contributors"`
+	Tags         []string          `json:"tags"`
 	Dependencies map[string]string `json:"dependencies"`
 }
 
@@ -35,5 +40,12 @@ func (p *haxelibJSONParser) Parse(filename string, content []byte) (*core.Result
 		})
 	}
 
-	return &core.Result{Name: haxelib.Name, Version: haxelib.Version, Dependencies: deps}, nil
+	metadata := core.Metadata{
+		Description: haxelib.Description,
+		License:     haxelib.License,
+		Homepage:    haxelib.URL,
+		Authors:     haxelib.Contributors,
+		Keywords:    haxelib.Tags,
+	}
+	return &core.Result{Name: haxelib.Name, Version: haxelib.Version, Metadata: metadata, Dependencies: deps}, nil
 }
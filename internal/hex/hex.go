@@ -21,6 +21,14 @@ var (
 	mixAppRegex = regexp.MustCompile(`\bapp:\s*:([a-zA-Z_][a-zA-Z0-9_]*)`)
 	// version: "0.0.1" inside def project
 	mixVersionRegex = regexp.MustCompile(`\bversion:\s*"([^"]+)"`)
+	// @source_url "https://..." module attributes
+	mixAttrRegex = regexp.MustCompile(`(?m)^\s*@([a-z_]+)\s+"([^"]*)"`)
+	// key: "value" or key: @attr, for the package metadata keywords
+	mixKeywordRegex = regexp.MustCompile(`\b(description|source_url|homepage_url):\s*(?:"([^"]*)"|@([a-z_]+))`)
+	// licenses: ["MIT"] and maintainers: ["Jane Doe"]
+	mixListRegex   = regexp.MustCompile(`\b(licenses|maintainers):\s*\[([^\]]*)\]`)
+	mixGitHubRegex = regexp.MustCompile(`"GitHub"\s*=>\s*"([^"]+)"`)
+	mixQuoteRegex  = regexp.MustCompile(`"([^"]*)"`)
 )
 
 func (p *mixExsParser) Parse(filename string, content []byte) (*core.Result, error) {
@@ -38,13 +46,15 @@ func (p *mixExsParser) Parse(filename string, content []byte) (*core.Result, err
 		}
 	}
 
+	metadata := mixMetadata(text)
+
 	// Find deps function content
 	depsStart := strings.Index(text, "defp deps do")
 	if depsStart < 0 {
 		depsStart = strings.Index(text, "def deps do")
 	}
 	if depsStart < 0 {
-		return &core.Result{Name: selfName, Version: selfVersion, Dependencies: deps}, nil
+		return &core.Result{Name: selfName, Version: selfVersion, Metadata: metadata, Dependencies: deps}, nil
 	}
 
 	section := extractMixBlock(text[depsStart:])
@@ -57,7 +67,65 @@ func (p *mixExsParser) Parse(filename string, content []byte) (*core.Result, err
 		})
 	}
 
-	return &core.Result{Name: selfName, Version: selfVersion, Dependencies: deps}, nil
+	return &core.Result{Name: selfName, Version: selfVersion, Metadata: metadata, Dependencies: deps}, nil
+}
+
+// mixMetadata reads the description, source_url and homepage_url project
+// keys and the licenses, maintainers and GitHub link of the Hex package
+// config, wherever they're defined. Values may be string literals or
+// module attributes holding one; heredocs and other expressions aren't
+// evaluated.
+func mixMetadata(text string) core.Metadata {
+	var code strings.Builder
+	core.ForEachLine(text, func(line string) bool {
+		if !strings.HasPrefix(strings.TrimSpace(line), "#") {
+			code.WriteString(line)
+			code.WriteByte('\n')
+		}
+		return true
+	})
+	text = code.String()
+
+	attrs := make(map[string]string)
+	for _, m := range mixAttrRegex.FindAllStringSubmatch(text, -1) {
+		attrs[m[1]] = m[2]
+	}
+	values := make(map[string]string)
+	for _, m := range mixKeywordRegex.FindAllStringSubmatch(text, -1) {
+		if _, seen := values[m[1]]; seen {
+			continue
+		}
+		if m[3] != "" {
+			values[m[1]] = attrs[m[3]]
+		} else {
+			values[m[1]] = m[2]
+		}
+	}
+
+	meta := core.Metadata{
+		Description: values["description"],
+		Homepage:    values["homepage_url"],
+		Repository:  values["source_url"],
+	}
+	if meta.Repository == "" {
+		if m := mixGitHubRegex.FindStringSubmatch(text); m != nil {
+			meta.Repository = m[1]
+		}
+	}
+	for _, m := range mixListRegex.FindAllStringSubmatch(text, -1) {
+		var items []string
+		for _, q := range mixQuoteRegex.FindAllStringSubmatch(m[2], -1) {
+			items = append(items, q[1])
+		}
+		switch {
+		case m[1] == "licenses" && meta.License == "":
+			// Hex doesn't say how several licenses combine
+			meta.License = core.JoinLicenses("AND", items...)
+		case m[1] == "maintainers" && meta.Authors == nil:
+			meta.Authors = items
+		}
+	}
+	return meta
 }
 
 // extractMixBlock returns the bracket-delimited body starting at text.
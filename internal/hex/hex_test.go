@@ -94,3 +94,45 @@ func TestMixLock(t *testing.T) {
 		}
 	}
 }
+
+func TestMixExsMetadata(t *testing.T) {
+	content := []byte(`defmodule Acme.MixProject do
+  use Mix.Project
+
+  @source_url "https://github.com/acme/acme"
+
+  def project do
+    [
+      app: :acme,
+      version: "1.0.0",
+      description: "Does things",
+      source_url: @source_url,
+      package: package(),
+      deps: deps()
+    ]
+  end
+
+  defp package do
+    [licenses: ["Apache-2.0"], maintainers: ["Jane Doe"], links: %{"GitHub" => @source_url}]
+  end
+
+  defp deps do
+    [{:plug, "~> 1.14"}]
+  end
+end
+`)
+	res, err := (&mixExsParser{}).Parse("mix.exs", content)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	m := res.Metadata
+	if m.Description != "Does things" || m.License != "Apache-2.0" || m.Repository != "https://github.com/acme/acme" {
+		t.Errorf("unexpected metadata %+v", m)
+	}
+	if len(m.Authors) != 1 || m.Authors[0] != "Jane Doe" {
+		t.Errorf("Authors = %q", m.Authors)
+	}
+	if len(res.Dependencies) != 1 {
+		t.Errorf("expected 1 dependency, got %d", len(res.Dependencies))
+	}
+}
@@ -33,6 +33,7 @@ type juliaProjectParser struct{}
 type juliaProject struct {
 	Name    string            `toml:"name"`
 	Version string            `toml:"version"`
+	Authors []string          `toml:"authors"`
 	Deps    map[string]string `toml:"deps"`
 	Compat  map[string]string `toml:"compat"`
 }
@@ -59,7 +60,7 @@ func (p *juliaProjectParser) Parse(filename string, content []byte) (*core.Resul
 		})
 	}
 
-	return &core.Result{Name: project.Name, Version: project.Version, Dependencies: deps}, nil
+	return &core.Result{Name: project.Name, Version: project.Version, Metadata: core.Metadata{Authors: project.Authors}, Dependencies: deps}, nil
 }
 
 // juliaManifestParser parses Julia Manifest.toml files using regex for speed.
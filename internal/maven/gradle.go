@@ -138,8 +138,10 @@ func (p *gradleParser) Parse(filename string, content []byte) (*core.Result, err
 	text := string(content)
 	deps := make([]core.Dependency, 0, core.EstimateDeps(len(content)))
 	seen := make(map[string]bool)
+	lineNo := 0
 
 	core.ForEachLine(text, func(line string) bool {
+		lineNo++
 		keyword, pos, isTest := findGradleKeyword(line)
 		if keyword == "" {
 			return true
@@ -166,11 +168,14 @@ func (p *gradleParser) Parse(filename string, content []byte) (*core.Result, err
 			scope = core.Test
 		}
 
+		position, raw := core.LineSpan(lineNo, line)
 		deps = append(deps, core.Dependency{
-			Name:    name,
-			Version: version,
-			Scope:   scope,
-			Direct:  true,
+			Name:     name,
+			Version:  version,
+			Scope:    scope,
+			Direct:   true,
+			Position: position,
+			Raw:      raw,
 		})
 		return true
 	})
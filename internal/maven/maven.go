@@ -1,10 +1,13 @@
 package maven
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"encoding/xml"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"strings"
 
 	"github.com/git-pkgs/manifests/internal/core"
@@ -32,35 +35,52 @@ func init() {
 // properties defined in a multi-module root resolve. Nothing is fetched
 // over the network and nothing outside fsRoot is read. Anything that would
 // need a remote parent or BOM is left as-is and the dependency keeps its
-// raw ${...} version.
+// raw ${...} version, with a diagnostic.
 type pomXMLParser struct{}
 
 func (p *pomXMLParser) Parse(filename string, content []byte) (*core.Result, error) {
-	return p.ParseInRoot(filename, content, "")
+	return p.ParseInRoot(context.Background(), filename, content, "", core.Limits{})
 }
 
-func (p *pomXMLParser) ParseInRoot(filename string, content []byte, fsRoot string) (*core.Result, error) {
+func (p *pomXMLParser) ParseInRoot(ctx context.Context, filename string, content []byte, fsRoot string, limits core.Limits) (*core.Result, error) {
+	if err := core.CheckXMLDepth(filename, content, limits.MaxDepth); err != nil {
+		return nil, err
+	}
 	root, err := pom.ParsePOM(content)
 	if err != nil {
 		return nil, &core.ParseError{Filename: filename, Err: err}
 	}
 
 	fetcher := pom.NewLocalFetcherFrom(root, filepath.Dir(filename), fsRoot)
-	ep, err := pom.NewResolver(fetcher).ResolvePOM(context.Background(), root, pom.Options{})
+	ep, err := pom.NewResolver(fetcher).ResolvePOM(ctx, root, pom.Options{})
 	if err != nil {
 		return nil, &core.ParseError{Filename: filename, Err: err}
 	}
 
+	locator := core.NewLocator(content, locatePOMDependencies(content))
 	deps := make([]core.Dependency, 0, len(ep.Dependencies))
+	var diags []core.Diagnostic
 	for _, d := range ep.Dependencies {
+		name := d.GroupID + ":" + d.ArtifactID
 		if strings.Contains(d.GroupID, "${") && strings.Contains(d.ArtifactID, "${") {
+			diags = append(diags, core.Warning(0, core.CodeUnresolvedVariable, "dependency %s has unresolved coordinates", name))
 			continue
 		}
+		pos, raw := locator.Locate(pomByCoordinates, name)
+		if !pos.IsValid() {
+			// groupId may have been interpolated from ${project.groupId}
+			pos, raw = locator.Locate(pomByArtifact, d.ArtifactID)
+		}
+		if strings.Contains(d.Version, "${") {
+			diags = append(diags, core.Warning(pos.StartLine, core.CodeUnresolvedVariable, "%s has unresolved version %s", name, d.Version))
+		}
 		deps = append(deps, core.Dependency{
-			Name:    d.GroupID + ":" + d.ArtifactID,
-			Version: d.Version,
-			Scope:   mapScope(d.Scope, d.Optional),
-			Direct:  true,
+			Name:     name,
+			Version:  d.Version,
+			Scope:    mapScope(d.Scope, d.Optional),
+			Direct:   true,
+			Position: pos,
+			Raw:      raw,
 		})
 	}
 
@@ -68,7 +88,286 @@ func (p *pomXMLParser) ParseInRoot(filename string, content []byte, fsRoot strin
 	if ep.GAV.GroupID != "" {
 		selfName = ep.GAV.GroupID + ":" + ep.GAV.ArtifactID
 	}
-	return &core.Result{Name: selfName, Version: ep.GAV.Version, Dependencies: deps}, nil
+	return &core.Result{
+		Name:         selfName,
+		Version:      ep.GAV.Version,
+		Metadata:     pomMetadata(ep, content),
+		Dependencies: deps,
+		Diagnostics:  diags,
+		Runtimes:     pomRuntimes(ep),
+	}, nil
+}
+
+// pomJavaProperties are the properties that set the Java version, in
+// order of precedence: the compiler plugin's release and source, then
+// the java.version convention Spring Boot's parent uses to set them.
+var pomJavaProperties = []string{"maven.compiler.release", "maven.compiler.source", "java.version"}
+
+// pomRuntimes returns the Java version the effective POM compiles for.
+func pomRuntimes(ep *pom.EffectivePOM) []core.Requirement {
+	for _, prop := range pomJavaProperties {
+		if v := ep.Properties[prop]; v != "" && !strings.Contains(v, "${") {
+			return []core.Requirement{{Name: "java", Version: v, Source: prop}}
+		}
+	}
+	return nil
+}
+
+// pomMetadata builds the package metadata from the effective POM, so
+// that a license or URL declared in a local parent is picked up.
+// Developers aren't part of the effective POM and are read from this
+// file only.
+func pomMetadata(ep *pom.EffectivePOM, content []byte) core.Metadata {
+	m := core.Metadata{
+		Description: ep.Description,
+		Homepage:    ep.URL,
+		Repository:  pomRepository(ep.SCM),
+	}
+	// Maven reads several licenses as a choice between them
+	licenses := make([]string, len(ep.Licenses))
+	for i, l := range ep.Licenses {
+		licenses[i] = l.Name
+	}
+	m.License = core.JoinLicenses("OR", licenses...)
+
+	var developers struct {
+		Developers []struct {
+			Name  string `xml:"name"`
+			Email string `xml:"email"`
+		} `xml:"developers>developer"`
+	}
+	if xml.Unmarshal(content, &developers) == nil {
+		for _, d := range developers.Developers {
+			if author := core.Author(d.Name, d.Email); author != "" {
+				m.Authors = append(m.Authors, author)
+			}
+		}
+	}
+	return m
+}
+
+// pomRepository picks the repository URL from <scm>, preferring an
+// http(s) URL from any of its elements. Connections such as
+// scm:git:https://github.com/org/repo.git lose their scm:provider:
+// prefix.
+func pomRepository(scm pom.SCM) string {
+	var fallback string
+	for _, u := range []string{scm.URL, scm.Connection, scm.DeveloperConnection} {
+		if rest, ok := strings.CutPrefix(strings.TrimSpace(u), "scm:"); ok {
+			_, u, _ = strings.Cut(rest, ":")
+		}
+		if strings.HasPrefix(u, "https://") || strings.HasPrefix(u, "http://") {
+			return u
+		}
+		if fallback == "" {
+			fallback = u
+		}
+	}
+	return fallback
+}
+
+// EditVersion rewrites the <version> of a dependency. A version taken
+// from a ${property} is changed where the property is set in this
+// file's <properties>, unless something besides this artifact's own
+// declarations refers to it, which gives core.ErrSharedVersion. A
+// property set in a parent, or a version managed by a parent or
+// <dependencyManagement>, has none here to change.
+func (p *pomXMLParser) EditVersion(content []byte, decl core.Span, dep *core.Dependency, version string) (core.Edit, bool, error) {
+	span, ok := pomElementText(content[:decl.End], decl.Start, []string{"dependency", "version"})
+	if !ok {
+		return core.Edit{}, false, nil
+	}
+	text := string(content[span.Start:span.End])
+	if prop, ok := strings.CutPrefix(text, "${"); ok && strings.HasSuffix(prop, "}") {
+		span, ok = pomElementText(content, 0, []string{"project", "properties", strings.TrimSuffix(prop, "}")})
+		if !ok {
+			return core.Edit{}, false, nil
+		}
+		if pomPropertyShared(content, text, dep.Name) {
+			return core.Edit{}, false, core.ErrSharedVersion
+		}
+	}
+	if version == "" || strings.ContainsAny(version, "\r\n") {
+		return core.Edit{}, false, core.ErrUnencodableVersion
+	}
+	var encoded strings.Builder
+	_ = xml.EscapeText(&encoded, []byte(version))
+	return core.Edit{Span: span, Text: encoded.String()}, true, nil
+}
+
+// pomPropertyShared reports whether ref, a "${property}" reference, is
+// used anywhere in content other than the <version> of dependencies
+// whose groupId:artifactId is name.
+func pomPropertyShared(content []byte, ref, name string) bool {
+	dec := xml.NewDecoder(bytes.NewReader(content))
+	dec.Strict = false
+
+	var stack []string
+	var group, artifact string
+	usesRef := false
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return false
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			stack = append(stack, t.Name.Local)
+			if t.Name.Local == "dependency" {
+				group, artifact, usesRef = "", "", false
+			}
+			for _, attr := range t.Attr {
+				if strings.Contains(attr.Value, ref) {
+					return true
+				}
+			}
+		case xml.CharData:
+			n := len(stack)
+			if n < 2 || stack[n-2] != "dependency" {
+				if bytes.Contains(t, []byte(ref)) {
+					return true
+				}
+				continue
+			}
+			switch text := strings.TrimSpace(string(t)); stack[n-1] {
+			case "groupId":
+				group = text
+			case "artifactId":
+				artifact = text
+			case "version":
+				usesRef = usesRef || strings.Contains(text, ref)
+			default:
+				if strings.Contains(text, ref) {
+					return true
+				}
+			}
+		case xml.EndElement:
+			if t.Name.Local == "dependency" && usesRef && group+":"+artifact != name {
+				return true
+			}
+			if len(stack) > 0 {
+				stack = stack[:len(stack)-1]
+			}
+		}
+	}
+}
+
+// pomElementText returns the span of the trimmed text of the first
+// element at path, the elements enclosing it from the document element
+// of content[start:] down. It fails for elements holding markup.
+func pomElementText(content []byte, start int, path []string) (core.Span, bool) {
+	dec := xml.NewDecoder(bytes.NewReader(content[start:]))
+	dec.Strict = false
+
+	var stack []string
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return core.Span{}, false
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			stack = append(stack, t.Name.Local)
+			if !slices.Equal(stack, path) {
+				continue
+			}
+			from := start + int(dec.InputOffset())
+			if err := dec.Skip(); err != nil {
+				return core.Span{}, false
+			}
+			// InputOffset is now past </name>
+			to := start + int(dec.InputOffset())
+			to = from + bytes.LastIndex(content[from:to], []byte("</"))
+			text := content[from:to]
+			trimmed := bytes.TrimSpace(text)
+			if len(trimmed) == 0 || bytes.ContainsAny(trimmed, "<") {
+				return core.Span{}, false
+			}
+			from += bytes.Index(text, trimmed)
+			return core.Span{Start: from, End: from + len(trimmed)}, true
+		case xml.EndElement:
+			if len(stack) > 0 {
+				stack = stack[:len(stack)-1]
+			}
+		}
+	}
+}
+
+// Sections used by locatePOMDependencies.
+const (
+	pomByCoordinates = "coordinates"
+	pomByArtifact    = "artifact"
+)
+
+// locatePOMDependencies records the byte span of every <dependency>
+// element declared directly in this file's <dependencies> (including
+// inside profiles), keyed by the literal groupId:artifactId, and by
+// artifactId alone when the groupId is a ${...} placeholder. Dependencies
+// inherited from a parent or declared in
+// <dependencyManagement> or plugins are not located.
+func locatePOMDependencies(content []byte) map[string]map[string]core.Span {
+	spans := map[string]map[string]core.Span{
+		pomByCoordinates: {},
+		pomByArtifact:    {},
+	}
+	dec := xml.NewDecoder(bytes.NewReader(content))
+	dec.Strict = false
+
+	var stack []string
+	var start int
+	var groupID, artifactID strings.Builder
+	inDependency := false
+
+	for {
+		offset := int(dec.InputOffset())
+		tok, err := dec.Token()
+		if err != nil {
+			return spans
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			stack = append(stack, t.Name.Local)
+			if t.Name.Local == "dependency" && isPOMDependencyPath(stack) {
+				inDependency = true
+				start = offset
+				groupID.Reset()
+				artifactID.Reset()
+			}
+		case xml.CharData:
+			if inDependency && len(stack) > 0 {
+				switch stack[len(stack)-1] {
+				case "groupId":
+					groupID.Write(t)
+				case "artifactId":
+					artifactID.Write(t)
+				}
+			}
+		case xml.EndElement:
+			if inDependency && t.Name.Local == "dependency" && isPOMDependencyPath(stack) {
+				inDependency = false
+				span := core.Span{Start: start, End: int(dec.InputOffset())}
+				g := strings.TrimSpace(groupID.String())
+				a := strings.TrimSpace(artifactID.String())
+				if _, ok := spans[pomByCoordinates][g+":"+a]; !ok {
+					spans[pomByCoordinates][g+":"+a] = span
+				}
+				if _, ok := spans[pomByArtifact][a]; !ok && strings.Contains(g, "${") {
+					spans[pomByArtifact][a] = span
+				}
+			}
+			if len(stack) > 0 {
+				stack = stack[:len(stack)-1]
+			}
+		}
+	}
+}
+
+// isPOMDependencyPath matches project/dependencies/dependency and
+// project/profiles/profile/dependencies/dependency.
+func isPOMDependencyPath(stack []string) bool {
+	path := strings.Join(stack, "/")
+	return path == "project/dependencies/dependency" ||
+		path == "project/profiles/profile/dependencies/dependency"
 }
 
 func mapScope(scope string, optional bool) core.Scope {
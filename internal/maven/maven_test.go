@@ -1,7 +1,9 @@
 package maven
 
 import (
+	"context"
 	"os"
+	"strings"
 	"testing"
 
 	"github.com/git-pkgs/manifests/internal/core"
@@ -356,13 +358,31 @@ func TestPomMissingProps(t *testing.T) {
 	})
 }
 
+func TestPomUnresolvedDiagnostics(t *testing.T) {
+	content, err := os.ReadFile("../../testdata/maven/pom_missing_props.xml")
+	if err != nil {
+		t.Fatalf("failed to read fixture: %v", err)
+	}
+	res, err := (&pomXMLParser{}).Parse("pom.xml", content)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if len(res.Diagnostics) != 1 {
+		t.Fatalf("expected 1 diagnostic, got %+v", res.Diagnostics)
+	}
+	d := res.Diagnostics[0]
+	if d.Code != core.CodeUnresolvedVariable || d.Line == 0 || !strings.Contains(d.Message, "org.testng:testng") {
+		t.Errorf("unexpected diagnostic %+v", d)
+	}
+}
+
 func TestPomMultiModuleLocalParent(t *testing.T) {
 	content, err := os.ReadFile("../../testdata/maven/multimodule/child/pom.xml")
 	if err != nil {
 		t.Fatalf("read fixture: %v", err)
 	}
 	parser := &pomXMLParser{}
-	res, err := parser.ParseInRoot("../../testdata/maven/multimodule/child/pom.xml", content, "../../testdata/maven/multimodule")
+	res, err := parser.ParseInRoot(context.Background(), "../../testdata/maven/multimodule/child/pom.xml", content, "../../testdata/maven/multimodule", core.Limits{})
 	if err != nil {
 		t.Fatalf("Parse failed: %v", err)
 	}
@@ -413,7 +433,7 @@ func TestPomMultiModuleFSRootJail(t *testing.T) {
 	}
 	parser := &pomXMLParser{}
 	// fsRoot is the child dir itself, so ../pom.xml is outside the jail.
-	res, err := parser.ParseInRoot("../../testdata/maven/multimodule/child/pom.xml", content, "../../testdata/maven/multimodule/child")
+	res, err := parser.ParseInRoot(context.Background(), "../../testdata/maven/multimodule/child/pom.xml", content, "../../testdata/maven/multimodule/child", core.Limits{})
 	if err != nil {
 		t.Fatalf("Parse failed: %v", err)
 	}
@@ -939,3 +959,62 @@ func TestSbtDotMatcher(t *testing.T) {
 		}
 	}
 }
+
+func TestGradleSettingsWorkspace(t *testing.T) {
+	content := []byte(`rootProject.name = "shop"
+
+include(":app", ":libs:ui")
+include ':api',
+        ':web'
+includeFlat 'shared'
+// include ':commented'
+project(":libs:ui").projectDir = file("ui")
+`)
+	def, err := (&gradleSettingsReader{}).ReadWorkspace("settings.gradle", content)
+	if err != nil {
+		t.Fatalf("ReadWorkspace failed: %v", err)
+	}
+	want := []string{"app", "ui", "api", "web", "../shared"}
+	if strings.Join(def.Members, ",") != strings.Join(want, ",") {
+		t.Errorf("Members = %q, want %q", def.Members, want)
+	}
+}
+
+func TestPomModulesWorkspace(t *testing.T) {
+	content := []byte(`<project><modules><module>core</module><module>web/pom.xml</module></modules></project>`)
+	def, err := (&pomXMLParser{}).ReadWorkspace("pom.xml", content)
+	if err != nil {
+		t.Fatalf("ReadWorkspace failed: %v", err)
+	}
+	if strings.Join(def.Members, ",") != "core,web" {
+		t.Errorf("Members = %q", def.Members)
+	}
+
+	def, err = (&pomXMLParser{}).ReadWorkspace("pom.xml", []byte(`<project><artifactId>leaf</artifactId></project>`))
+	if err != nil || def != nil {
+		t.Errorf("expected no workspace, got %+v, %v", def, err)
+	}
+}
+
+func TestPomJavaVersion(t *testing.T) {
+	tests := []struct {
+		name, properties string
+		want             []core.Requirement
+	}{
+		{"release", "<maven.compiler.source>11</maven.compiler.source><maven.compiler.release>17</maven.compiler.release>",
+			[]core.Requirement{{Name: "java", Version: "17", Source: "maven.compiler.release"}}},
+		{"spring boot", "<java.version>21</java.version>",
+			[]core.Requirement{{Name: "java", Version: "21", Source: "java.version"}}},
+		{"unresolved", "<maven.compiler.source>${jdk}</maven.compiler.source>", nil},
+	}
+	for _, tt := range tests {
+		content := []byte("<project><groupId>g</groupId><artifactId>a</artifactId><version>1</version><properties>" + tt.properties + "</properties></project>")
+		res, err := (&pomXMLParser{}).Parse("pom.xml", content)
+		if err != nil {
+			t.Fatalf("%s: Parse failed: %v", tt.name, err)
+		}
+		if len(res.Runtimes) != len(tt.want) || len(tt.want) > 0 && res.Runtimes[0] != tt.want[0] {
+			t.Errorf("%s: Runtimes = %+v, want %+v", tt.name, res.Runtimes, tt.want)
+		}
+	}
+}
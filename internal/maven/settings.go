@@ -0,0 +1,116 @@
+package maven
+
+import (
+	"encoding/xml"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/git-pkgs/manifests/internal/core"
+)
+
+func init() {
+	core.RegisterWorkspace("maven", &pomXMLParser{}, core.ExactMatch("pom.xml"))
+	core.RegisterWorkspace("maven", &gradleSettingsReader{}, core.ExactMatch("settings.gradle", "settings.gradle.kts"))
+}
+
+// ReadWorkspace reads a multi-module POM's <modules>. A module names a
+// directory, or occasionally a POM file within one.
+func (p *pomXMLParser) ReadWorkspace(filename string, content []byte) (*core.WorkspaceDefinition, error) {
+	var project struct {
+		Modules []string `xml:"modules>module"`
+	}
+	if err := xml.Unmarshal(content, &project); err != nil {
+		return nil, &core.ParseError{Filename: filename, Err: err}
+	}
+	if len(project.Modules) == 0 {
+		return nil, nil
+	}
+	def := &core.WorkspaceDefinition{Manifests: []string{"pom.xml"}}
+	for _, module := range project.Modules {
+		module = strings.TrimSpace(module)
+		if strings.HasSuffix(module, ".xml") {
+			module = path.Dir(module)
+		}
+		def.Members = append(def.Members, module)
+	}
+	return def, nil
+}
+
+// gradleSettingsReader reads the projects a settings.gradle or
+// settings.gradle.kts includes.
+type gradleSettingsReader struct{}
+
+var (
+	gradleQuotedRegex = regexp.MustCompile(`["']([^"']+)["']`)
+
+	// project(':lib').projectDir = file('libs/lib'), or
+	// project(":lib").projectDir = file("libs/lib") in Kotlin
+	gradleProjectDirRegex = regexp.MustCompile(`project\(\s*["']([^"']+)["']\s*\)\.projectDir\s*=\s*(?:file\(\s*|File\(\s*rootDir\s*,\s*)?["']([^"']+)["']`)
+)
+
+// ReadWorkspace maps each included project path to its directory:
+// ":libs:core" is libs/core unless a projectDir assignment moves it, and
+// includeFlat projects are siblings of the root.
+func (r *gradleSettingsReader) ReadWorkspace(filename string, content []byte) (*core.WorkspaceDefinition, error) {
+	dirs := make(map[string]string)
+	var projects []string
+	add := func(project, dir string) {
+		if _, ok := dirs[project]; !ok {
+			projects = append(projects, project)
+		}
+		dirs[project] = dir
+	}
+
+	flat, continued := false, false
+	for _, line := range strings.Split(string(content), "\n") {
+		if i := strings.Index(line, "//"); i >= 0 {
+			line = line[:i]
+		}
+		trimmed := strings.TrimSpace(line)
+
+		if !continued {
+			switch {
+			case hasGradleKeyword(trimmed, "includeFlat"):
+				flat = true
+			case hasGradleKeyword(trimmed, "include"):
+				flat = false
+			default:
+				continue
+			}
+		}
+		for _, m := range gradleQuotedRegex.FindAllStringSubmatch(trimmed, -1) {
+			project := strings.TrimPrefix(m[1], ":")
+			dir := strings.ReplaceAll(project, ":", "/")
+			if flat {
+				dir = "../" + project
+			}
+			add(project, dir)
+		}
+		// Groovy lets a list of projects run on over several lines
+		continued = strings.HasSuffix(trimmed, ",") || strings.HasSuffix(trimmed, "(")
+	}
+
+	for _, m := range gradleProjectDirRegex.FindAllStringSubmatch(string(content), -1) {
+		project := strings.TrimPrefix(m[1], ":")
+		if _, ok := dirs[project]; ok {
+			dirs[project] = m[2]
+		}
+	}
+
+	if len(projects) == 0 {
+		return nil, nil
+	}
+	def := &core.WorkspaceDefinition{Manifests: []string{"build.gradle.kts", "build.gradle"}}
+	for _, project := range projects {
+		def.Members = append(def.Members, dirs[project])
+	}
+	return def, nil
+}
+
+// hasGradleKeyword reports whether a line calls the named function,
+// either as include 'a' or include('a').
+func hasGradleKeyword(line, keyword string) bool {
+	rest, ok := strings.CutPrefix(line, keyword)
+	return ok && (strings.HasPrefix(rest, " ") || strings.HasPrefix(rest, "(") || strings.HasPrefix(rest, "\t"))
+}
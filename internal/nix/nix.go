@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"github.com/git-pkgs/manifests/internal/core"
 	"regexp"
+	"strconv"
 	"strings"
 )
 
@@ -95,10 +96,14 @@ func parseFlakeURL(url string) string {
 type flakeLockParser struct{}
 
 type flakeLock struct {
-	Nodes map[string]flakeLockNode `json:"nodes"`
-	Root  string                   `json:"root"`
+	Nodes   map[string]flakeLockNode `json:"nodes"`
+	Root    string                   `json:"root"`
+	Version int                      `json:"version"`
 }
 
+// latestFlakeLockVersion is the newest flake.lock format version.
+const latestFlakeLockVersion = 7
+
 type flakeLockNode struct {
 	Locked struct {
 		Owner       string `json:"owner"`
@@ -122,6 +127,13 @@ func (p *flakeLockParser) Parse(filename string, content []byte) (*core.Result,
 	if err := json.Unmarshal(content, &lock); err != nil {
 		return nil, &core.ParseError{Filename: filename, Err: err}
 	}
+	formatVersion := ""
+	if lock.Version > 0 {
+		formatVersion = strconv.Itoa(lock.Version)
+	}
+	if err := core.CheckFormatVersion(filename, formatVersion, latestFlakeLockVersion); err != nil {
+		return nil, err
+	}
 
 	var deps []core.Dependency
 
@@ -151,7 +163,7 @@ func (p *flakeLockParser) Parse(filename string, content []byte) (*core.Result,
 		})
 	}
 
-	return &core.Result{Dependencies: deps}, nil
+	return &core.Result{Dependencies: deps, FormatVersion: formatVersion}, nil
 }
 
 // sourcesJSONParser parses niv sources.json files.
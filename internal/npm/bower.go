@@ -13,8 +13,9 @@ func init() {
 type bowerParser struct{}
 
 type bowerJSON struct {
-	Name            string            `json:"name"`
-	Version         string            `json:"version"`
+	Name    string `json:"name"`
+	Version string `json:"version"`
+	metadataFields
 	Dependencies    map[string]string `json:"dependencies"`
 	DevDependencies map[string]string `json:"devDependencies"`
 }
@@ -45,5 +46,5 @@ func (p *bowerParser) Parse(filename string, content []byte) (*core.Result, erro
 		})
 	}
 
-	return &core.Result{Name: bower.Name, Version: bower.Version, Dependencies: deps}, nil
+	return &core.Result{Name: bower.Name, Version: bower.Version, Metadata: bower.metadata(), Dependencies: deps}, nil
 }
@@ -1,7 +1,12 @@
 package npm
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
+	"maps"
+	"slices"
+	"sort"
 	"strings"
 
 	"github.com/git-pkgs/manifests/internal/core"
@@ -22,12 +27,15 @@ func init() {
 type npmPackageJSONParser struct{}
 
 type packageJSON struct {
-	Name                 string         `json:"name"`
-	Version              string         `json:"version"`
+	Name    string `json:"name"`
+	Version string `json:"version"`
+	metadataFields
 	Dependencies         map[string]any `json:"dependencies"`
 	DevDependencies      map[string]any `json:"devDependencies"`
 	OptionalDependencies map[string]any `json:"optionalDependencies"`
 	PeerDependencies     map[string]any `json:"peerDependencies"`
+	Engines              any            `json:"engines"`
+	PackageManager       string         `json:"packageManager"`
 }
 
 func (p *npmPackageJSONParser) Parse(filename string, content []byte) (*core.Result, error) {
@@ -37,6 +45,8 @@ func (p *npmPackageJSONParser) Parse(filename string, content []byte) (*core.Res
 	}
 
 	var deps []core.Dependency
+	var diags []core.Diagnostic
+	locator := core.NewJSONLocator(content)
 
 	for name, value := range pkg.Dependencies {
 		if isNpmComment(name) {
@@ -47,12 +57,19 @@ func (p *npmPackageJSONParser) Parse(filename string, content []byte) (*core.Res
 			continue
 		}
 		realName, realVersion := parseNpmAlias(name, version)
+		pos, raw := locator.Locate("dependencies", name)
 		deps = append(deps, core.Dependency{
-			Name:    realName,
-			Version: realVersion,
-			Scope:   core.Runtime,
-			Direct:  true,
+			Name:      realName,
+			Version:   realVersion,
+			Scope:     core.Runtime,
+			Direct:    true,
+			Position:  pos,
+			Raw:       raw,
+			Workspace: isWorkspaceProtocol(version),
 		})
+		if d, ok := nonRegistryDiagnostic(pos.StartLine, name, version); ok {
+			diags = append(diags, d)
+		}
 	}
 
 	for name, value := range pkg.DevDependencies {
@@ -64,12 +81,19 @@ func (p *npmPackageJSONParser) Parse(filename string, content []byte) (*core.Res
 			continue
 		}
 		realName, realVersion := parseNpmAlias(name, version)
+		pos, raw := locator.Locate("devDependencies", name)
 		deps = append(deps, core.Dependency{
-			Name:    realName,
-			Version: realVersion,
-			Scope:   core.Development,
-			Direct:  true,
+			Name:      realName,
+			Version:   realVersion,
+			Scope:     core.Development,
+			Direct:    true,
+			Position:  pos,
+			Raw:       raw,
+			Workspace: isWorkspaceProtocol(version),
 		})
+		if d, ok := nonRegistryDiagnostic(pos.StartLine, name, version); ok {
+			diags = append(diags, d)
+		}
 	}
 
 	for name, value := range pkg.OptionalDependencies {
@@ -81,12 +105,19 @@ func (p *npmPackageJSONParser) Parse(filename string, content []byte) (*core.Res
 			continue
 		}
 		realName, realVersion := parseNpmAlias(name, version)
+		pos, raw := locator.Locate("optionalDependencies", name)
 		deps = append(deps, core.Dependency{
-			Name:    realName,
-			Version: realVersion,
-			Scope:   core.Optional,
-			Direct:  true,
+			Name:      realName,
+			Version:   realVersion,
+			Scope:     core.Optional,
+			Direct:    true,
+			Position:  pos,
+			Raw:       raw,
+			Workspace: isWorkspaceProtocol(version),
 		})
+		if d, ok := nonRegistryDiagnostic(pos.StartLine, name, version); ok {
+			diags = append(diags, d)
+		}
 	}
 
 	for name, value := range pkg.PeerDependencies {
@@ -98,15 +129,176 @@ func (p *npmPackageJSONParser) Parse(filename string, content []byte) (*core.Res
 			continue
 		}
 		realName, realVersion := parseNpmAlias(name, version)
+		pos, raw := locator.Locate("peerDependencies", name)
 		deps = append(deps, core.Dependency{
-			Name:    realName,
-			Version: realVersion,
-			Scope:   core.Runtime, // peer dependencies are runtime requirements
-			Direct:  true,
+			Name:      realName,
+			Version:   realVersion,
+			Scope:     core.Runtime, // peer dependencies are runtime requirements
+			Direct:    true,
+			Position:  pos,
+			Raw:       raw,
+			Workspace: isWorkspaceProtocol(version),
 		})
+		if d, ok := nonRegistryDiagnostic(pos.StartLine, name, version); ok {
+			diags = append(diags, d)
+		}
+	}
+
+	return &core.Result{
+		Name:         pkg.Name,
+		Version:      pkg.Version,
+		Metadata:     pkg.metadata(),
+		Dependencies: deps,
+		Runtimes:     pkg.runtimes(),
+		Diagnostics:  diags,
+	}, nil
+}
+
+// EditVersion rewrites the range in a dependency's value string. For an
+// alias ("npm:name@range") only the range after the "@" changes.
+func (p *npmPackageJSONParser) EditVersion(content []byte, decl core.Span, dep *core.Dependency, version string) (core.Edit, bool, error) {
+	key, _, ok := core.StringLiteralAt(content, decl.Start)
+	if !ok {
+		return core.Edit{}, false, nil
 	}
+	colon := bytes.IndexByte(content[key.End+1:decl.End], ':')
+	if colon < 0 {
+		return core.Edit{}, false, nil
+	}
+	value, _, ok := core.StringLiteralAt(content, skipJSONSpace(content, key.End+1+colon+1))
+	if !ok {
+		return core.Edit{}, false, nil
+	}
+	text := content[value.Start:value.End]
+	if (dep.Version == "" && len(text) > 0) || !bytes.HasSuffix(text, []byte(dep.Version)) {
+		return core.Edit{}, false, nil
+	}
+	encoded, err := core.QuotedVersion(version, '"')
+	if err != nil {
+		return core.Edit{}, false, err
+	}
+	return core.Edit{Span: core.Span{Start: value.End - len(dep.Version), End: value.End}, Text: encoded}, true, nil
+}
 
-	return &core.Result{Name: pkg.Name, Version: pkg.Version, Dependencies: deps}, nil
+// skipJSONSpace returns the offset of the first non-whitespace byte at
+// or after i.
+func skipJSONSpace(content []byte, i int) int {
+	for i < len(content) && strings.IndexByte(" \t\r\n", content[i]) >= 0 {
+		i++
+	}
+	return i
+}
+
+// runtimes returns the "engines" requirements in name order, followed by
+// the package manager Corepack pins ("pnpm@8.6.0+sha512.abc" is pnpm
+// 8.6.0).
+func (pkg *packageJSON) runtimes() []core.Requirement {
+	var runtimes []core.Requirement
+	// Very old packages wrote engines as an array of strings, which no
+	// tool reads any more
+	if engines, ok := pkg.Engines.(map[string]any); ok {
+		for _, name := range slices.Sorted(maps.Keys(engines)) {
+			if version, ok := engines[name].(string); ok {
+				runtimes = append(runtimes, core.Requirement{Name: name, Version: version, Source: "engines"})
+			}
+		}
+	}
+	if name, version, ok := strings.Cut(pkg.PackageManager, "@"); ok && name != "" {
+		version, _, _ = strings.Cut(version, "+")
+		runtimes = append(runtimes, core.Requirement{Name: name, Version: version, Source: "packageManager"})
+	}
+	return runtimes
+}
+
+// metadataFields holds the package.json and bower.json fields describing
+// the package. They're decoded loosely since both registries accept
+// strings, objects or arrays for most of them.
+type metadataFields struct {
+	Description any `json:"description"`
+	License     any `json:"license"`
+	Licenses    any `json:"licenses"`
+	Homepage    any `json:"homepage"`
+	Repository  any `json:"repository"`
+	Author      any `json:"author"`
+	Authors     any `json:"authors"`
+	Keywords    any `json:"keywords"`
+}
+
+func (f *metadataFields) metadata() core.Metadata {
+	m := core.Metadata{
+		Description: jsonField(f.Description, ""),
+		License:     npmLicense(f.License, f.Licenses),
+		Homepage:    jsonField(f.Homepage, ""),
+		Repository:  jsonField(f.Repository, "url"),
+		Keywords:    jsonStrings(f.Keywords, ""),
+	}
+	if author := npmPerson(f.Author); author != "" {
+		m.Authors = append(m.Authors, author)
+	}
+	if list, ok := f.Authors.([]any); ok {
+		for _, a := range list {
+			if author := npmPerson(a); author != "" {
+				m.Authors = append(m.Authors, author)
+			}
+		}
+	}
+	return m
+}
+
+// npmLicense reads a license given as a string, as {"type": "MIT"}, or
+// as a list of alternatives: the deprecated licenses array, or a license
+// array as bower.json allows.
+func npmLicense(license, licenses any) string {
+	if l := jsonField(license, "type"); l != "" {
+		return l
+	}
+	list := jsonStrings(licenses, "type")
+	if list == nil {
+		list = jsonStrings(license, "type")
+	}
+	return core.JoinLicenses("OR", list...)
+}
+
+// jsonField returns v when it's a string, or the key member of v when
+// it's an object such as {"type": "MIT"} or {"url": "..."}.
+func jsonField(v any, key string) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case map[string]any:
+		if key != "" {
+			s, _ := v[key].(string)
+			return s
+		}
+	}
+	return ""
+}
+
+// jsonStrings applies jsonField to each element of an array.
+func jsonStrings(v any, key string) []string {
+	list, _ := v.([]any)
+	var values []string
+	for _, item := range list {
+		if s := jsonField(item, key); s != "" {
+			values = append(values, s)
+		}
+	}
+	return values
+}
+
+// npmPerson formats an author given as {"name", "email"} or as the
+// "Name <email> (url)" shorthand, dropping the url.
+func npmPerson(v any) string {
+	if m, ok := v.(map[string]any); ok {
+		name, _ := m["name"].(string)
+		email, _ := m["email"].(string)
+		return core.Author(name, email)
+	}
+	s, _ := v.(string)
+	if i := strings.Index(s, " ("); i > 0 && strings.HasSuffix(s, ")") {
+		s = s[:i]
+	}
+	return strings.TrimSpace(s)
 }
 
 // isNpmComment checks if a dependency name is actually a comment.
@@ -115,6 +307,35 @@ func isNpmComment(name string) bool {
 	return strings.HasPrefix(name, "//")
 }
 
+// isWorkspaceProtocol reports whether a requirement uses the
+// "workspace:" protocol of pnpm, Yarn and Bun, which always resolves to
+// another member of the workspace.
+func isWorkspaceProtocol(version string) bool {
+	return strings.HasPrefix(version, "workspace:")
+}
+
+// nonRegistrySpecPrefixes start requirements that install a package from
+// somewhere other than the registry.
+var nonRegistrySpecPrefixes = []string{"file:", "link:", "portal:", "git:", "git+", "github:", "gitlab:", "bitbucket:", "gist:", "http:", "https:"}
+
+// nonRegistryDiagnostic warns about a requirement naming a path, URL or
+// git repository, including the "user/repo" GitHub shorthand, whose
+// version the registry can't tell.
+func nonRegistryDiagnostic(line int, name, version string) (core.Diagnostic, bool) {
+	if strings.HasPrefix(version, "npm:") || isWorkspaceProtocol(version) {
+		return core.Diagnostic{}, false
+	}
+	for _, prefix := range nonRegistrySpecPrefixes {
+		if strings.HasPrefix(version, prefix) {
+			return core.Warning(line, core.CodeNonRegistrySource, "%s is installed from %s, not the registry", name, version), true
+		}
+	}
+	if strings.Contains(version, "/") {
+		return core.Warning(line, core.CodeNonRegistrySource, "%s is installed from %s, not the registry", name, version), true
+	}
+	return core.Diagnostic{}, false
+}
+
 // parseNpmAlias handles npm alias syntax: "alias-name": "npm:@scope/real-name@version"
 func parseNpmAlias(name, version string) (string, string) {
 	if strings.HasPrefix(version, "npm:") {
@@ -131,10 +352,8 @@ func parseNpmAlias(name, version string) (string, string) {
 // npmPackageLockParser parses package-lock.json files.
 type npmPackageLockParser struct{}
 
-// packageLockJSON supports both v1/v2 and v3 lockfile formats.
+// packageLockJSON is the v1 lockfile format.
 type packageLockJSON struct {
-	LockfileVersion int `json:"lockfileVersion"`
-	// v1/v2 format
 	Dependencies map[string]packageLockDep `json:"dependencies"`
 }
 
@@ -144,33 +363,136 @@ type packageLockDep struct {
 	Integrity    string                    `json:"integrity"`
 	Dev          bool                      `json:"dev"`
 	Optional     bool                      `json:"optional"`
+	Requires     packageLockRequires       `json:"requires"`
 	Dependencies map[string]packageLockDep `json:"dependencies"`
 }
 
+// packageLockRequires is the v1 "requires" map of name to range. Some
+// writers emit a bare boolean here, which is ignored.
+type packageLockRequires map[string]string
+
+func (r *packageLockRequires) UnmarshalJSON(data []byte) error {
+	var m map[string]string
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil //nolint:nilerr // non-object requires carries no names
+	}
+	*r = m
+	return nil
+}
+
+// latestPackageLockVersion is the newest lockfileVersion npm writes.
+const latestPackageLockVersion = 3
+
 func (p *npmPackageLockParser) Parse(filename string, content []byte) (*core.Result, error) {
-	// Quick check for lockfile version to determine parsing strategy
-	// v3 (lockfileVersion >= 2 with packages) uses line-based parsing
-	// v1 uses JSON parsing for nested dependencies
-	const headerPeekSize = 200
-	const packagesPeekSize = 600
-	header := string(content[:min(headerPeekSize, len(content))])
+	return p.ParseLimited(context.Background(), filename, content, core.Limits{})
+}
+
+func (p *npmPackageLockParser) ParseLimited(ctx context.Context, filename string, content []byte, limits core.Limits) (*core.Result, error) {
+	version, ok := packageLockVersion(content)
+	if err := core.CheckFormatVersion(filename, version, latestPackageLockVersion); err != nil {
+		return nil, err
+	}
 
-	// v2+ with packages section uses line-based v3 parsing
-	if strings.Contains(header, `"lockfileVersion": 3`) ||
-		(strings.Contains(header, `"lockfileVersion": 2`) && strings.Contains(string(content[:min(packagesPeekSize, len(content))]), `"packages"`)) {
-		return &core.Result{Dependencies: parsePackageLockV3Lines(content)}, nil
+	// v2 carries both a packages section and the v1 dependencies tree;
+	// v3 only the former. The packages section is flat, so it's read
+	// line by line.
+	if version == "3" || (version == "2" && bytes.Contains(content, []byte(`"packages"`))) {
+		deps, graph, err := parsePackageLockV3Lines(ctx, filename, content, limits)
+		if err != nil {
+			return nil, err
+		}
+		return &core.Result{Dependencies: deps, Graph: graph, FormatVersion: version}, nil
 	}
 
-	// v1 format uses JSON (nested dependencies make line parsing complex)
+	// v1 format uses JSON (nested dependencies make line parsing complex).
+	// Each level of nesting is a node_modules directory, so bound the
+	// depth before building the tree.
+	if err := core.CheckJSONDepth(filename, content, limits.MaxDepth); err != nil {
+		return nil, err
+	}
 	var lock packageLockJSON
 	if err := json.Unmarshal(content, &lock); err != nil {
 		return nil, &core.ParseError{Filename: filename, Err: err}
 	}
-	return &core.Result{Dependencies: parsePackageLockV1(lock.Dependencies)}, nil
+	var diags []core.Diagnostic
+	if !ok {
+		// npm-shrinkwrap.json from before npm 5 has no lockfileVersion
+		diags = append(diags, core.Info(0, core.CodeUnknownFormatVersion, "no lockfileVersion, read as version 1"))
+	}
+	deps, err := parsePackageLockV1(ctx, filename, lock.Dependencies, limits, nil)
+	if err != nil {
+		return nil, err
+	}
+	graph := core.NewGraphBuilder()
+	addPackageLockV1Edges(graph, lock.Dependencies, nil)
+	return &core.Result{Dependencies: deps, Graph: graph.Graph(), Diagnostics: diags, FormatVersion: version}, nil
 }
 
-func parsePackageLockV1(deps map[string]packageLockDep) []core.Dependency {
-	var result []core.Dependency
+// packageLockVersion reads lockfileVersion without decoding the whole
+// file. npm writes it ahead of the packages and dependencies sections,
+// so the first occurrence of the key is the top-level one.
+func packageLockVersion(content []byte) (string, bool) {
+	const key = `"lockfileVersion"`
+	i := bytes.Index(content, []byte(key))
+	if i < 0 {
+		return "", false
+	}
+	rest := bytes.TrimLeft(content[i+len(key):], " \t\r\n")
+	rest, ok := bytes.CutPrefix(rest, []byte(":"))
+	if !ok {
+		return "", false
+	}
+	rest = bytes.TrimLeft(rest, " \t\r\n")
+	n := 0
+	for n < len(rest) && rest[n] >= '0' && rest[n] <= '9' {
+		n++
+	}
+	return string(rest[:n]), n > 0
+}
+
+// addPackageLockV1Edges links each entry to the packages named in its
+// requires map. A name resolves to the entry's own nested dependencies
+// first, then to each enclosing level out to the top. v1 lockfiles don't
+// record the project's own requirements, so no roots are added.
+func addPackageLockV1Edges(graph *core.GraphBuilder, deps map[string]packageLockDep, scopes []map[string]packageLockDep) {
+	scopes = append(scopes[:len(scopes):len(scopes)], deps)
+	for _, name := range sortedKeys(deps) {
+		dep := deps[name]
+		from := core.Node{Name: name, Version: dep.Version}
+		graph.AddNode(from)
+
+		lookup := scopes
+		if len(dep.Dependencies) > 0 {
+			lookup = append(scopes[:len(scopes):len(scopes)], dep.Dependencies)
+		}
+		for _, req := range sortedKeys(dep.Requires) {
+			for i := len(lookup) - 1; i >= 0; i-- {
+				if target, ok := lookup[i][req]; ok {
+					graph.AddEdge(from, core.Node{Name: req, Version: target.Version})
+					break
+				}
+			}
+		}
+
+		if len(dep.Dependencies) > 0 {
+			addPackageLockV1Edges(graph, dep.Dependencies, scopes)
+		}
+	}
+}
+
+// sortedKeys returns the keys of m in sorted order.
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// parsePackageLockV1 appends deps and everything nested in them to
+// result.
+func parsePackageLockV1(ctx context.Context, filename string, deps map[string]packageLockDep, limits core.Limits, result []core.Dependency) ([]core.Dependency, error) {
 	for name, dep := range deps {
 		scope := core.Runtime
 		if dep.Dev {
@@ -187,14 +509,19 @@ func parsePackageLockV1(deps map[string]packageLockDep) []core.Dependency {
 			Direct:      false,
 			RegistryURL: dep.Resolved,
 		})
+		if err := limits.Check(ctx, filename, len(result)); err != nil {
+			return nil, err
+		}
 
 		// Recursively add nested dependencies
 		if len(dep.Dependencies) > 0 {
-			nested := parsePackageLockV1(dep.Dependencies)
-			result = append(result, nested...)
+			var err error
+			if result, err = parsePackageLockV1(ctx, filename, dep.Dependencies, limits, result); err != nil {
+				return nil, err
+			}
 		}
 	}
-	return result
+	return result, nil
 }
 
 // v3PackageEntry holds the state accumulated while parsing a single package
@@ -204,10 +531,12 @@ type v3PackageEntry struct {
 	version     string
 	integrity   string
 	resolved    string
+	license     string
 	dev         bool
 	optional    bool
 	devOptional bool
 	link        bool
+	requires    []string
 }
 
 func (e *v3PackageEntry) reset(path string) {
@@ -215,10 +544,12 @@ func (e *v3PackageEntry) reset(path string) {
 	e.version = ""
 	e.integrity = ""
 	e.resolved = ""
+	e.license = ""
 	e.dev = false
 	e.optional = false
 	e.devOptional = false
 	e.link = false
+	e.requires = nil
 }
 
 func (e *v3PackageEntry) hasContent() bool {
@@ -244,6 +575,7 @@ func (e *v3PackageEntry) toDependency() (core.Dependency, bool) {
 		Integrity:   e.integrity,
 		Direct:      direct,
 		RegistryURL: e.resolved,
+		License:     e.license,
 	}, true
 }
 
@@ -263,6 +595,10 @@ func (e *v3PackageEntry) updateFromLine(trimmed string) bool {
 		if v := extractJSONStringValue(trimmed); v != "" {
 			e.resolved = v
 		}
+	case strings.HasPrefix(trimmed, `"license":`):
+		if v := extractJSONStringValue(trimmed); v != "" {
+			e.license = v
+		}
 	case strings.HasPrefix(trimmed, `"dev": true`):
 		e.dev = true
 	case strings.HasPrefix(trimmed, `"optional": true`):
@@ -300,15 +636,42 @@ func isPackagesSectionEnd(line, trimmed string) bool {
 	return (line == "  }," || line == "  }") && strings.HasPrefix(trimmed, "}")
 }
 
+// isRequiresBlock reports whether a nested object key lists a package's
+// requirements, as opposed to engines, funding, bin and so on.
+func isRequiresBlock(trimmed string) bool {
+	switch extractQuotedPath(trimmed) {
+	case "dependencies", "devDependencies", "optionalDependencies", "peerDependencies":
+		return true
+	}
+	return false
+}
+
 // parsePackageLockV3Lines parses v3 format using line-based parsing.
 // Format: "packages": { "node_modules/name": { "version": "x", ... } }
-func parsePackageLockV3Lines(content []byte) []core.Dependency {
+func parsePackageLockV3Lines(ctx context.Context, filename string, content []byte, limits core.Limits) ([]core.Dependency, *core.Graph, error) {
 	var deps []core.Dependency
+	var entries []v3PackageEntry
 	lines := strings.Split(string(content), "\n")
 
 	inPackages := false
+	inRequires := false
+	// depth counts open objects inside the current package entry; 0
+	// means we're between entries.
+	depth := 0
 	var entry v3PackageEntry
 
+	flush := func() {
+		if entry.hasContent() {
+			if dep, ok := entry.toDependency(); ok {
+				deps = append(deps, dep)
+			}
+		}
+		if entry.path == "" && len(entry.requires) == 0 {
+			return
+		}
+		entries = append(entries, entry)
+	}
+
 	for _, line := range lines {
 		trimmed := strings.TrimSpace(line)
 
@@ -319,31 +682,99 @@ func parsePackageLockV3Lines(content []byte) []core.Dependency {
 			continue
 		}
 
-		if isPackagesSectionEnd(line, trimmed) {
-			break
-		}
-
-		if isPackagePathLine(trimmed) {
-			if entry.hasContent() {
-				if dep, ok := entry.toDependency(); ok {
-					deps = append(deps, dep)
+		if depth == 0 {
+			if isPackagesSectionEnd(line, trimmed) {
+				break
+			}
+			if isPackagePathLine(trimmed) {
+				flush()
+				if err := limits.Check(ctx, filename, len(deps)); err != nil {
+					return nil, nil, err
 				}
+				entry.reset(extractQuotedPath(trimmed))
+				depth = 1
 			}
-			entry.reset(extractQuotedPath(trimmed))
 			continue
 		}
 
-		entry.updateFromLine(trimmed)
+		switch {
+		case strings.HasPrefix(trimmed, "}"):
+			depth--
+			inRequires = false
+		case strings.HasSuffix(trimmed, "{"):
+			depth++
+			inRequires = depth == 2 && isRequiresBlock(trimmed)
+		case depth == 1:
+			entry.updateFromLine(trimmed)
+		case inRequires:
+			if name := extractQuotedPath(trimmed); name != "" {
+				entry.requires = append(entry.requires, name)
+			}
+		}
 	}
 
 	// Don't forget the last package
-	if entry.hasContent() {
-		if dep, ok := entry.toDependency(); ok {
-			deps = append(deps, dep)
+	flush()
+	if err := limits.Check(ctx, filename, len(deps)); err != nil {
+		return nil, nil, err
+	}
+
+	return deps, buildPackageLockV3Graph(entries), nil
+}
+
+// buildPackageLockV3Graph resolves each entry's requirements the way
+// node does: look in the entry's own node_modules, then each enclosing
+// node_modules up to the top level. Requirements of entries outside
+// node_modules (the root project and workspace members) become roots.
+func buildPackageLockV3Graph(entries []v3PackageEntry) *core.Graph {
+	nodes := make(map[string]core.Node, len(entries))
+	for _, e := range entries {
+		if e.version == "" || !strings.Contains(e.path, "node_modules/") {
+			continue
 		}
+		nodes[e.path] = core.Node{Name: extractPackageName(e.path), Version: e.version}
 	}
 
-	return deps
+	graph := core.NewGraphBuilder()
+	for _, e := range entries {
+		from, isPackage := nodes[e.path]
+		if isPackage {
+			graph.AddNode(from)
+		}
+		for _, name := range e.requires {
+			to, ok := resolveNodeModule(nodes, e.path, name)
+			if !ok {
+				continue
+			}
+			if isPackage {
+				graph.AddEdge(from, to)
+			} else if !strings.Contains(e.path, "node_modules/") {
+				graph.AddRoot(to)
+			}
+		}
+	}
+	return graph.Graph()
+}
+
+// resolveNodeModule finds the installed copy of name visible from path.
+func resolveNodeModule(nodes map[string]core.Node, path, name string) (core.Node, bool) {
+	for {
+		candidate := "node_modules/" + name
+		if path != "" {
+			candidate = path + "/" + candidate
+		}
+		if n, ok := nodes[candidate]; ok {
+			return n, true
+		}
+		if path == "" {
+			return core.Node{}, false
+		}
+		if idx := strings.LastIndex(path, "/node_modules/"); idx >= 0 {
+			path = path[:idx]
+		} else {
+			path = ""
+		}
+	}
 }
 
 // extractJSONStringValue extracts the string value from a JSON line like: "key": "value"
@@ -408,6 +839,10 @@ type npmLsDep struct {
 	Integrity    string              `json:"integrity"`
 	Dev          bool                `json:"dev"`
 	Dependencies map[string]npmLsDep `json:"dependencies"`
+	// License and Licenses are only present with npm ls --long, which
+	// includes each package's package.json fields.
+	License  any `json:"license"`
+	Licenses any `json:"licenses"`
 }
 
 func (p *npmLsParser) Parse(filename string, content []byte) (*core.Result, error) {
@@ -440,6 +875,7 @@ func parseNpmLsDeps(deps map[string]npmLsDep, seen map[string]bool) []core.Depen
 			Integrity:   dep.Integrity,
 			Direct:      false,
 			RegistryURL: dep.Resolved,
+			License:     npmLicense(dep.License, dep.Licenses),
 		})
 
 		// Recursively add nested dependencies
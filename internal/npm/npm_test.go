@@ -1,7 +1,10 @@
 package npm
 
 import (
+	"context"
+	"errors"
 	"os"
+	"slices"
 	"testing"
 
 	"github.com/git-pkgs/manifests/internal/core"
@@ -212,6 +215,10 @@ func TestNpmPackageLockV3(t *testing.T) {
 			t.Errorf("expected %s dependency", name)
 		}
 	}
+
+	if got := depMap["alias-package-name"].License; got != "MIT" {
+		t.Errorf("alias-package-name license = %q, want MIT", got)
+	}
 }
 
 func TestParseNpmAlias(t *testing.T) {
@@ -318,6 +325,103 @@ func TestYarnLock(t *testing.T) {
 	}
 }
 
+func TestYarnLockDiagnostics(t *testing.T) {
+	content := []byte(`# yarn lockfile v1
+
+
+lodash@^4.17.21:
+  version "4.17.21"
+
+"left-pad@^1.3.0":
+  resolved "https://registry.yarnpkg.com/left-pad/-/left-pad-1.3.0.tgz"
+
+garbage line
+  version "1.0.0"
+`)
+	res, err := (&yarnLockParser{}).Parse("yarn.lock", content)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if len(res.Dependencies) != 1 || res.Dependencies[0].Name != "lodash" {
+		t.Errorf("expected only lodash, got %+v", res.Dependencies)
+	}
+	if len(res.Diagnostics) != 2 {
+		t.Fatalf("expected 2 diagnostics, got %+v", res.Diagnostics)
+	}
+	if d := res.Diagnostics[0]; d.Line != 7 || d.Code != core.CodeUnrecognisedLine {
+		t.Errorf("unexpected diagnostic %+v", d)
+	}
+	if d := res.Diagnostics[1]; d.Line != 10 || d.Code != core.CodeUnrecognisedLine {
+		t.Errorf("unexpected diagnostic %+v", d)
+	}
+}
+
+func TestNpmFormatVersion(t *testing.T) {
+	tests := []struct {
+		name     string
+		parser   core.Parser
+		filename string
+		content  string
+		want     string
+		deps     int
+	}{
+		// No space after the colon, which the old header sniffing read
+		// as version 1
+		{"package-lock v3", &npmPackageLockParser{}, "package-lock.json", "{\n  \"name\": \"app\",\n  \"lockfileVersion\":3,\n  \"packages\": {\n    \"\": {\n      \"name\": \"app\"\n    },\n    \"node_modules/lodash\": {\n      \"version\": \"4.17.21\"\n    }\n  }\n}\n", "3", 1},
+		{"package-lock v1", &npmPackageLockParser{}, "package-lock.json", `{"lockfileVersion": 1, "dependencies": {"lodash": {"version": "4.17.21"}}}`, "1", 1},
+		{"yarn classic", &yarnLockParser{}, "yarn.lock", "# yarn lockfile v1\n\nlodash@^4.17.0:\n  version \"4.17.21\"\n", "1", 1},
+		{"yarn berry", &yarnLockParser{}, "yarn.lock", "__metadata:\n  version: 8\n  cacheKey: 10c0\n\n\"lodash@npm:^4.17.0\":\n  version: 4.17.21\n", "8", 1},
+		{"pnpm", &pnpmLockParser{}, "pnpm-lock.yaml", "lockfileVersion: '9.0'\n\npackages:\n\n  lodash@4.17.21:\n    resolution: {integrity: sha512-abc}\n", "9.0", 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := tt.parser.Parse(tt.filename, []byte(tt.content))
+			if err != nil {
+				t.Fatalf("Parse failed: %v", err)
+			}
+			if res.FormatVersion != tt.want {
+				t.Errorf("FormatVersion = %q, want %q", res.FormatVersion, tt.want)
+			}
+			if len(res.Dependencies) != tt.deps {
+				t.Errorf("expected %d dependencies, got %+v", tt.deps, res.Dependencies)
+			}
+		})
+	}
+}
+
+func TestNpmUnsupportedFormatVersion(t *testing.T) {
+	tests := []struct {
+		parser   core.Parser
+		filename string
+		content  string
+	}{
+		{&npmPackageLockParser{}, "package-lock.json", `{"name":"app","lockfileVersion":4,"packages":{"node_modules/lodash":{"version":"4.17.21"}}}`},
+		{&yarnLockParser{}, "yarn.lock", "__metadata:\n  version: 9\n"},
+		{&pnpmLockParser{}, "pnpm-lock.yaml", "lockfileVersion: '10.0'\n"},
+	}
+	for _, tt := range tests {
+		_, err := tt.parser.Parse(tt.filename, []byte(tt.content))
+		var unsupported *core.UnsupportedFormatVersionError
+		if !errors.As(err, &unsupported) {
+			t.Errorf("%s: expected UnsupportedFormatVersionError, got %v", tt.filename, err)
+		}
+	}
+}
+
+func TestNpmPackageLockMissingVersion(t *testing.T) {
+	content := []byte(`{"name":"app","dependencies":{"lodash":{"version":"4.17.21"}}}`)
+	res, err := (&npmPackageLockParser{}).Parse("package-lock.json", content)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if len(res.Dependencies) != 1 {
+		t.Errorf("expected 1 dependency, got %d", len(res.Dependencies))
+	}
+	if len(res.Diagnostics) != 1 || res.Diagnostics[0].Code != core.CodeUnknownFormatVersion {
+		t.Errorf("unexpected diagnostics %+v", res.Diagnostics)
+	}
+}
+
 func TestPnpmLock(t *testing.T) {
 	content, err := os.ReadFile("../../testdata/npm/pnpm-lock.yaml")
 	if err != nil {
@@ -743,6 +847,132 @@ func TestPnpmLockV9(t *testing.T) {
 	}
 }
 
+func TestPnpmLockGraph(t *testing.T) {
+	tests := []struct {
+		fixture string
+		roots   []core.Node
+		edge    core.Edge
+		path    string
+	}{
+		{
+			fixture: "pnpm-lock.yaml",
+			roots:   []core.Node{{Name: "chalk", Version: "1.1.3"}},
+			edge:    core.Edge{From: core.Node{Name: "chalk", Version: "1.1.3"}, To: core.Node{Name: "has-ansi", Version: "2.0.0"}},
+			path:    "ansi-regex",
+		},
+		{
+			fixture: "pnpm-lockfile-version-6/pnpm-lock.yaml",
+			roots: []core.Node{
+				{Name: "@babel/types", Version: "7.28.1"},
+				{Name: "zod", Version: "4.0.5"},
+				{Name: "mocha", Version: "2.5.3"},
+			},
+			edge: core.Edge{From: core.Node{Name: "mocha", Version: "2.5.3"}, To: core.Node{Name: "debug", Version: "2.2.0"}},
+			path: "ms",
+		},
+		{
+			fixture: "pnpm-lockfile-version-9/pnpm-lock.yaml",
+			roots: []core.Node{
+				{Name: "@babel/types", Version: "7.28.1"},
+				{Name: "zod", Version: "3.24.2"},
+				{Name: "mocha", Version: "2.5.3"},
+			},
+			edge: core.Edge{From: core.Node{Name: "mocha", Version: "2.5.3"}, To: core.Node{Name: "debug", Version: "2.2.0"}},
+			path: "ms",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			content, err := os.ReadFile("../../testdata/npm/" + tt.fixture)
+			if err != nil {
+				t.Fatalf("failed to read fixture: %v", err)
+			}
+
+			res, err := (&pnpmLockParser{}).Parse("pnpm-lock.yaml", content)
+			if err != nil {
+				t.Fatalf("Parse failed: %v", err)
+			}
+			if res.Graph == nil {
+				t.Fatal("expected a graph")
+			}
+
+			roots := make(map[core.Node]bool)
+			for _, r := range res.Graph.Roots {
+				roots[r] = true
+			}
+			for _, want := range tt.roots {
+				if !roots[want] {
+					t.Errorf("expected root %v, got %v", want, res.Graph.Roots)
+				}
+			}
+
+			found := false
+			for _, e := range res.Graph.Edges {
+				if e == tt.edge {
+					found = true
+					break
+				}
+			}
+			if !found {
+				t.Errorf("expected edge %v", tt.edge)
+			}
+
+			path := res.Graph.Path(tt.path, "")
+			if len(path) < 2 {
+				t.Fatalf("Path(%s) = %v, want a chain from a root", tt.path, path)
+			}
+			if !roots[path[0]] {
+				t.Errorf("Path starts at %v, which is not a root", path[0])
+			}
+		})
+	}
+}
+
+func TestPackageLockGraph(t *testing.T) {
+	tests := []struct {
+		fixture string
+		roots   int
+	}{
+		// v1 has no record of the project's own requirements
+		{"npm-lockfile-version-1/package-lock.json", 0},
+		{"npm-lockfile-version-3/package-lock.json", 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			content, err := os.ReadFile("../../testdata/npm/" + tt.fixture)
+			if err != nil {
+				t.Fatalf("failed to read fixture: %v", err)
+			}
+
+			res, err := (&npmPackageLockParser{}).Parse("package-lock.json", content)
+			if err != nil {
+				t.Fatalf("Parse failed: %v", err)
+			}
+			if res.Graph == nil {
+				t.Fatal("expected a graph")
+			}
+			if len(res.Graph.Roots) != tt.roots {
+				t.Errorf("roots = %v, want %d", res.Graph.Roots, tt.roots)
+			}
+
+			// find-versions requires its own nested semver-regex, not the
+			// hoisted 4.0.5 copy
+			children := res.Graph.Children(core.Node{Name: "find-versions", Version: "4.0.0"})
+			want := core.Node{Name: "semver-regex", Version: "3.1.4"}
+			if len(children) != 1 || children[0] != want {
+				t.Errorf("find-versions children = %v, want [%v]", children, want)
+			}
+
+			path := res.Graph.Path("semver-regex", "3.1.4")
+			if len(path) != 2 || path[0].Name != "find-versions" {
+				t.Errorf("Path(semver-regex@3.1.4) = %v", path)
+			}
+		})
+	}
+}
+
 func TestYarnWithGitRepo(t *testing.T) {
 	content, err := os.ReadFile("../../testdata/npm/yarn-with-git-repo/yarn.lock")
 	if err != nil {
@@ -1096,3 +1326,190 @@ func TestYarnV4Lock(t *testing.T) {
 		t.Error("workspace package should be excluded")
 	}
 }
+
+func TestNpmPackageJSONMetadata(t *testing.T) {
+	content := []byte(`{
+  "name": "app",
+  "description": "An app",
+  "licenses": [{"type": "MIT"}, {"type": "Apache-2.0"}],
+  "repository": {"type": "git", "url": "git+https://github.com/acme/app.git"},
+  "author": {"name": "Jane Doe", "email": "jane@example.com"},
+  "keywords": ["one", 2, "three"]
+}`)
+	res, err := (&npmPackageJSONParser{}).Parse("package.json", content)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	m := res.Metadata
+	if m.License != "MIT OR Apache-2.0" {
+		t.Errorf("License = %q", m.License)
+	}
+	if m.Repository != "git+https://github.com/acme/app.git" {
+		t.Errorf("Repository = %q", m.Repository)
+	}
+	if len(m.Authors) != 1 || m.Authors[0] != "Jane Doe <jane@example.com>" {
+		t.Errorf("Authors = %q", m.Authors)
+	}
+	if len(m.Keywords) != 2 || m.Keywords[1] != "three" {
+		t.Errorf("Keywords = %q", m.Keywords)
+	}
+
+	// The shorthand author form drops the URL
+	content = []byte(`{"name": "app", "author": "Jane Doe <jane@example.com> (https://example.com)"}`)
+	res, err = (&npmPackageJSONParser{}).Parse("package.json", content)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if len(res.Metadata.Authors) != 1 || res.Metadata.Authors[0] != "Jane Doe <jane@example.com>" {
+		t.Errorf("Authors = %q", res.Metadata.Authors)
+	}
+}
+
+func TestNpmLsLongLicenses(t *testing.T) {
+	// npm ls --json --long includes each package's license
+	content := []byte(`{
+  "name": "app",
+  "dependencies": {
+    "a": {"version": "1.0.0", "license": "ISC"},
+    "b": {"version": "2.0.0", "license": {"type": "BSD-3-Clause"}},
+    "c": {"version": "3.0.0", "licenses": [{"type": "MIT"}, {"type": "Apache-2.0"}]},
+    "d": {"version": "4.0.0"}
+  }
+}`)
+	res, err := (&npmLsParser{}).Parse("npm-ls.json", content)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	want := map[string]string{"a": "ISC", "b": "BSD-3-Clause", "c": "MIT OR Apache-2.0", "d": ""}
+	for _, dep := range res.Dependencies {
+		if dep.License != want[dep.Name] {
+			t.Errorf("%s license = %q, want %q", dep.Name, dep.License, want[dep.Name])
+		}
+	}
+}
+
+func TestWorkspaceReaders(t *testing.T) {
+	tests := []struct {
+		name    string
+		reader  core.WorkspaceReader
+		content string
+		members []string
+		exclude []string
+	}{
+		{"package.json", &npmPackageJSONParser{}, `{"workspaces": ["packages/*", "!packages/old"]}`, []string{"packages/*"}, []string{"packages/old"}},
+		{"package.json", &npmPackageJSONParser{}, `{"workspaces": {"packages": ["apps/*"], "nohoist": ["**/rn"]}}`, []string{"apps/*"}, nil},
+		{"package.json", &npmPackageJSONParser{}, `{"name": "plain"}`, nil, nil},
+		{"pnpm-workspace.yaml", &pnpmWorkspaceReader{}, "packages:\n  - 'libs/**'\n  - '!**/test/**'\n", []string{"libs/**"}, []string{"**/test/**"}},
+		{"lerna.json", &lernaJSONReader{}, `{"version": "1.0.0"}`, []string{"packages/*"}, nil},
+		{"lerna.json", &lernaJSONReader{}, `{"useWorkspaces": true}`, nil, nil},
+		{"rush.json", &rushJSONReader{}, `{
+  // https://rushjs.io
+  "projects": [
+    {"packageName": "a", "projectFolder": "apps/a"}, /* the app */
+    {"packageName": "b", "projectFolder": "libs/b // not a comment"}
+  ]
+}`, []string{"apps/a", "libs/b // not a comment"}, nil},
+	}
+
+	for _, tt := range tests {
+		def, err := tt.reader.ReadWorkspace(tt.name, []byte(tt.content))
+		if err != nil {
+			t.Fatalf("%s: ReadWorkspace failed: %v", tt.name, err)
+		}
+		if tt.members == nil {
+			if def != nil {
+				t.Errorf("%s: expected no workspace, got %+v", tt.name, def)
+			}
+			continue
+		}
+		if def == nil {
+			t.Fatalf("%s: expected a workspace", tt.name)
+		}
+		if !slices.Equal(def.Members, tt.members) || !slices.Equal(def.Exclude, tt.exclude) {
+			t.Errorf("%s: members %q exclude %q, want %q %q", tt.name, def.Members, def.Exclude, tt.members, tt.exclude)
+		}
+	}
+}
+
+func TestPackageJSONWorkspaceProtocol(t *testing.T) {
+	content := []byte(`{"dependencies": {"@acme/core": "workspace:^", "react": "^18.0.0"}}`)
+	res, err := (&npmPackageJSONParser{}).Parse("package.json", content)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	for _, dep := range res.Dependencies {
+		if dep.Workspace != (dep.Name == "@acme/core") {
+			t.Errorf("%s Workspace = %v", dep.Name, dep.Workspace)
+		}
+	}
+}
+
+func TestPackageJSONRuntimes(t *testing.T) {
+	content := []byte(`{
+  "name": "app",
+  "engines": {"npm": ">=9", "node": ">=18.17 <23"},
+  "packageManager": "pnpm@9.1.0+sha512.67f5879916a9293e5cf059c23853d571beaf4f753c707f40cb22bed5fb1578c6aad3b6c4107ccb3ba0b35be003eb621a16471ac836c87beb53f9d54bb4612724"
+}`)
+	res, err := (&npmPackageJSONParser{}).Parse("package.json", content)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	want := []core.Requirement{
+		{Name: "node", Version: ">=18.17 <23", Source: "engines"},
+		{Name: "npm", Version: ">=9", Source: "engines"},
+		{Name: "pnpm", Version: "9.1.0", Source: "packageManager"},
+	}
+	if !slices.Equal(res.Runtimes, want) {
+		t.Errorf("Runtimes = %+v, want %+v", res.Runtimes, want)
+	}
+
+	// Old packages wrote engines as an array
+	res, err = (&npmPackageJSONParser{}).Parse("package.json", []byte(`{"engines": ["node >= 0.4"]}`))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if len(res.Runtimes) != 0 {
+		t.Errorf("expected no runtimes, got %+v", res.Runtimes)
+	}
+}
+
+// TestLockfileLimits checks that the large lockfile parsers stop part
+// way through for a cancelled context or too many dependencies.
+func TestLockfileLimits(t *testing.T) {
+	testCases := []struct {
+		path   string
+		parser core.LimitedParser
+	}{
+		{"../../testdata/npm/package-lock.json", &npmPackageLockParser{}},
+		{"../../testdata/npm/npm-lockfile-version-1/package-lock.json", &npmPackageLockParser{}},
+		{"../../testdata/npm/pnpm-lock.yaml", &pnpmLockParser{}},
+		{"../../testdata/npm/yarn.lock", &yarnLockParser{}},
+	}
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	for _, tc := range testCases {
+		t.Run(tc.path, func(t *testing.T) {
+			content, err := os.ReadFile(tc.path)
+			if err != nil {
+				t.Fatalf("failed to read fixture: %v", err)
+			}
+
+			_, err = tc.parser.ParseLimited(cancelled, tc.path, content, core.Limits{})
+			if !errors.Is(err, context.Canceled) {
+				t.Errorf("expected context.Canceled, got %v", err)
+			}
+
+			_, err = tc.parser.ParseLimited(context.Background(), tc.path, content, core.Limits{MaxDependencies: 2})
+			var limitErr *core.LimitError
+			if !errors.As(err, &limitErr) || !errors.Is(err, core.ErrTooManyDependencies) {
+				t.Errorf("expected ErrTooManyDependencies, got %v", err)
+			}
+
+			res, err := tc.parser.ParseLimited(context.Background(), tc.path, content, core.Limits{MaxDependencies: 1 << 20})
+			if err != nil || len(res.Dependencies) < 3 {
+				t.Errorf("expected a full parse within limits, got %v", err)
+			}
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package npm
 
 import (
+	"context"
 	"github.com/git-pkgs/manifests/internal/core"
 	"strings"
 )
@@ -117,14 +118,27 @@ func isTopLevelKey(line string) bool {
 	return len(line) > 0 && line[0] != ' ' && line[0] != '\n'
 }
 
+// latestPnpmLockVersion is the newest major lockfileVersion pnpm writes.
+const latestPnpmLockVersion = 9
+
 func (p *pnpmLockParser) Parse(filename string, content []byte) (*core.Result, error) {
+	return p.ParseLimited(context.Background(), filename, content, core.Limits{})
+}
+
+func (p *pnpmLockParser) ParseLimited(ctx context.Context, filename string, content []byte, limits core.Limits) (*core.Result, error) {
 	text := string(content)
 	deps := make([]core.Dependency, 0, core.EstimateDeps(len(content)))
 
 	inPackages := false
 	var state pnpmPackageState
+	var formatVersion string
+	var err error
 
 	core.ForEachLine(text, func(line string) bool {
+		if v, ok := strings.CutPrefix(line, "lockfileVersion:"); ok {
+			formatVersion = strings.Trim(strings.TrimSpace(v), `'"`)
+			return true
+		}
 		if line == "packages:" {
 			inPackages = true
 			return true
@@ -146,7 +160,8 @@ func (p *pnpmLockParser) Parse(filename string, content []byte) (*core.Result, e
 		if key, ok := extractPnpmPackageKey(line); ok {
 			deps = buildDependency(deps, state)
 			state = pnpmPackageState{key: key}
-			return true
+			err = limits.Check(ctx, filename, len(deps))
+			return err == nil
 		}
 
 		// Collect metadata within a package block
@@ -156,10 +171,163 @@ func (p *pnpmLockParser) Parse(filename string, content []byte) (*core.Result, e
 		return true
 	})
 
+	if err != nil {
+		return nil, err
+	}
 	// Flush the last package
 	deps = buildDependency(deps, state)
+	if err := limits.Check(ctx, filename, len(deps)); err != nil {
+		return nil, err
+	}
+
+	if err := core.CheckFormatVersion(filename, formatVersion, latestPnpmLockVersion); err != nil {
+		return nil, err
+	}
+	return &core.Result{Dependencies: deps, Graph: buildPnpmGraph(text), FormatVersion: formatVersion}, nil
+}
+
+// pnpmIndent returns the number of leading spaces on a line.
+func pnpmIndent(line string) int {
+	return len(line) - len(strings.TrimLeft(line, " "))
+}
+
+// splitPnpmPair splits a trimmed "key: value" line, removing YAML quotes.
+func splitPnpmPair(trimmed string) (key, value string) {
+	key = trimmed
+	if idx := strings.Index(trimmed, ": "); idx >= 0 {
+		key, value = trimmed[:idx], strings.TrimSpace(trimmed[idx+2:])
+	} else {
+		key = strings.TrimSuffix(trimmed, ":")
+	}
+	return strings.Trim(key, `'"`), strings.Trim(value, `'"`)
+}
+
+// pnpmDependencyNode turns a dependency entry into the node it resolves to.
+// Values are a plain version, a version with a peer suffix, or an alias
+// key such as "zod@3.24.2" (v9) or "/zod@4.0.5" (v6). link: and file:
+// values point at local packages and don't resolve.
+func pnpmDependencyNode(name, value string) (core.Node, bool) {
+	if value == "" || strings.HasPrefix(value, "link:") || strings.HasPrefix(value, "file:") {
+		return core.Node{}, false
+	}
+	if idx := strings.IndexByte(value, '('); idx > 0 {
+		value = value[:idx]
+	}
+	if strings.HasPrefix(value, "/") || strings.Contains(value, "@") {
+		n, v := parsePnpmPackageKey(value)
+		if n == "" {
+			return core.Node{}, false
+		}
+		return core.Node{Name: n, Version: v}, true
+	}
+	return core.Node{Name: name, Version: value}, true
+}
+
+// isPnpmDependencyKey reports whether a key introduces a dependency map.
+func isPnpmDependencyKey(key string) bool {
+	return key == "dependencies" || key == "devDependencies" || key == "optionalDependencies"
+}
+
+// buildPnpmGraph makes a second pass over the lockfile to collect edges.
+// Package dependencies live under "packages:" (v5/v6) or "snapshots:"
+// (v9) and are already resolved to exact versions. Roots come from the
+// top-level dependency maps (v5/v6) or every entry under "importers:".
+func buildPnpmGraph(text string) *core.Graph {
+	graph := core.NewGraphBuilder()
+
+	section := ""
+	var current core.Node // package whose dependencies we're reading
+	inDeps := false       // inside a dependency map at the expected depth
+	depIndent := 0        // indent of entries in the current dependency map
+	pending := ""         // v6+ root name waiting for its version: line
 
-	return &core.Result{Dependencies: deps}, nil
+	core.ForEachLine(text, func(line string) bool {
+		line = strings.TrimRight(line, "\r")
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			return true
+		}
+		indent := pnpmIndent(line)
+
+		if indent == 0 {
+			section = strings.TrimSuffix(trimmed, ":")
+			current = core.Node{}
+			pending = ""
+			inDeps = false
+			if isPnpmDependencyKey(section) {
+				inDeps = true
+				depIndent = 2
+			}
+			return true
+		}
+
+		switch section {
+		case "packages", "snapshots":
+			if indent == 2 {
+				// v9 snapshots write dependency-free entries as "key: {}"
+				current = core.Node{}
+				inDeps = false
+				if key, ok := extractPnpmPackageKey(strings.TrimSuffix(line, " {}")); ok {
+					if name, version := parsePnpmPackageKey(key); name != "" {
+						current = core.Node{Name: name, Version: version}
+						graph.AddNode(current)
+					}
+				}
+				return true
+			}
+			if indent == 4 {
+				key, _ := splitPnpmPair(trimmed)
+				inDeps = key == "dependencies" || key == "optionalDependencies"
+				return true
+			}
+			if inDeps && indent == 6 && current.Name != "" {
+				name, value := splitPnpmPair(trimmed)
+				if to, ok := pnpmDependencyNode(name, value); ok {
+					graph.AddEdge(current, to)
+				}
+			}
+
+		case "importers":
+			if indent <= 4 {
+				key, _ := splitPnpmPair(trimmed)
+				inDeps = indent == 4 && isPnpmDependencyKey(key)
+				depIndent = 6
+				pending = ""
+				return true
+			}
+			addPnpmRootLine(graph, trimmed, indent, inDeps, depIndent, &pending)
+
+		default:
+			addPnpmRootLine(graph, trimmed, indent, inDeps, depIndent, &pending)
+		}
+		return true
+	})
+
+	return graph.Graph()
+}
+
+// addPnpmRootLine handles a line inside a project dependency map. v5
+// writes "name: version" on one line; v6+ writes "name:" followed by
+// nested specifier and version keys.
+func addPnpmRootLine(graph *core.GraphBuilder, trimmed string, indent int, inDeps bool, depIndent int, pending *string) {
+	if !inDeps {
+		return
+	}
+	key, value := splitPnpmPair(trimmed)
+	switch {
+	case indent == depIndent && value == "":
+		*pending = key
+	case indent == depIndent:
+		*pending = ""
+		if to, ok := pnpmDependencyNode(key, value); ok {
+			graph.AddRoot(to)
+		}
+	case indent > depIndent && *pending != "" && key == "version":
+		if to, ok := pnpmDependencyNode(*pending, value); ok {
+			graph.AddRoot(to)
+		}
+		*pending = ""
+	}
 }
 
 // parsePnpmPackageKey parses a pnpm package key like "/@scope/name/1.0.0" or "@scope/name@1.0.0"
@@ -0,0 +1,123 @@
+package npm
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/git-pkgs/manifests/internal/core"
+)
+
+func init() {
+	core.RegisterWorkspace("npm", &npmPackageJSONParser{}, core.ExactMatch("package.json"))
+	core.RegisterWorkspace("npm", &pnpmWorkspaceReader{}, core.ExactMatch("pnpm-workspace.yaml"))
+	core.RegisterWorkspace("npm", &lernaJSONReader{}, core.ExactMatch("lerna.json"))
+	core.RegisterWorkspace("npm", &rushJSONReader{}, core.ExactMatch("rush.json"))
+}
+
+// npmWorkspaceManifests are the files that make a directory a member of
+// any of the JavaScript workspace kinds.
+var npmWorkspaceManifests = []string{"package.json"}
+
+// ReadWorkspace reads the "workspaces" field: an array of patterns for
+// npm, Yarn and Bun, or {"packages": [...]} in Yarn classic. Patterns
+// starting with "!" exclude directories.
+func (p *npmPackageJSONParser) ReadWorkspace(filename string, content []byte) (*core.WorkspaceDefinition, error) {
+	var pkg struct {
+		Workspaces any `json:"workspaces"`
+	}
+	if err := json.Unmarshal(content, &pkg); err != nil {
+		return nil, &core.ParseError{Filename: filename, Err: err}
+	}
+	patterns := jsonStrings(pkg.Workspaces, "")
+	if m, ok := pkg.Workspaces.(map[string]any); ok {
+		patterns = jsonStrings(m["packages"], "")
+	}
+	if len(patterns) == 0 {
+		return nil, nil
+	}
+	return npmWorkspace(patterns), nil
+}
+
+// npmWorkspace splits patterns into members and "!"-prefixed exclusions.
+func npmWorkspace(patterns []string) *core.WorkspaceDefinition {
+	def := &core.WorkspaceDefinition{Manifests: npmWorkspaceManifests}
+	for _, pattern := range patterns {
+		if exclude, ok := strings.CutPrefix(pattern, "!"); ok {
+			def.Exclude = append(def.Exclude, exclude)
+		} else {
+			def.Members = append(def.Members, pattern)
+		}
+	}
+	return def
+}
+
+// pnpmWorkspaceReader reads pnpm-workspace.yaml.
+type pnpmWorkspaceReader struct{}
+
+func (r *pnpmWorkspaceReader) ReadWorkspace(filename string, content []byte) (*core.WorkspaceDefinition, error) {
+	return r.ReadWorkspaceLimited(filename, content, core.Limits{})
+}
+
+func (r *pnpmWorkspaceReader) ReadWorkspaceLimited(filename string, content []byte, limits core.Limits) (*core.WorkspaceDefinition, error) {
+	var ws struct {
+		Packages []string `yaml:"packages"`
+	}
+	if err := core.DecodeYAML(filename, content, &ws, limits); err != nil {
+		return nil, err
+	}
+	if len(ws.Packages) == 0 {
+		return nil, nil
+	}
+	return npmWorkspace(ws.Packages), nil
+}
+
+// lernaJSONReader reads lerna.json.
+type lernaJSONReader struct{}
+
+// lernaDefaultPackages is where Lerna looks when lerna.json doesn't say.
+var lernaDefaultPackages = []string{"packages/*"}
+
+func (r *lernaJSONReader) ReadWorkspace(filename string, content []byte) (*core.WorkspaceDefinition, error) {
+	var lerna struct {
+		Packages      []string `json:"packages"`
+		UseWorkspaces bool     `json:"useWorkspaces"`
+	}
+	if err := json.Unmarshal(content, &lerna); err != nil {
+		return nil, &core.ParseError{Filename: filename, Err: err}
+	}
+	if lerna.UseWorkspaces {
+		// The packages are package.json's workspaces
+		return nil, nil
+	}
+	if len(lerna.Packages) == 0 {
+		lerna.Packages = lernaDefaultPackages
+	}
+	return npmWorkspace(lerna.Packages), nil
+}
+
+// rushJSONReader reads rush.json, which lists every project's folder
+// rather than patterns.
+type rushJSONReader struct{}
+
+func (r *rushJSONReader) ReadWorkspace(filename string, content []byte) (*core.WorkspaceDefinition, error) {
+	var rush struct {
+		Projects []struct {
+			PackageName   string `json:"packageName"`
+			ProjectFolder string `json:"projectFolder"`
+		} `json:"projects"`
+	}
+	// rush.json is JSON with comments
+	if err := json.Unmarshal(core.StripJSONComments(content), &rush); err != nil {
+		return nil, &core.ParseError{Filename: filename, Err: err}
+	}
+	def := &core.WorkspaceDefinition{Manifests: npmWorkspaceManifests}
+	for _, p := range rush.Projects {
+		if p.ProjectFolder != "" {
+			def.Members = append(def.Members, p.ProjectFolder)
+		}
+	}
+	if len(def.Members) == 0 {
+		return nil, nil
+	}
+	return def, nil
+}
@@ -1,6 +1,7 @@
 package npm
 
 import (
+	"context"
 	"strings"
 
 	"github.com/git-pkgs/manifests/internal/core"
@@ -19,13 +20,25 @@ type yarnParseState struct {
 	version   string
 	integrity string
 	resolved  string
+	// line is where the current entry's header is.
+	line int
 }
 
-func (s *yarnParseState) reset(name string) {
+func (s *yarnParseState) reset(name string, line int) {
 	s.name = name
 	s.version = ""
 	s.integrity = ""
 	s.resolved = ""
+	s.line = line
+}
+
+// diagnose reports an entry that collectDep would drop for want of a
+// version.
+func (s *yarnParseState) diagnose(diags []core.Diagnostic) []core.Diagnostic {
+	if s.name == "" || s.version != "" {
+		return diags
+	}
+	return append(diags, core.Warning(s.line, core.CodeUnrecognisedLine, "entry for %s has no version", s.name))
 }
 
 // collectDep appends the current state as a dependency if it has a name and version
@@ -45,48 +58,82 @@ func (s *yarnParseState) collectDep(deps []core.Dependency, seen map[string]bool
 	})
 }
 
+// latestYarnMetadataVersion is the newest __metadata version Yarn
+// Berry writes (Yarn 4). Classic Yarn lockfiles have no __metadata and
+// are reported as version 1.
+const latestYarnMetadataVersion = 8
+
 func (p *yarnLockParser) Parse(filename string, content []byte) (*core.Result, error) {
+	return p.ParseLimited(context.Background(), filename, content, core.Limits{})
+}
+
+func (p *yarnLockParser) ParseLimited(ctx context.Context, filename string, content []byte, limits core.Limits) (*core.Result, error) {
 	var deps []core.Dependency
+	var diags []core.Diagnostic
 	lines := strings.Split(string(content), "\n")
 	seen := make(map[string]bool)
 	isV4 := strings.Contains(string(content), "__metadata:")
+	formatVersion := "1"
+	inMetadata := false
 
 	var state yarnParseState
 
-	for _, line := range lines {
+	for i, line := range lines {
 		if skipYarnLine(line) {
 			continue
 		}
 
 		if isYarnHeader(line) {
+			diags = state.diagnose(diags)
 			deps = state.collectDep(deps, seen)
-			state.reset(parseYarnHeader(line))
+			if err := limits.Check(ctx, filename, len(deps)); err != nil {
+				return nil, err
+			}
+			inMetadata = strings.HasPrefix(line, "__metadata:")
+			if inMetadata {
+				state.reset("", i+1)
+				continue
+			}
+			name := parseYarnHeader(line)
+			if name == "" || !strings.HasSuffix(strings.TrimSpace(line), ":") {
+				diags = append(diags, core.Warning(i+1, core.CodeUnrecognisedLine, "unrecognised entry %q", strings.TrimSpace(line)))
+			}
+			state.reset(name, i+1)
 			continue
 		}
 
-		parseYarnDetailLine(strings.TrimLeft(line, " \t"), isV4, &state)
+		trimmed := strings.TrimLeft(line, " \t")
+		if inMetadata {
+			if v, ok := strings.CutPrefix(trimmed, "version:"); ok {
+				formatVersion = extractYarnValue(v)
+			}
+			continue
+		}
+		parseYarnDetailLine(trimmed, isV4, &state)
+	}
+
+	if err := core.CheckFormatVersion(filename, formatVersion, latestYarnMetadataVersion); err != nil {
+		return nil, err
 	}
 
 	// Don't forget the last package
+	diags = state.diagnose(diags)
 	if state.name != "" && state.version != "" && !seen[state.name] {
 		if !strings.HasPrefix(state.version, "0.0.0-use.local") {
 			deps = state.collectDep(deps, seen)
 		}
 	}
+	if err := limits.Check(ctx, filename, len(deps)); err != nil {
+		return nil, err
+	}
 
-	return &core.Result{Dependencies: deps}, nil
+	return &core.Result{Dependencies: deps, Diagnostics: diags, FormatVersion: formatVersion}, nil
 }
 
-// skipYarnLine returns true for lines that should be ignored: empty lines,
-// comments, and __metadata entries.
+// skipYarnLine returns true for lines that should be ignored: empty lines
+// and comments.
 func skipYarnLine(line string) bool {
-	if len(line) == 0 {
-		return true
-	}
-	if line[0] == '#' {
-		return true
-	}
-	return strings.HasPrefix(line, "__metadata:")
+	return len(line) == 0 || line[0] == '#'
 }
 
 // isYarnHeader returns true if the line is a package header (no leading whitespace).
@@ -1,6 +1,7 @@
 package nuget
 
 import (
+	"cmp"
 	"encoding/json"
 	"encoding/xml"
 	"github.com/git-pkgs/manifests/internal/core"
@@ -19,6 +20,9 @@ func init() {
 	core.Register("nuget", core.Lockfile, &paketLockParser{}, core.ExactMatch("paket.lock"))
 	core.Register("nuget", core.Lockfile, &projectAssetsParser{}, core.ExactMatch("project.assets.json"))
 
+	// global.json - manifest (.NET SDK version and MSBuild project SDKs)
+	core.Register("nuget", core.Manifest, &globalJSONParser{}, core.ExactMatch("global.json"))
+
 	// Project.json - manifest (legacy DNX/ASP.NET 5 format)
 	core.Register("nuget", core.Manifest, &projectJSONParser{}, core.ExactMatch("project.json", "Project.json"))
 
@@ -41,6 +45,13 @@ type csprojPropertyGroup struct {
 	AssemblyName string `xml:"AssemblyName"`
 	PackageID    string `xml:"PackageId"`
 	Version      string `xml:"Version"`
+
+	Description       string `xml:"Description"`
+	LicenseExpression string `xml:"PackageLicenseExpression"`
+	ProjectURL        string `xml:"PackageProjectUrl"`
+	RepositoryURL     string `xml:"RepositoryUrl"`
+	Authors           string `xml:"Authors"`
+	Tags              string `xml:"PackageTags"`
 }
 
 type csprojItemGroup struct {
@@ -121,6 +132,7 @@ func (p *csprojParser) Parse(filename string, content []byte) (*core.Result, err
 	base := filepath.Base(filename)
 	selfName := strings.TrimSuffix(base, filepath.Ext(base))
 	var selfVersion string
+	var metadata core.Metadata
 	for _, pg := range project.PropertyGroups {
 		if pg.PackageID != "" {
 			selfName = pg.PackageID
@@ -130,9 +142,19 @@ func (p *csprojParser) Parse(filename string, content []byte) (*core.Result, err
 		if pg.Version != "" {
 			selfVersion = pg.Version
 		}
+		metadata.Description = cmp.Or(strings.TrimSpace(pg.Description), metadata.Description)
+		metadata.License = cmp.Or(pg.LicenseExpression, metadata.License)
+		metadata.Homepage = cmp.Or(pg.ProjectURL, metadata.Homepage)
+		metadata.Repository = cmp.Or(pg.RepositoryURL, metadata.Repository)
+		if authors := splitNuGetList(pg.Authors, ","); authors != nil {
+			metadata.Authors = authors
+		}
+		if tags := splitNuGetList(pg.Tags, ";"); tags != nil {
+			metadata.Keywords = tags
+		}
 	}
 
-	return &core.Result{Name: selfName, Version: selfVersion, Dependencies: deps}, nil
+	return &core.Result{Name: selfName, Version: selfVersion, Metadata: metadata, Dependencies: deps}, nil
 }
 
 // parseReferenceInclude parses a Reference Include attribute.
@@ -176,8 +198,19 @@ type nuspecParser struct{}
 
 type nuspecPackage struct {
 	Metadata struct {
-		ID           string `xml:"id"`
-		Version      string `xml:"version"`
+		ID          string `xml:"id"`
+		Version     string `xml:"version"`
+		Description string `xml:"description"`
+		ProjectURL  string `xml:"projectUrl"`
+		Authors     string `xml:"authors"`
+		Tags        string `xml:"tags"`
+		License     struct {
+			Type  string `xml:"type,attr"`
+			Value string `xml:",chardata"`
+		} `xml:"license"`
+		Repository struct {
+			URL string `xml:"url,attr"`
+		} `xml:"repository"`
 		Dependencies struct {
 			Groups []nuspecDepGroup `xml:"group"`
 			Deps   []nuspecDep      `xml:"dependency"`
@@ -236,13 +269,39 @@ func (p *nuspecParser) Parse(filename string, content []byte) (*core.Result, err
 		}
 	}
 
+	meta := &pkg.Metadata
+	metadata := core.Metadata{
+		Description: strings.TrimSpace(meta.Description),
+		Homepage:    meta.ProjectURL,
+		Repository:  meta.Repository.URL,
+		Authors:     splitNuGetList(meta.Authors, ","),
+		Keywords:    splitNuGetList(meta.Tags, " "),
+	}
+	// A type="file" license names a file in the package
+	if meta.License.Type == "expression" {
+		metadata.License = strings.TrimSpace(meta.License.Value)
+	}
+
 	return &core.Result{
-		Name:         pkg.Metadata.ID,
-		Version:      pkg.Metadata.Version,
+		Name:         meta.ID,
+		Version:      meta.Version,
+		Metadata:     metadata,
 		Dependencies: deps,
 	}, nil
 }
 
+// splitNuGetList splits a comma- or space-separated list such as
+// <authors> or <tags>, dropping empty entries.
+func splitNuGetList(s, sep string) []string {
+	var values []string
+	for _, v := range strings.Split(s, sep) {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
 // packagesConfigParser parses packages.config files.
 type packagesConfigParser struct{}
 
@@ -473,6 +532,39 @@ func (p *projectJSONParser) Parse(filename string, content []byte) (*core.Result
 	return &core.Result{Dependencies: deps}, nil
 }
 
+// globalJSONParser parses global.json, which pins the .NET SDK and the
+// versions of MSBuild project SDKs (themselves NuGet packages).
+type globalJSONParser struct{}
+
+func (p *globalJSONParser) Parse(filename string, content []byte) (*core.Result, error) {
+	var global struct {
+		SDK struct {
+			Version string `json:"version"`
+		} `json:"sdk"`
+		MSBuildSDKs map[string]string `json:"msbuild-sdks"`
+	}
+	// The dotnet CLI accepts comments in global.json
+	if err := json.Unmarshal(core.StripJSONComments(content), &global); err != nil {
+		return nil, &core.ParseError{Filename: filename, Err: err}
+	}
+
+	var deps []core.Dependency
+	for name, version := range global.MSBuildSDKs {
+		deps = append(deps, core.Dependency{
+			Name:    name,
+			Version: version,
+			Scope:   core.Build,
+			Direct:  true,
+		})
+	}
+
+	var runtimes []core.Requirement
+	if global.SDK.Version != "" {
+		runtimes = append(runtimes, core.Requirement{Name: "dotnet", Version: global.SDK.Version, Source: "sdk.version"})
+	}
+	return &core.Result{Dependencies: deps, Runtimes: runtimes}, nil
+}
+
 // libraryEntry holds the fields shared by deps.json and project.lock.json libraries.
 type libraryEntry struct {
 	Type   string `json:"type"`
@@ -2,6 +2,7 @@ package nuget
 
 import (
 	"os"
+	"slices"
 	"testing"
 
 	"github.com/git-pkgs/manifests/internal/core"
@@ -424,3 +425,25 @@ func TestCsprojReferences(t *testing.T) {
 		}
 	}
 }
+
+func TestGlobalJSON(t *testing.T) {
+	content := []byte(`{
+  // pinned for reproducible builds
+  "sdk": {"version": "8.0.100", "rollForward": "latestFeature"},
+  "msbuild-sdks": {"Microsoft.Build.Traversal": "4.1.0"}
+}`)
+	res, err := (&globalJSONParser{}).Parse("global.json", content)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	want := []core.Requirement{{Name: "dotnet", Version: "8.0.100", Source: "sdk.version"}}
+	if !slices.Equal(res.Runtimes, want) {
+		t.Errorf("Runtimes = %+v, want %+v", res.Runtimes, want)
+	}
+	if len(res.Dependencies) != 1 {
+		t.Fatalf("expected 1 dependency, got %d", len(res.Dependencies))
+	}
+	if dep := res.Dependencies[0]; dep.Name != "Microsoft.Build.Traversal" || dep.Version != "4.1.0" || dep.Scope != core.Build {
+		t.Errorf("unexpected dependency %+v", dep)
+	}
+}
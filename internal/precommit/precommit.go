@@ -1,11 +1,11 @@
 package precommit
 
 import (
+	"context"
 	"strings"
 
 	"github.com/BurntSushi/toml"
 	"github.com/git-pkgs/manifests/internal/core"
-	"gopkg.in/yaml.v3"
 )
 
 func init() {
@@ -49,9 +49,13 @@ type preCommitYAMLConfig struct {
 }
 
 func (p *preCommitYAMLParser) Parse(filename string, content []byte) (*core.Result, error) {
+	return p.ParseLimited(context.Background(), filename, content, core.Limits{})
+}
+
+func (p *preCommitYAMLParser) ParseLimited(ctx context.Context, filename string, content []byte, limits core.Limits) (*core.Result, error) {
 	var config preCommitYAMLConfig
-	if err := yaml.Unmarshal(content, &config); err != nil {
-		return nil, &core.ParseError{Filename: filename, Err: err}
+	if err := core.DecodeYAML(filename, content, &config, limits); err != nil {
+		return nil, err
 	}
 	return &core.Result{Dependencies: reposToDeps(config.Repos)}, nil
 }
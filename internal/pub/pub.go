@@ -1,10 +1,9 @@
 package pub
 
 import (
+	"context"
 	"github.com/git-pkgs/manifests/internal/core"
 	"strings"
-
-	"gopkg.in/yaml.v3"
 )
 
 func init() {
@@ -38,20 +37,45 @@ func extractPubspecVersion(line string) (string, bool) {
 	return rest[:end], true
 }
 
+// extractPubspecDependencyType extracts the kind from a
+// '    dependency: "direct main"' line.
+func extractPubspecDependencyType(line string) (string, bool) {
+	const prefix = "    dependency: "
+	if !strings.HasPrefix(line, prefix) {
+		return "", false
+	}
+	return strings.Trim(strings.TrimSpace(line[len(prefix):]), `"`), true
+}
+
 // pubspecYAMLParser parses pubspec.yaml files.
 type pubspecYAMLParser struct{}
 
 type pubspecYAML struct {
-	Name            string         `yaml:"name"`
-	Version         string         `yaml:"version"`
+	Name        string   `yaml:"name"`
+	Version     string   `yaml:"version"`
+	Description string   `yaml:"description"`
+	Homepage    string   `yaml:"homepage"`
+	Repository  string   `yaml:"repository"`
+	Topics      []string `yaml:"topics"`
+	// Deprecated by pub, but still common
+	Author          string         `yaml:"author"`
+	Authors         []string       `yaml:"authors"`
 	Dependencies    map[string]any `yaml:"dependencies"`
 	DevDependencies map[string]any `yaml:"dev_dependencies"`
+	Environment     struct {
+		SDK     string `yaml:"sdk"`
+		Flutter string `yaml:"flutter"`
+	} `yaml:"environment"`
 }
 
 func (p *pubspecYAMLParser) Parse(filename string, content []byte) (*core.Result, error) {
+	return p.ParseLimited(context.Background(), filename, content, core.Limits{})
+}
+
+func (p *pubspecYAMLParser) ParseLimited(ctx context.Context, filename string, content []byte, limits core.Limits) (*core.Result, error) {
 	var pubspec pubspecYAML
-	if err := yaml.Unmarshal(content, &pubspec); err != nil {
-		return nil, &core.ParseError{Filename: filename, Err: err}
+	if err := core.DecodeYAML(filename, content, &pubspec, limits); err != nil {
+		return nil, err
 	}
 
 	var deps []core.Dependency
@@ -76,7 +100,28 @@ func (p *pubspecYAMLParser) Parse(filename string, content []byte) (*core.Result
 		})
 	}
 
-	return &core.Result{Name: pubspec.Name, Version: pubspec.Version, Dependencies: deps}, nil
+	// pub reads the license from the package's LICENSE file
+	metadata := core.Metadata{
+		Description: strings.TrimSpace(pubspec.Description),
+		Homepage:    pubspec.Homepage,
+		Repository:  pubspec.Repository,
+		Authors:     pubspec.Authors,
+		Keywords:    pubspec.Topics,
+	}
+	if pubspec.Author != "" {
+		metadata.Authors = append([]string{pubspec.Author}, metadata.Authors...)
+	}
+
+	// environment.sdk constrains the Dart SDK
+	var runtimes []core.Requirement
+	if env := pubspec.Environment; env.SDK != "" {
+		runtimes = append(runtimes, core.Requirement{Name: "dart", Version: env.SDK, Source: "environment"})
+	}
+	if env := pubspec.Environment; env.Flutter != "" {
+		runtimes = append(runtimes, core.Requirement{Name: "flutter", Version: env.Flutter, Source: "environment"})
+	}
+
+	return &core.Result{Name: pubspec.Name, Version: pubspec.Version, Metadata: metadata, Dependencies: deps, Runtimes: runtimes}, nil
 }
 
 // parsePubVersion extracts version from a pubspec dependency spec.
@@ -99,25 +144,39 @@ func (p *pubspecLockParser) Parse(filename string, content []byte) (*core.Result
 	text := string(content)
 	deps := make([]core.Dependency, 0, core.EstimateDeps(len(content)))
 
-	var currentName string
+	var currentName, currentType string
 	core.ForEachLine(text, func(line string) bool {
 		// Check for package name (2-space indent, ends with colon)
 		if name, ok := extractPubspecName(line); ok {
 			currentName = name
+			currentType = ""
+			return true
+		}
+
+		if currentName == "" {
+			return true
+		}
+
+		// Newer lockfiles record "direct main", "direct dev",
+		// "direct overridden" or "transitive"
+		if kind, ok := extractPubspecDependencyType(line); ok {
+			currentType = kind
 			return true
 		}
 
 		// Check for version (4-space indent)
-		if currentName != "" {
-			if version, ok := extractPubspecVersion(line); ok {
-				deps = append(deps, core.Dependency{
-					Name:    currentName,
-					Version: version,
-					Scope:   core.Runtime,
-					Direct:  false,
-				})
-				currentName = ""
+		if version, ok := extractPubspecVersion(line); ok {
+			scope := core.Runtime
+			if currentType == "direct dev" {
+				scope = core.Development
 			}
+			deps = append(deps, core.Dependency{
+				Name:    currentName,
+				Version: version,
+				Scope:   scope,
+				Direct:  strings.HasPrefix(currentType, "direct"),
+			})
+			currentName = ""
 		}
 		return true
 	})
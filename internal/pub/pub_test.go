@@ -2,6 +2,7 @@ package pub
 
 import (
 	"os"
+	"slices"
 	"testing"
 
 	"github.com/git-pkgs/manifests/internal/core"
@@ -94,3 +95,79 @@ func TestPubspecLock(t *testing.T) {
 		}
 	}
 }
+
+func TestPubspecLockDependencyType(t *testing.T) {
+	content := []byte(`packages:
+  async:
+    dependency: transitive
+    description:
+      name: async
+      url: "https://pub.dev"
+    source: hosted
+    version: "2.11.0"
+  http:
+    dependency: "direct main"
+    description:
+      name: http
+      url: "https://pub.dev"
+    source: hosted
+    version: "1.2.1"
+  test:
+    dependency: "direct dev"
+    description:
+      name: test
+      url: "https://pub.dev"
+    source: hosted
+    version: "1.25.2"
+sdks:
+  dart: ">=3.3.0 <4.0.0"
+`)
+
+	parser := &pubspecLockParser{}
+	res, err := parser.Parse("pubspec.lock", content)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	expected := map[string]struct {
+		direct bool
+		scope  core.Scope
+	}{
+		"async": {false, core.Runtime},
+		"http":  {true, core.Runtime},
+		"test":  {true, core.Development},
+	}
+	if len(res.Dependencies) != len(expected) {
+		t.Fatalf("expected %d dependencies, got %d", len(expected), len(res.Dependencies))
+	}
+	for _, dep := range res.Dependencies {
+		exp := expected[dep.Name]
+		if dep.Direct != exp.direct {
+			t.Errorf("%s direct = %v, want %v", dep.Name, dep.Direct, exp.direct)
+		}
+		if dep.Scope != exp.scope {
+			t.Errorf("%s scope = %v, want %v", dep.Name, dep.Scope, exp.scope)
+		}
+	}
+}
+
+func TestPubspecEnvironment(t *testing.T) {
+	content := []byte(`name: app
+environment:
+  sdk: ">=3.2.0 <4.0.0"
+  flutter: ">=3.16.0"
+dependencies:
+  http: ^1.1.0
+`)
+	res, err := (&pubspecYAMLParser{}).Parse("pubspec.yaml", content)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	want := []core.Requirement{
+		{Name: "dart", Version: ">=3.2.0 <4.0.0", Source: "environment"},
+		{Name: "flutter", Version: ">=3.16.0", Source: "environment"},
+	}
+	if !slices.Equal(res.Runtimes, want) {
+		t.Errorf("Runtimes = %+v, want %+v", res.Runtimes, want)
+	}
+}
@@ -1,10 +1,18 @@
 package pypi
 
 import (
+	"cmp"
 	"encoding/json"
 	"github.com/git-pkgs/manifests/internal/core"
+	"maps"
+	"net/url"
+	"path"
 	"regexp"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
+	"unicode"
 
 	"github.com/BurntSushi/toml"
 )
@@ -33,6 +41,9 @@ func init() {
 	// pyproject.toml - manifest (Poetry/PEP 621)
 	core.Register("pypi", core.Manifest, &pyprojectParser{}, core.ExactMatch("pyproject.toml"))
 
+	// [tool.uv.workspace] in pyproject.toml
+	core.RegisterWorkspace("pypi", &pyprojectParser{}, core.ExactMatch("pyproject.toml"))
+
 	// poetry.lock - lockfile
 	core.Register("pypi", core.Lockfile, &poetryLockParser{}, core.ExactMatch("poetry.lock"))
 
@@ -62,21 +73,32 @@ type requirementsTxtParser struct{}
 var (
 	// pkg==1.0.0 or pkg>=1.0.0 or pkg~=1.0.0
 	requirementRegex = regexp.MustCompile(`^([a-zA-Z0-9_.-]+(?:\[[^\]]+\])?)\s*(==|>=|<=|~=|!=|>|<)?(.*)`)
+
+	// Runs of -, _ and . are equivalent in package names (PEP 503)
+	pythonNameSeparators = regexp.MustCompile(`[-_.]+`)
 )
 
 func (p *requirementsTxtParser) Parse(filename string, content []byte) (*core.Result, error) {
 	var deps []core.Dependency
+	var diags []core.Diagnostic
 	lines := strings.Split(string(content), "\n")
 
-	for _, line := range lines {
+	for i, line := range lines {
 		// Remove comments
 		if idx := strings.Index(line, "#"); idx >= 0 {
 			line = line[:idx]
 		}
+		pos, raw := core.LineSpan(i+1, line)
 		line = strings.TrimSpace(line)
 
-		// Skip empty lines and options
-		if line == "" || strings.HasPrefix(line, "-") {
+		if line == "" {
+			continue
+		}
+		// Options, and requirements given as a path or URL
+		if strings.HasPrefix(line, "-") || isRequirementLocation(line) {
+			if diag, ok := requirementLineDiagnostic(i+1, line); ok {
+				diags = append(diags, diag)
+			}
 			continue
 		}
 
@@ -93,15 +115,79 @@ func (p *requirementsTxtParser) Parse(filename string, content []byte) (*core.Re
 			}
 
 			deps = append(deps, core.Dependency{
-				Name:    name,
-				Version: version,
-				Scope:   core.Runtime,
-				Direct:  true,
+				Name:     name,
+				Version:  version,
+				Scope:    core.Runtime,
+				Direct:   true,
+				Position: pos,
+				Raw:      raw,
 			})
+		} else {
+			diags = append(diags, core.Warning(i+1, core.CodeUnrecognisedLine, "unrecognised requirement %q", line))
 		}
 	}
 
-	return &core.Result{Dependencies: deps}, nil
+	return &core.Result{Dependencies: deps, Diagnostics: diags}, nil
+}
+
+// EditVersion rewrites the version specifier that follows a
+// requirement's name, keeping any extras, environment markers and
+// per-requirement options such as --hash.
+func (p *requirementsTxtParser) EditVersion(content []byte, decl core.Span, dep *core.Dependency, version string) (core.Edit, bool, error) {
+	// The parser's version runs to the end of the line
+	specifier := dep.Version
+	if i := strings.IndexByte(specifier, ';'); i >= 0 {
+		specifier = specifier[:i]
+	}
+	if i := strings.Index(specifier, " -"); i >= 0 {
+		specifier = specifier[:i]
+	}
+	span, ok := core.FindAfter(content, decl, dep.Name, strings.TrimSpace(specifier))
+	if !ok {
+		return core.Edit{}, false, nil
+	}
+	if version == "" || strings.ContainsAny(version, "#;\r\n") {
+		return core.Edit{}, false, core.ErrUnencodableVersion
+	}
+	return core.Edit{Span: span, Text: version}, true, nil
+}
+
+// requirementIncludeOptions reference other requirements files.
+var requirementIncludeOptions = []string{"-r", "--requirement", "-c", "--constraint"}
+
+// isRequirementLocation reports whether a requirement line is a path or
+// URL rather than a package name.
+func isRequirementLocation(line string) bool {
+	return (strings.Contains(line, "://") && !strings.Contains(line, " @ ")) ||
+		strings.HasPrefix(line, ".") || strings.HasPrefix(line, "/") || strings.HasPrefix(line, "~")
+}
+
+// requirementLineDiagnostic explains a requirements.txt line that
+// declares dependencies the parser doesn't report: an included file, an
+// editable install, or a path or URL. Other options (--index-url,
+// --hash and so on) don't declare any and get no diagnostic.
+func requirementLineDiagnostic(line int, text string) (core.Diagnostic, bool) {
+	option, value, _ := strings.Cut(text, " ")
+	if opt, v, ok := strings.Cut(option, "="); ok {
+		option, value = opt, v
+	}
+	value = strings.TrimSpace(value)
+
+	switch {
+	case slices.Contains(requirementIncludeOptions, option):
+		return core.Warning(line, core.CodeUnfollowedInclude, "requirements from %s are not read", value), true
+	case option == "-e" || option == "--editable":
+		if isRequirementLocation(value) && !strings.Contains(value, "://") {
+			return core.Info(line, core.CodeSkippedDependency, "editable install of local path %s is not reported", value), true
+		}
+		return core.Warning(line, core.CodeSkippedDependency, "editable install %s is not reported", value), true
+	case !strings.HasPrefix(text, "-"):
+		if strings.Contains(text, "://") {
+			return core.Warning(line, core.CodeSkippedDependency, "requirement given as a URL is not reported: %s", text), true
+		}
+		return core.Info(line, core.CodeSkippedDependency, "requirement given as a local path is not reported: %s", text), true
+	}
+	return core.Diagnostic{}, false
 }
 
 // pipfileParser parses Pipfile (TOML format).
@@ -118,6 +204,7 @@ func (p *pipfileParser) Parse(filename string, content []byte) (*core.Result, er
 	}
 
 	var deps []core.Dependency
+	var diags []core.Diagnostic
 
 	for name, value := range pipfile.Packages {
 		version := extractPipfileVersion(value)
@@ -127,6 +214,9 @@ func (p *pipfileParser) Parse(filename string, content []byte) (*core.Result, er
 			Scope:   core.Runtime,
 			Direct:  true,
 		})
+		if source, ok := pipfileSource(value); ok {
+			diags = append(diags, core.Warning(0, core.CodeNonRegistrySource, "%s is installed from %s, not the registry", name, source))
+		}
 	}
 
 	for name, value := range pipfile.DevPackages {
@@ -137,9 +227,27 @@ func (p *pipfileParser) Parse(filename string, content []byte) (*core.Result, er
 			Scope:   core.Development,
 			Direct:  true,
 		})
+		if source, ok := pipfileSource(value); ok {
+			diags = append(diags, core.Warning(0, core.CodeNonRegistrySource, "%s is installed from %s, not the registry", name, source))
+		}
 	}
 
-	return &core.Result{Dependencies: deps}, nil
+	return &core.Result{Dependencies: deps, Diagnostics: diags}, nil
+}
+
+// pipfileSource returns the path, file or git repository a Pipfile
+// package table installs from instead of the package index.
+func pipfileSource(value any) (string, bool) {
+	table, ok := value.(map[string]any)
+	if !ok {
+		return "", false
+	}
+	for _, key := range []string{"path", "file", "git"} {
+		if source, ok := table[key].(string); ok {
+			return source, true
+		}
+	}
+	return "", false
 }
 
 func extractPipfileVersion(value any) string {
@@ -200,6 +308,7 @@ func (p *pipfileLockParser) Parse(filename string, content []byte) (*core.Result
 			// Use first hash, convert to SRI format
 			integrity = convertPythonHash(dep.Hashes[0])
 		}
+		hashes := pythonHashes(len(dep.Hashes), func(i int) (string, string) { return dep.Hashes[i], "" })
 
 		// Determine registry URL: prefer file URL, then lookup by index
 		registryURL := dep.File
@@ -214,6 +323,7 @@ func (p *pipfileLockParser) Parse(filename string, content []byte) (*core.Result
 			Integrity:   integrity,
 			Direct:      false, // Pipfile.lock doesn't distinguish
 			RegistryURL: registryURL,
+			Hashes:      hashes,
 		})
 	}
 
@@ -223,6 +333,7 @@ func (p *pipfileLockParser) Parse(filename string, content []byte) (*core.Result
 		if len(dep.Hashes) > 0 {
 			integrity = convertPythonHash(dep.Hashes[0])
 		}
+		hashes := pythonHashes(len(dep.Hashes), func(i int) (string, string) { return dep.Hashes[i], "" })
 
 		registryURL := dep.File
 		if registryURL == "" && dep.Index != "" {
@@ -236,6 +347,7 @@ func (p *pipfileLockParser) Parse(filename string, content []byte) (*core.Result
 			Integrity:   integrity,
 			Direct:      false,
 			RegistryURL: registryURL,
+			Hashes:      hashes,
 		})
 	}
 
@@ -253,6 +365,39 @@ func convertPythonHash(h string) string {
 	return h
 }
 
+// pythonHash splits a hash written as "sha256:<hex>", the form Python
+// lockfiles use, into a core.Hash for artifact.
+func pythonHash(h, artifact string) (core.Hash, bool) {
+	alg, value, ok := strings.Cut(h, ":")
+	if !ok || alg == "" || value == "" {
+		return core.Hash{}, false
+	}
+	return core.Hash{Algorithm: strings.ToLower(alg), Value: value, Artifact: artifact}, true
+}
+
+// pythonHashes converts n "alg:hex" hashes, each returned along with
+// its artifact by hash(i), skipping malformed ones.
+func pythonHashes(n int, hash func(i int) (h, artifact string)) []core.Hash {
+	var hashes []core.Hash
+	for i := range n {
+		if h, ok := pythonHash(hash(i)); ok {
+			hashes = append(hashes, h)
+		}
+	}
+	return hashes
+}
+
+// artifactName returns the file name at the end of a URL or path.
+func artifactName(location string) string {
+	if location == "" {
+		return ""
+	}
+	if u, err := url.Parse(location); err == nil && u.Path != "" {
+		location = u.Path
+	}
+	return path.Base(location)
+}
+
 // pyprojectParser parses pyproject.toml (Poetry format).
 type pyprojectParser struct{}
 
@@ -262,6 +407,12 @@ func (p *pyprojectParser) Parse(filename string, content []byte) (*core.Result,
 			Poetry struct {
 				Name            string         `toml:"name"`
 				Version         string         `toml:"version"`
+				Description     string         `toml:"description"`
+				License         string         `toml:"license"`
+				Homepage        string         `toml:"homepage"`
+				Repository      string         `toml:"repository"`
+				Authors         []string       `toml:"authors"`
+				Keywords        []string       `toml:"keywords"`
 				Dependencies    map[string]any `toml:"dependencies"`
 				DevDependencies map[string]any `toml:"dev-dependencies"`
 				Group           map[string]struct {
@@ -270,8 +421,15 @@ func (p *pyprojectParser) Parse(filename string, content []byte) (*core.Result,
 			} `toml:"poetry"`
 		} `toml:"tool"`
 		Project struct {
-			Name                 string              `toml:"name"`
-			Version              string              `toml:"version"`
+			Name           string `toml:"name"`
+			Version        string `toml:"version"`
+			Description    string `toml:"description"`
+			RequiresPython string `toml:"requires-python"`
+			// A string (PEP 639) or a {text = ...} or {file = ...} table
+			License              any                 `toml:"license"`
+			Authors              []pyprojectPerson   `toml:"authors"`
+			Keywords             []string            `toml:"keywords"`
+			URLs                 map[string]string   `toml:"urls"`
 			Dependencies         []string            `toml:"dependencies"`
 			OptionalDependencies map[string][]string `toml:"optional-dependencies"`
 		} `toml:"project"`
@@ -282,28 +440,44 @@ func (p *pyprojectParser) Parse(filename string, content []byte) (*core.Result,
 	}
 
 	var deps []core.Dependency
+	locator := core.NewLocator(content, locatePyprojectDependencies(content))
+
+	var runtimes []core.Requirement
+	if pyproject.Project.RequiresPython != "" {
+		runtimes = append(runtimes, core.Requirement{Name: "python", Version: pyproject.Project.RequiresPython, Source: "requires-python"})
+	}
 
 	// Poetry format
 	for name, value := range pyproject.Tool.Poetry.Dependencies {
 		if name == "python" {
+			// Poetry declares the interpreter as a dependency
+			if version := extractPoetryVersion(value); len(runtimes) == 0 && version != "" {
+				runtimes = append(runtimes, core.Requirement{Name: "python", Version: version, Source: "tool.poetry.dependencies"})
+			}
 			continue
 		}
 		version := extractPoetryVersion(value)
+		pos, raw := locator.Locate("tool.poetry.dependencies", name)
 		deps = append(deps, core.Dependency{
-			Name:    name,
-			Version: version,
-			Scope:   core.Runtime,
-			Direct:  true,
+			Name:     name,
+			Version:  version,
+			Scope:    core.Runtime,
+			Direct:   true,
+			Position: pos,
+			Raw:      raw,
 		})
 	}
 
 	for name, value := range pyproject.Tool.Poetry.DevDependencies {
 		version := extractPoetryVersion(value)
+		pos, raw := locator.Locate("tool.poetry.dev-dependencies", name)
 		deps = append(deps, core.Dependency{
-			Name:    name,
-			Version: version,
-			Scope:   core.Development,
-			Direct:  true,
+			Name:     name,
+			Version:  version,
+			Scope:    core.Development,
+			Direct:   true,
+			Position: pos,
+			Raw:      raw,
 		})
 	}
 
@@ -321,11 +495,14 @@ func (p *pyprojectParser) Parse(filename string, content []byte) (*core.Result,
 
 		for name, value := range group.Dependencies {
 			version := extractPoetryVersion(value)
+			pos, raw := locator.Locate("tool.poetry.group."+groupName+".dependencies", name)
 			deps = append(deps, core.Dependency{
-				Name:    name,
-				Version: version,
-				Scope:   scope,
-				Direct:  true,
+				Name:     name,
+				Version:  version,
+				Scope:    scope,
+				Direct:   true,
+				Position: pos,
+				Raw:      raw,
 			})
 		}
 	}
@@ -333,11 +510,14 @@ func (p *pyprojectParser) Parse(filename string, content []byte) (*core.Result,
 	// PEP 621 format
 	for _, dep := range pyproject.Project.Dependencies {
 		name, version := parsePEP508(dep)
+		pos, raw := locator.Locate("project.dependencies", dep)
 		deps = append(deps, core.Dependency{
-			Name:    name,
-			Version: version,
-			Scope:   core.Runtime,
-			Direct:  true,
+			Name:     name,
+			Version:  version,
+			Scope:    core.Runtime,
+			Direct:   true,
+			Position: pos,
+			Raw:      raw,
 		})
 	}
 
@@ -346,11 +526,14 @@ func (p *pyprojectParser) Parse(filename string, content []byte) (*core.Result,
 		scope := optionalGroupScope(groupName)
 		for _, dep := range groupDeps {
 			name, version := parsePEP508(dep)
+			pos, raw := locator.Locate("project.optional-dependencies."+groupName, dep)
 			deps = append(deps, core.Dependency{
-				Name:    name,
-				Version: version,
-				Scope:   scope,
-				Direct:  true,
+				Name:     name,
+				Version:  version,
+				Scope:    scope,
+				Direct:   true,
+				Position: pos,
+				Raw:      raw,
 			})
 		}
 	}
@@ -365,7 +548,317 @@ func (p *pyprojectParser) Parse(filename string, content []byte) (*core.Result,
 		selfVersion = pyproject.Tool.Poetry.Version
 	}
 
-	return &core.Result{Name: selfName, Version: selfVersion, Dependencies: deps}, nil
+	project, poetry := &pyproject.Project, &pyproject.Tool.Poetry
+	metadata := core.Metadata{
+		Description: cmp.Or(project.Description, poetry.Description),
+		License:     cmp.Or(pyprojectLicense(project.License), poetry.License),
+		Homepage:    cmp.Or(pyprojectURL(project.URLs, "homepage"), poetry.Homepage),
+		Repository:  cmp.Or(pyprojectURL(project.URLs, "repository", "source", "sourcecode", "code"), poetry.Repository),
+		Authors:     poetry.Authors,
+		Keywords:    poetry.Keywords,
+	}
+	if len(project.Authors) > 0 {
+		metadata.Authors = nil
+		for _, a := range project.Authors {
+			metadata.Authors = append(metadata.Authors, core.Author(a.Name, a.Email))
+		}
+	}
+	if len(project.Keywords) > 0 {
+		metadata.Keywords = project.Keywords
+	}
+
+	return &core.Result{Name: selfName, Version: selfVersion, Metadata: metadata, Dependencies: deps, Runtimes: runtimes}, nil
+}
+
+type pyprojectPerson struct {
+	Name  string `toml:"name"`
+	Email string `toml:"email"`
+}
+
+// ReadWorkspace reads a uv workspace's members and exclude globs from
+// [tool.uv.workspace]. The root project, when the file has one, is a
+// member too. Poetry has no workspace table, so a Poetry project's path
+// dependencies on directories inside its own make up its workspace
+// instead, with the project as a member.
+func (p *pyprojectParser) ReadWorkspace(filename string, content []byte) (*core.WorkspaceDefinition, error) {
+	var pyproject struct {
+		Project *struct{} `toml:"project"`
+		Tool    struct {
+			UV struct {
+				Workspace *struct {
+					Members []string `toml:"members"`
+					Exclude []string `toml:"exclude"`
+				} `toml:"workspace"`
+			} `toml:"uv"`
+			Poetry *poetryDependencyTables `toml:"poetry"`
+		} `toml:"tool"`
+	}
+	if _, err := toml.Decode(string(content), &pyproject); err != nil {
+		return nil, &core.ParseError{Filename: filename, Err: err}
+	}
+	ws := pyproject.Tool.UV.Workspace
+	if ws == nil {
+		return poetryWorkspace(pyproject.Tool.Poetry)
+	}
+	def := &core.WorkspaceDefinition{
+		Members:   ws.Members,
+		Exclude:   ws.Exclude,
+		Manifests: []string{"pyproject.toml"},
+	}
+	if pyproject.Project != nil {
+		def.Members = append([]string{"."}, def.Members...)
+	}
+	return def, nil
+}
+
+// poetryDependencyTables are the dependency tables of [tool.poetry].
+type poetryDependencyTables struct {
+	Dependencies    map[string]any `toml:"dependencies"`
+	DevDependencies map[string]any `toml:"dev-dependencies"`
+	Group           map[string]struct {
+		Dependencies map[string]any `toml:"dependencies"`
+	} `toml:"group"`
+}
+
+// poetryWorkspace returns the workspace made by a Poetry project's path
+// dependencies, or nil when none of them is a directory below the
+// project's. Paths leading out of the project, such as "../shared", are
+// left to the workspace that encloses both.
+func poetryWorkspace(poetry *poetryDependencyTables) (*core.WorkspaceDefinition, error) {
+	if poetry == nil {
+		return nil, nil
+	}
+	tables := []map[string]any{poetry.Dependencies, poetry.DevDependencies}
+	for _, group := range poetry.Group {
+		tables = append(tables, group.Dependencies)
+	}
+	seen := make(map[string]bool)
+	var members []string
+	for _, table := range tables {
+		for _, value := range table {
+			dir, ok := poetryPathDir(value)
+			if ok && !seen[dir] {
+				seen[dir] = true
+				members = append(members, dir)
+			}
+		}
+	}
+	if len(members) == 0 {
+		return nil, nil
+	}
+	sort.Strings(members)
+	return &core.WorkspaceDefinition{
+		Members:   append([]string{"."}, members...),
+		Manifests: []string{"pyproject.toml"},
+	}, nil
+}
+
+// poetryPathDir returns the directory of a Poetry path dependency when
+// it lies below the project's own. Paths to sdists and wheels aren't
+// directories and are skipped.
+func poetryPathDir(value any) (string, bool) {
+	m, ok := value.(map[string]any)
+	if !ok {
+		return "", false
+	}
+	dir, ok := m["path"].(string)
+	if !ok || strings.HasPrefix(dir, "/") {
+		return "", false
+	}
+	dir = path.Clean(dir)
+	if dir == "." || dir == ".." || strings.HasPrefix(dir, "../") {
+		return "", false
+	}
+	for _, ext := range []string{".whl", ".zip", ".tar.gz", ".tgz", ".tar.bz2"} {
+		if strings.HasSuffix(dir, ext) {
+			return "", false
+		}
+	}
+	return dir, true
+}
+
+// EditVersion rewrites the version specifier of a PEP 621 requirement
+// string, keeping its name, extras and markers, or the version of a
+// Poetry dependency as Cargo.toml's are rewritten.
+func (p *pyprojectParser) EditVersion(content []byte, decl core.Span, dep *core.Dependency, version string) (core.Edit, bool, error) {
+	lit, quote, isString := core.StringLiteralAt(content, decl.Start)
+	var span core.Span
+	var ok bool
+	if isString {
+		span, ok = core.FindAfter(content, lit, dep.Name, dep.Version)
+	} else {
+		span, quote, ok = core.TOMLVersionSpan(content, decl)
+	}
+	if !ok {
+		return core.Edit{}, false, nil
+	}
+	if isString && strings.Contains(version, ";") {
+		return core.Edit{}, false, core.ErrUnencodableVersion
+	}
+	encoded, err := core.QuotedVersion(version, quote)
+	if err != nil {
+		return core.Edit{}, false, err
+	}
+	return core.Edit{Span: span, Text: encoded}, true, nil
+}
+
+// locatePyprojectDependencies finds where each dependency is declared.
+// PEP 621 requirements are the string literals of [project]
+// dependencies and of the [project.optional-dependencies] arrays, keyed
+// by "project.dependencies" or "project.optional-dependencies.<group>"
+// and then by the requirement string itself. Poetry dependencies are
+// key lines or [tool.poetry.dependencies.name] tables, as in Cargo.toml,
+// keyed by table and then name.
+func locatePyprojectDependencies(content []byte) map[string]map[string]core.Span {
+	spans := make(map[string]map[string]core.Span)
+	record := func(table, name string, span core.Span) {
+		if spans[table] == nil {
+			spans[table] = make(map[string]core.Span)
+		}
+		if _, ok := spans[table][name]; !ok {
+			spans[table][name] = span
+		}
+	}
+
+	table := ""       // current [table]
+	poetry := ""      // current Poetry dependency table
+	subTable := ""    // current [poetry-table.name] when it declares one dependency
+	var sub core.Span // extent of subTable so far
+	flushSub := func() {
+		if subTable != "" {
+			record(poetry, subTable, sub)
+		}
+		subTable = ""
+	}
+
+	offset := 0
+	arrayEnd := 0 // end of a requirements array spanning several lines
+	core.ForEachLine(string(content), func(line string) bool {
+		lineStart := offset
+		offset += len(line) + 1
+		trimmed := strings.TrimSpace(line)
+		if lineStart < arrayEnd || trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			return true
+		}
+		start := lineStart + strings.Index(line, trimmed)
+		end := start + len(trimmed)
+
+		if strings.HasPrefix(trimmed, "[") {
+			flushSub()
+			table = strings.TrimSpace(strings.Trim(trimmed, "[]"))
+			poetry = ""
+			if isPoetryDependencyTable(table) {
+				poetry = table
+			} else if i := strings.LastIndexByte(table, '.'); i >= 0 && isPoetryDependencyTable(table[:i]) {
+				poetry = table[:i]
+				subTable = strings.Trim(table[i+1:], `"'`)
+				sub = core.Span{Start: start, End: end}
+			}
+			return true
+		}
+		if subTable != "" {
+			sub.End = end
+			return true
+		}
+
+		eq := strings.IndexByte(line, '=')
+		if eq < 0 {
+			return true
+		}
+		key := strings.Trim(strings.TrimSpace(line[:eq]), `"'`)
+		section := ""
+		switch {
+		case poetry != "":
+			if name, _, dotted := strings.Cut(key, "."); dotted {
+				key = name
+			}
+			record(poetry, key, core.Span{Start: start, End: end})
+			return true
+		case table == "project" && key == "dependencies":
+			section = "project.dependencies"
+		case table == "project.optional-dependencies":
+			section = "project.optional-dependencies." + key
+		default:
+			return true
+		}
+		i := core.SkipSpaces(content, lineStart+eq+1)
+		if i < len(content) && content[i] == '[' {
+			arrayEnd = tomlArrayStrings(content, i, func(requirement string, span core.Span) {
+				record(section, requirement, span)
+			})
+		}
+		return true
+	})
+	flushSub()
+
+	return spans
+}
+
+// isPoetryDependencyTable reports whether a table name holds Poetry
+// dependencies that pyprojectParser reads.
+func isPoetryDependencyTable(name string) bool {
+	if name == "tool.poetry.dependencies" || name == "tool.poetry.dev-dependencies" {
+		return true
+	}
+	group, ok := strings.CutPrefix(name, "tool.poetry.group.")
+	return ok && strings.HasSuffix(group, ".dependencies") && strings.Count(group, ".") == 1
+}
+
+// tomlArrayStrings calls fn with the contents and span of each string
+// literal in the TOML array opening at content[i], and returns the
+// offset just past the array.
+func tomlArrayStrings(content []byte, i int, fn func(string, core.Span)) int {
+	for i++; i < len(content); i++ {
+		switch content[i] {
+		case ']':
+			return i + 1
+		case '#':
+			for i < len(content) && content[i] != '\n' {
+				i++
+			}
+		case '"', '\'':
+			lit, _, ok := core.StringLiteralAt(content, i)
+			if !ok {
+				return i
+			}
+			fn(string(content[lit.Start:lit.End]), core.Span{Start: i, End: lit.End + 1})
+			i = lit.End
+		}
+	}
+	return i
+}
+
+// pyprojectLicense returns a [project] license given as an SPDX
+// expression or as {text = ...}. A {file = ...} reference isn't read.
+func pyprojectLicense(v any) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case map[string]any:
+		s, _ := v["text"].(string)
+		return s
+	}
+	return ""
+}
+
+// pyprojectURL returns the first [project.urls] entry whose label, once
+// normalised the way PyPI does (lowercased, punctuation and spaces
+// removed), is one of labels.
+func pyprojectURL(urls map[string]string, labels ...string) string {
+	for _, label := range labels {
+		for key, url := range urls {
+			normalised := strings.Map(func(r rune) rune {
+				if unicode.IsLetter(r) || unicode.IsDigit(r) {
+					return unicode.ToLower(r)
+				}
+				return -1
+			}, key)
+			if normalised == label {
+				return url
+			}
+		}
+	}
+	return ""
 }
 
 func extractPoetryVersion(value any) string {
@@ -439,9 +932,15 @@ func optionalGroupScope(groupName string) core.Scope {
 type poetryLockParser struct{}
 
 type poetryLockFile struct {
-	Package []poetryLockPackage `toml:"package"`
+	Package  []poetryLockPackage `toml:"package"`
+	Metadata struct {
+		LockVersion string `toml:"lock-version"`
+	} `toml:"metadata"`
 }
 
+// latestPoetryLockVersion is the newest major lock-version Poetry writes.
+const latestPoetryLockVersion = 2
+
 type poetryLockPackage struct {
 	Name    string   `toml:"name"`
 	Version string   `toml:"version"`
@@ -454,6 +953,7 @@ type poetryLockPackage struct {
 		Type string `toml:"type"`
 		URL  string `toml:"url"`
 	} `toml:"source"`
+	Dependencies map[string]any `toml:"dependencies"`
 }
 
 func (p *poetryLockParser) Parse(filename string, content []byte) (*core.Result, error) {
@@ -461,6 +961,9 @@ func (p *poetryLockParser) Parse(filename string, content []byte) (*core.Result,
 	if _, err := toml.Decode(string(content), &lock); err != nil {
 		return nil, &core.ParseError{Filename: filename, Err: err}
 	}
+	if err := core.CheckFormatVersion(filename, lock.Metadata.LockVersion, latestPoetryLockVersion); err != nil {
+		return nil, err
+	}
 
 	var deps []core.Dependency
 
@@ -491,10 +994,50 @@ func (p *poetryLockParser) Parse(filename string, content []byte) (*core.Result,
 			Integrity:   integrity,
 			Direct:      false, // poetry.lock doesn't distinguish direct
 			RegistryURL: pkg.Source.URL,
+			Hashes: pythonHashes(len(pkg.Files), func(i int) (string, string) {
+				return pkg.Files[i].Hash, pkg.Files[i].File
+			}),
 		})
 	}
 
-	return &core.Result{Dependencies: deps}, nil
+	return &core.Result{Dependencies: deps, Graph: buildPoetryGraph(lock.Package), FormatVersion: lock.Metadata.LockVersion}, nil
+}
+
+// buildPoetryGraph links packages through their [package.dependencies]
+// tables. poetry.lock doesn't record the project's own requirements, so
+// the graph has no explicit roots.
+func buildPoetryGraph(packages []poetryLockPackage) *core.Graph {
+	nodes := make(map[string]core.Node, len(packages))
+	for _, pkg := range packages {
+		key := normalizePythonName(pkg.Name)
+		if _, ok := nodes[key]; !ok {
+			nodes[key] = core.Node{Name: pkg.Name, Version: pkg.Version}
+		}
+	}
+
+	graph := core.NewGraphBuilder()
+	for _, pkg := range packages {
+		from := core.Node{Name: pkg.Name, Version: pkg.Version}
+		graph.AddNode(from)
+		names := make([]string, 0, len(pkg.Dependencies))
+		for name := range pkg.Dependencies {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if to, ok := nodes[normalizePythonName(name)]; ok {
+				graph.AddEdge(from, to)
+			}
+		}
+	}
+	return graph.Graph()
+}
+
+// normalizePythonName applies PEP 503 normalisation so that
+// "Typing_Extensions" and "typing-extensions" compare equal.
+func normalizePythonName(name string) string {
+	name = strings.ToLower(name)
+	return pythonNameSeparators.ReplaceAllString(name, "-")
 }
 
 // pdmLockParser parses pdm.lock files.
@@ -544,6 +1087,9 @@ func (p *pdmLockParser) Parse(filename string, content []byte) (*core.Result, er
 			Scope:     scope,
 			Integrity: integrity,
 			Direct:    false,
+			Hashes: pythonHashes(len(pkg.Files), func(i int) (string, string) {
+				return pkg.Files[i].Hash, pkg.Files[i].File
+			}),
 		})
 	}
 
@@ -554,21 +1100,43 @@ func (p *pdmLockParser) Parse(filename string, content []byte) (*core.Result, er
 type uvLockParser struct{}
 
 type uvLockFile struct {
+	Version int             `toml:"version"`
 	Package []uvLockPackage `toml:"package"`
 }
 
+// latestUvLockVersion is the newest uv.lock format version.
+const latestUvLockVersion = 1
+
 type uvLockPackage struct {
 	Name    string `toml:"name"`
 	Version string `toml:"version"`
 	Source  struct {
 		Registry string `toml:"registry"`
+		Editable string `toml:"editable"`
+		Virtual  string `toml:"virtual"`
 	} `toml:"source"`
-	Sdist struct {
-		Hash string `toml:"hash"`
-	} `toml:"sdist"`
-	Wheels []struct {
-		Hash string `toml:"hash"`
-	} `toml:"wheels"`
+	Dependencies         []uvLockDependency            `toml:"dependencies"`
+	OptionalDependencies map[string][]uvLockDependency `toml:"optional-dependencies"`
+	DevDependencies      map[string][]uvLockDependency `toml:"dev-dependencies"`
+	Sdist                uvLockArtifact                `toml:"sdist"`
+	Wheels               []uvLockArtifact              `toml:"wheels"`
+}
+
+// uvLockArtifact is an sdist or wheel. Registry artifacts have a url;
+// local ones a path, and those from a find-links index a filename.
+type uvLockArtifact struct {
+	URL      string `toml:"url"`
+	Path     string `toml:"path"`
+	Filename string `toml:"filename"`
+	Hash     string `toml:"hash"`
+}
+
+func (a *uvLockArtifact) name() string {
+	return cmp.Or(a.Filename, artifactName(cmp.Or(a.URL, a.Path)))
+}
+
+type uvLockDependency struct {
+	Name string `toml:"name"`
 }
 
 func (p *uvLockParser) Parse(filename string, content []byte) (*core.Result, error) {
@@ -576,7 +1144,15 @@ func (p *uvLockParser) Parse(filename string, content []byte) (*core.Result, err
 	if _, err := toml.Decode(string(content), &lock); err != nil {
 		return nil, &core.ParseError{Filename: filename, Err: err}
 	}
+	formatVersion := ""
+	if lock.Version > 0 {
+		formatVersion = strconv.Itoa(lock.Version)
+	}
+	if err := core.CheckFormatVersion(filename, formatVersion, latestUvLockVersion); err != nil {
+		return nil, err
+	}
 
+	direct, dev := uvProjectDependencies(lock.Package)
 	var deps []core.Dependency
 
 	for _, pkg := range lock.Package {
@@ -588,17 +1164,56 @@ func (p *uvLockParser) Parse(filename string, content []byte) (*core.Result, err
 			integrity = convertPythonHash(pkg.Wheels[0].Hash)
 		}
 
+		artifacts := append([]uvLockArtifact{pkg.Sdist}, pkg.Wheels...)
+		hashes := pythonHashes(len(artifacts), func(i int) (string, string) {
+			return artifacts[i].Hash, artifacts[i].name()
+		})
+
+		key := normalizePythonName(pkg.Name)
+		scope := core.Runtime
+		if dev[key] && !direct[key] {
+			scope = core.Development
+		}
 		deps = append(deps, core.Dependency{
 			Name:        pkg.Name,
 			Version:     pkg.Version,
-			Scope:       core.Runtime,
+			Scope:       scope,
 			Integrity:   integrity,
-			Direct:      false,
+			Direct:      direct[key] || dev[key],
 			RegistryURL: pkg.Source.Registry,
+			Hashes:      hashes,
+			Workspace:   pkg.Source.Editable != "" || pkg.Source.Virtual != "",
 		})
 	}
 
-	return &core.Result{Dependencies: deps}, nil
+	return &core.Result{Dependencies: deps, FormatVersion: formatVersion}, nil
+}
+
+// uvProjectDependencies returns the normalized names the workspace's own
+// packages (those with an editable or virtual source) depend on,
+// split into runtime/optional and dev dependencies.
+func uvProjectDependencies(packages []uvLockPackage) (direct, dev map[string]bool) {
+	direct = make(map[string]bool)
+	dev = make(map[string]bool)
+	for _, pkg := range packages {
+		if pkg.Source.Editable == "" && pkg.Source.Virtual == "" {
+			continue
+		}
+		for _, d := range pkg.Dependencies {
+			direct[normalizePythonName(d.Name)] = true
+		}
+		for _, group := range pkg.OptionalDependencies {
+			for _, d := range group {
+				direct[normalizePythonName(d.Name)] = true
+			}
+		}
+		for _, group := range pkg.DevDependencies {
+			for _, d := range group {
+				dev[normalizePythonName(d.Name)] = true
+			}
+		}
+	}
+	return direct, dev
 }
 
 // pipDependencyGraphParser parses pip-dependency-graph.json files (pipdeptree --json output).
@@ -686,6 +1301,12 @@ type setupPyParser struct{}
 var (
 	// Match install_requires list items
 	installRequiresRegex = regexp.MustCompile(`install_requires\s*=\s*\[([^\]]*)\]`)
+	// Match install_requires and extras_require given as anything other
+	// than a literal list or dict, such as a variable or a call
+	setupRequiresExprRegex = regexp.MustCompile(`\b(install_requires|extras_require)\s*=\s*([^\s\[{][^,)\n]*)`)
+	// Match the strings and comments in a requirement list, leaving
+	// any variables or calls it also holds
+	setupListLiteralsRegex = regexp.MustCompile(`#[^\n]*|['"][^'"]*['"]`)
 	// Match extras_require dict
 	extrasRequireRegex = regexp.MustCompile(`extras_require\s*=\s*\{([^}]*)\}`)
 	// Match quoted string
@@ -708,10 +1329,24 @@ func (p *setupPyParser) Parse(filename string, content []byte) (*core.Result, er
 		selfVersion = m[1]
 	}
 
+	var diags []core.Diagnostic
+	lines := core.NewLineIndex(content)
+	for _, m := range setupRequiresExprRegex.FindAllStringSubmatchIndex(contentStr, -1) {
+		diags = append(diags, core.Warning(lines.Span(m[0], m[1]).StartLine, core.CodeUnresolvedVariable,
+			"%s is given as %s, whose requirements are not read", contentStr[m[2]:m[3]], strings.TrimSpace(contentStr[m[4]:m[5]])))
+	}
+
 	// Parse install_requires
 	const regexCaptureGroups = 2 // full match + first capture group
-	if match := installRequiresRegex.FindStringSubmatch(contentStr); match != nil {
-		for _, req := range quotedStringRegex.FindAllStringSubmatch(match[1], -1) {
+	if match := installRequiresRegex.FindStringSubmatchIndex(contentStr); match != nil {
+		list := contentStr[match[2]:match[3]]
+		for _, item := range strings.Split(setupListLiteralsRegex.ReplaceAllString(list, ""), ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				diags = append(diags, core.Warning(lines.Span(match[0], match[1]).StartLine, core.CodeUnresolvedVariable,
+					"install_requires includes %s, whose requirements are not read", item))
+			}
+		}
+		for _, req := range quotedStringRegex.FindAllStringSubmatch(list, -1) {
 			if len(req) >= regexCaptureGroups {
 				name, version := parseSetupRequirement(req[1])
 				deps = append(deps, core.Dependency{
@@ -740,7 +1375,7 @@ func (p *setupPyParser) Parse(filename string, content []byte) (*core.Result, er
 		}
 	}
 
-	return &core.Result{Name: selfName, Version: selfVersion, Dependencies: deps}, nil
+	return &core.Result{Name: selfName, Version: selfVersion, Dependencies: deps, Diagnostics: diags}, nil
 }
 
 func parseSetupRequirement(req string) (string, string) {
@@ -787,21 +1422,32 @@ type pylockToml struct {
 }
 
 type pylockPackage struct {
-	Name    string `toml:"name"`
-	Version string `toml:"version"`
-	Wheels  []struct {
-		Name   string `toml:"name"`
-		URL    string `toml:"url"`
-		Hashes struct {
-			SHA256 string `toml:"sha256"`
-		} `toml:"hashes"`
-	} `toml:"wheels"`
-	Archive struct {
-		URL    string `toml:"url"`
-		Hashes struct {
-			SHA256 string `toml:"sha256"`
-		} `toml:"hashes"`
-	} `toml:"archive"`
+	Name    string           `toml:"name"`
+	Version string           `toml:"version"`
+	Sdist   pylockArtifact   `toml:"sdist"`
+	Wheels  []pylockArtifact `toml:"wheels"`
+	Archive pylockArtifact   `toml:"archive"`
+}
+
+// pylockArtifact is an sdist, wheel or archive. hashes maps algorithm
+// names to hex digests.
+type pylockArtifact struct {
+	Name   string            `toml:"name"`
+	URL    string            `toml:"url"`
+	Path   string            `toml:"path"`
+	Hashes map[string]string `toml:"hashes"`
+}
+
+// hashes returns the artifact's hashes in algorithm order.
+func (a *pylockArtifact) hashes() []core.Hash {
+	name := cmp.Or(a.Name, artifactName(cmp.Or(a.URL, a.Path)))
+	var hashes []core.Hash
+	for _, alg := range slices.Sorted(maps.Keys(a.Hashes)) {
+		if a.Hashes[alg] != "" {
+			hashes = append(hashes, core.Hash{Algorithm: strings.ToLower(alg), Value: a.Hashes[alg], Artifact: name})
+		}
+	}
+	return hashes
 }
 
 func (p *pylockTomlParser) Parse(filename string, content []byte) (*core.Result, error) {
@@ -819,11 +1465,17 @@ func (p *pylockTomlParser) Parse(filename string, content []byte) (*core.Result,
 
 		integrity := ""
 		// Get hash from first wheel or archive
-		if len(pkg.Wheels) > 0 && pkg.Wheels[0].Hashes.SHA256 != "" {
-			integrity = "sha256-" + pkg.Wheels[0].Hashes.SHA256
-		} else if pkg.Archive.Hashes.SHA256 != "" {
-			integrity = "sha256-" + pkg.Archive.Hashes.SHA256
+		if len(pkg.Wheels) > 0 && pkg.Wheels[0].Hashes["sha256"] != "" {
+			integrity = "sha256-" + pkg.Wheels[0].Hashes["sha256"]
+		} else if pkg.Archive.Hashes["sha256"] != "" {
+			integrity = "sha256-" + pkg.Archive.Hashes["sha256"]
+		}
+
+		hashes := pkg.Sdist.hashes()
+		for i := range pkg.Wheels {
+			hashes = append(hashes, pkg.Wheels[i].hashes()...)
 		}
+		hashes = append(hashes, pkg.Archive.hashes()...)
 
 		deps = append(deps, core.Dependency{
 			Name:      pkg.Name,
@@ -831,6 +1483,7 @@ func (p *pylockTomlParser) Parse(filename string, content []byte) (*core.Result,
 			Scope:     core.Runtime,
 			Integrity: integrity,
 			Direct:    false,
+			Hashes:    hashes,
 		})
 	}
 
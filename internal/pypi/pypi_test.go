@@ -2,6 +2,8 @@ package pypi
 
 import (
 	"os"
+	"slices"
+	"strings"
 	"testing"
 
 	"github.com/git-pkgs/manifests/internal/core"
@@ -65,6 +67,56 @@ func TestRequirementsTxt(t *testing.T) {
 	}
 }
 
+func TestRequirementsTxtDiagnostics(t *testing.T) {
+	content := []byte(`--index-url https://pypi.example.com/simple
+-r base.txt
+--constraint=constraints.txt
+requests==2.31.0 \
+    --hash=sha256:58cd2187c01e70e6e26505bca751777aa9f2ee0b7f4300988b709f44e013003f
+-e .
+-e git+https://github.com/psf/black@main#egg=black
+https://example.com/pkg-1.0.tar.gz
+./vendor/local-pkg
+flask @ https://example.com/flask-3.0.0.tar.gz
+!!!
+`)
+	res, err := (&requirementsTxtParser{}).Parse("requirements.txt", content)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	var names []string
+	for _, d := range res.Dependencies {
+		names = append(names, d.Name)
+	}
+	if strings.Join(names, ",") != "requests,flask" {
+		t.Errorf("dependencies = %v, want [requests flask]", names)
+	}
+
+	expected := []struct {
+		line     int
+		severity core.Severity
+		code     string
+	}{
+		{2, core.SeverityWarning, core.CodeUnfollowedInclude},
+		{3, core.SeverityWarning, core.CodeUnfollowedInclude},
+		{6, core.SeverityInfo, core.CodeSkippedDependency},
+		{7, core.SeverityWarning, core.CodeSkippedDependency},
+		{8, core.SeverityWarning, core.CodeSkippedDependency},
+		{9, core.SeverityInfo, core.CodeSkippedDependency},
+		{11, core.SeverityWarning, core.CodeUnrecognisedLine},
+	}
+	if len(res.Diagnostics) != len(expected) {
+		t.Fatalf("expected %d diagnostics, got %+v", len(expected), res.Diagnostics)
+	}
+	for i, exp := range expected {
+		d := res.Diagnostics[i]
+		if d.Line != exp.line || d.Severity != exp.severity || d.Code != exp.code {
+			t.Errorf("diagnostic %d = %+v, want line %d %s %s", i, d, exp.line, exp.severity, exp.code)
+		}
+	}
+}
+
 func TestPipfile(t *testing.T) {
 	content, err := os.ReadFile("../../testdata/pypi/Pipfile")
 	if err != nil {
@@ -249,6 +301,53 @@ func TestPoetryLock(t *testing.T) {
 	})
 }
 
+func TestPoetryLockGraph(t *testing.T) {
+	content, err := os.ReadFile("../../testdata/pypi/poetry.lock")
+	if err != nil {
+		t.Fatalf("failed to read fixture: %v", err)
+	}
+
+	res, err := (&poetryLockParser{}).Parse("poetry.lock", content)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if res.Graph == nil {
+		t.Fatal("expected a graph")
+	}
+
+	children := make(map[string]bool)
+	for _, c := range res.Graph.Children(core.Node{Name: "django", Version: "3.2.25"}) {
+		children[c.Name+"@"+c.Version] = true
+	}
+	for _, want := range []string{"asgiref@3.7.2", "pytz@2025.2", "sqlparse@0.4.4"} {
+		if !children[want] {
+			t.Errorf("expected django -> %s edge", want)
+		}
+	}
+
+	path := res.Graph.Path("zipp", "")
+	if len(path) < 2 {
+		t.Fatalf("Path(zipp) = %v, want a chain", path)
+	}
+	if last := path[len(path)-1]; last != (core.Node{Name: "zipp", Version: "3.15.0"}) {
+		t.Errorf("Path ends at %v, want zipp 3.15.0", last)
+	}
+}
+
+func TestNormalizePythonName(t *testing.T) {
+	tests := map[string]string{
+		"Django":            "django",
+		"typing_extensions": "typing-extensions",
+		"zope.interface":    "zope-interface",
+		"a-_.b":             "a-b",
+	}
+	for in, want := range tests {
+		if got := normalizePythonName(in); got != want {
+			t.Errorf("normalizePythonName(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
 func TestRequirementsDevTxt(t *testing.T) {
 	depMap := parseFixture(t, "../../testdata/pypi/requirements-dev.txt", "requirements-dev.txt", &requirementsTxtParser{}, 51)
 	verifyVersions(t, depMap, map[string]string{
@@ -391,6 +490,24 @@ func TestUvLock(t *testing.T) {
 			t.Errorf("%s integrity = %q, want %q", name, dep.Integrity, exp.integrity)
 		}
 	}
+
+	// ftfy is the editable project; its dependencies are direct
+	directs := map[string]core.Scope{
+		"wcwidth": core.Runtime,
+		"furo":    core.Development,
+		"pytest":  core.Development,
+		"ruff":    core.Development,
+		"sphinx":  core.Development,
+	}
+	for _, d := range res.Dependencies {
+		scope, ok := directs[d.Name]
+		if d.Direct != ok {
+			t.Errorf("%s direct = %v, want %v", d.Name, d.Direct, ok)
+		}
+		if ok && d.Scope != scope {
+			t.Errorf("%s scope = %v, want %v", d.Name, d.Scope, scope)
+		}
+	}
 }
 
 func TestParsePEP508(t *testing.T) {
@@ -736,3 +853,143 @@ func TestPipenvGraphJSON(t *testing.T) {
 		"certifi":            "2024.2.2",
 	})
 }
+
+func TestPyprojectMetadata(t *testing.T) {
+	content := []byte(`[project]
+name = "app"
+description = "An app"
+license = {text = "BSD-3-Clause"}
+authors = [{name = "Jane Doe", email = "jane@example.com"}, {name = "John Roe"}]
+keywords = ["cli"]
+
+[project.urls]
+Homepage = "https://example.com"
+"Source Code" = "https://github.com/acme/app"
+
+[tool.poetry]
+description = "Ignored"
+repository = "https://gitlab.com/acme/app"
+`)
+	res, err := (&pyprojectParser{}).Parse("pyproject.toml", content)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	m := res.Metadata
+	if m.Description != "An app" || m.License != "BSD-3-Clause" {
+		t.Errorf("unexpected metadata %+v", m)
+	}
+	if m.Homepage != "https://example.com" || m.Repository != "https://github.com/acme/app" {
+		t.Errorf("unexpected urls %+v", m)
+	}
+	if len(m.Authors) != 2 || m.Authors[0] != "Jane Doe <jane@example.com>" || m.Authors[1] != "John Roe" {
+		t.Errorf("Authors = %q", m.Authors)
+	}
+}
+
+func TestUvLockHashes(t *testing.T) {
+	content, err := os.ReadFile("../../testdata/pypi/uv.lock")
+	if err != nil {
+		t.Fatalf("failed to read fixture: %v", err)
+	}
+	res, err := (&uvLockParser{}).Parse("uv.lock", content)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	var alabaster core.Dependency
+	for _, d := range res.Dependencies {
+		if d.Name == "alabaster" {
+			alabaster = d
+		}
+	}
+	want := []core.Hash{
+		{Algorithm: "sha256", Value: "75a8b99c28a5dad50dd7f8ccdd447a121ddb3892da9e53d1ca5cca3106d58d65", Artifact: "alabaster-0.7.16.tar.gz"},
+		{Algorithm: "sha256", Value: "b46733c07dce03ae4e150330b975c75737fa60f0a7c591b6c8bf4928a28e2c92", Artifact: "alabaster-0.7.16-py3-none-any.whl"},
+	}
+	if !slices.Equal(alabaster.Hashes, want) {
+		t.Errorf("alabaster hashes = %+v, want %+v", alabaster.Hashes, want)
+	}
+}
+
+func TestPoetryLockHashes(t *testing.T) {
+	content := []byte(`[[package]]
+name = "asgiref"
+version = "3.7.2"
+files = [
+    {file = "asgiref-3.7.2-py3-none-any.whl", hash = "sha256:89b2ef2247e3b562a16eef663bc0e2e703ec6468e2fa8a5cd61cd449786d4f6e"},
+    {file = "asgiref-3.7.2.tar.gz", hash = "sha256:9e0ce3aa93a819ba5b45120216b23878cf6e8525eb3848653452b4192b92afed"},
+]
+
+[metadata]
+lock-version = "2.0"
+`)
+	res, err := (&poetryLockParser{}).Parse("poetry.lock", content)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	dep := res.Dependencies[0]
+	if len(dep.Hashes) != 2 || dep.Hashes[1].Artifact != "asgiref-3.7.2.tar.gz" || dep.Hashes[1].Value != "9e0ce3aa93a819ba5b45120216b23878cf6e8525eb3848653452b4192b92afed" {
+		t.Errorf("unexpected hashes %+v", dep.Hashes)
+	}
+	// Integrity is still the first file's hash
+	if dep.Integrity != "sha256-89b2ef2247e3b562a16eef663bc0e2e703ec6468e2fa8a5cd61cd449786d4f6e" {
+		t.Errorf("Integrity = %q", dep.Integrity)
+	}
+}
+
+func TestPylockTomlHashes(t *testing.T) {
+	content := []byte(`lock-version = "1.0"
+
+[[packages]]
+name = "attrs"
+version = "25.3.0"
+
+[packages.sdist]
+url = "https://files.pythonhosted.org/packages/attrs-25.3.0.tar.gz"
+hashes = {sha256 = "aaaa", blake2b = "bbbb"}
+
+[[packages.wheels]]
+name = "attrs-25.3.0-py3-none-any.whl"
+hashes = {sha256 = "cccc"}
+`)
+	res, err := (&pylockTomlParser{}).Parse("pylock.toml", content)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	want := []core.Hash{
+		{Algorithm: "blake2b", Value: "bbbb", Artifact: "attrs-25.3.0.tar.gz"},
+		{Algorithm: "sha256", Value: "aaaa", Artifact: "attrs-25.3.0.tar.gz"},
+		{Algorithm: "sha256", Value: "cccc", Artifact: "attrs-25.3.0-py3-none-any.whl"},
+	}
+	if got := res.Dependencies[0].Hashes; !slices.Equal(got, want) {
+		t.Errorf("hashes = %+v, want %+v", got, want)
+	}
+}
+
+func TestPyprojectRequiresPython(t *testing.T) {
+	tests := []struct {
+		name, content string
+		want          []core.Requirement
+	}{
+		{"pep 621", "[project]\nname = \"a\"\nrequires-python = \">=3.11\"\n",
+			[]core.Requirement{{Name: "python", Version: ">=3.11", Source: "requires-python"}}},
+		{"poetry", "[tool.poetry]\nname = \"a\"\n\n[tool.poetry.dependencies]\npython = \"^3.10\"\nrequests = \"^2.31\"\n",
+			[]core.Requirement{{Name: "python", Version: "^3.10", Source: "tool.poetry.dependencies"}}},
+		{"both", "[project]\nname = \"a\"\nrequires-python = \">=3.11\"\n\n[tool.poetry.dependencies]\npython = \"^3.10\"\n",
+			[]core.Requirement{{Name: "python", Version: ">=3.11", Source: "requires-python"}}},
+	}
+	for _, tt := range tests {
+		res, err := (&pyprojectParser{}).Parse("pyproject.toml", []byte(tt.content))
+		if err != nil {
+			t.Fatalf("%s: Parse failed: %v", tt.name, err)
+		}
+		if !slices.Equal(res.Runtimes, tt.want) {
+			t.Errorf("%s: Runtimes = %+v, want %+v", tt.name, res.Runtimes, tt.want)
+		}
+		for _, dep := range res.Dependencies {
+			if dep.Name == "python" {
+				t.Errorf("%s: python reported as a dependency", tt.name)
+			}
+		}
+	}
+}
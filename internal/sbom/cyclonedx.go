@@ -0,0 +1,132 @@
+package sbom
+
+import (
+	"encoding/json"
+	"encoding/xml"
+
+	"github.com/git-pkgs/manifests/internal/core"
+)
+
+// cycloneDXScopes maps CycloneDX component scopes to dependency scopes.
+// "excluded" components aren't shipped, which for a package means a
+// development dependency.
+var cycloneDXScopes = map[string]core.Scope{
+	"required": core.Runtime,
+	"optional": core.Optional,
+	"excluded": core.Development,
+}
+
+// cycloneDXComponent is a component in either serialisation.
+type cycloneDXComponent struct {
+	Type    string `json:"type" xml:"type,attr"`
+	BOMRef  string `json:"bom-ref" xml:"bom-ref,attr"`
+	Group   string `json:"group" xml:"group"`
+	Name    string `json:"name" xml:"name"`
+	Version string `json:"version" xml:"version"`
+	Scope   string `json:"scope" xml:"scope"`
+	PURL    string `json:"purl" xml:"purl"`
+	Hashes  []struct {
+		Alg     string `json:"alg" xml:"alg,attr"`
+		Content string `json:"content" xml:",chardata"`
+	} `json:"hashes" xml:"hashes>hash"`
+	Components []cycloneDXComponent `json:"components" xml:"components>component"`
+}
+
+type cycloneDXDependency struct {
+	Ref          string   `json:"ref" xml:"ref,attr"`
+	DependsOn    []string `json:"dependsOn" xml:"-"`
+	XMLDependsOn []struct {
+		Ref string `xml:"ref,attr"`
+	} `json:"-" xml:"dependency"`
+}
+
+type cycloneDXBOM struct {
+	Metadata struct {
+		Component *cycloneDXComponent `json:"component" xml:"component"`
+	} `json:"metadata" xml:"metadata"`
+	Components   []cycloneDXComponent  `json:"components" xml:"components>component"`
+	Dependencies []cycloneDXDependency `json:"dependencies" xml:"dependencies>dependency"`
+}
+
+// cycloneDXJSONParser parses CycloneDX JSON SBOMs (bom.json, *.cdx.json).
+type cycloneDXJSONParser struct{}
+
+func (p *cycloneDXJSONParser) Parse(filename string, content []byte) (*core.Result, error) {
+	var bom cycloneDXBOM
+	if err := json.Unmarshal(content, &bom); err != nil {
+		return nil, &core.ParseError{Filename: filename, Err: err}
+	}
+	return cycloneDXResult(&bom), nil
+}
+
+// cycloneDXXMLParser parses CycloneDX XML SBOMs (bom.xml, *.cdx.xml).
+type cycloneDXXMLParser struct{}
+
+func (p *cycloneDXXMLParser) Parse(filename string, content []byte) (*core.Result, error) {
+	var bom cycloneDXBOM
+	if err := xml.Unmarshal(content, &bom); err != nil {
+		return nil, &core.ParseError{Filename: filename, Err: err}
+	}
+	for i := range bom.Dependencies {
+		d := &bom.Dependencies[i]
+		for _, child := range d.XMLDependsOn {
+			d.DependsOn = append(d.DependsOn, child.Ref)
+		}
+	}
+	return cycloneDXResult(&bom), nil
+}
+
+func cycloneDXResult(bom *cycloneDXBOM) *core.Result {
+	doc := newDocument()
+	var rootRef string
+	if root := bom.Metadata.Component; root != nil {
+		rootRef = root.BOMRef
+		doc.name = cycloneDXName(root)
+		doc.version = root.Version
+	}
+
+	var walk func([]cycloneDXComponent)
+	walk = func(components []cycloneDXComponent) {
+		for i := range components {
+			c := &components[i]
+			if c.Type == "" || c.Type == "library" || c.Type == "framework" || c.PURL != "" {
+				cc := &component{
+					id:      c.BOMRef,
+					name:    cycloneDXName(c),
+					version: c.Version,
+					purl:    c.PURL,
+					hashes:  make(map[string]string),
+				}
+				for _, h := range c.Hashes {
+					if alg := sriAlgorithm(h.Alg); alg != "" {
+						cc.hashes[alg] = h.Content
+					}
+				}
+				if scope, ok := cycloneDXScopes[c.Scope]; ok {
+					cc.scope = scope
+				}
+				doc.add(cc)
+			}
+			walk(c.Components)
+		}
+	}
+	walk(bom.Components)
+
+	for _, d := range bom.Dependencies {
+		for _, to := range d.DependsOn {
+			if rootRef != "" && d.Ref == rootRef {
+				doc.roots = append(doc.roots, to)
+			} else {
+				doc.addEdge(d.Ref, to)
+			}
+		}
+	}
+	return doc.result()
+}
+
+func cycloneDXName(c *cycloneDXComponent) string {
+	if c.Group != "" {
+		return c.Group + "/" + c.Name
+	}
+	return c.Name
+}
@@ -0,0 +1,193 @@
+// Package sbom parses CycloneDX and SPDX documents as lockfiles, so that
+// SBOMs handed over in place of a lockfile go through the same pipeline.
+package sbom
+
+import (
+	"strings"
+
+	"github.com/git-pkgs/manifests/internal/core"
+	"github.com/git-pkgs/purl"
+)
+
+func init() {
+	// CycloneDX JSON and XML
+	core.RegisterFormat("cyclonedx", core.Lockfile, &cycloneDXJSONParser{}, core.AnyMatch(
+		core.ExactMatch("bom.json"),
+		core.SuffixMatch(".cdx.json"),
+	))
+	core.RegisterFormat("cyclonedx", core.Lockfile, &cycloneDXXMLParser{}, core.AnyMatch(
+		core.ExactMatch("bom.xml"),
+		core.SuffixMatch(".cdx.xml"),
+	))
+
+	// SPDX JSON and tag-value
+	core.RegisterFormat("spdx", core.Lockfile, &spdxJSONParser{}, core.SuffixMatch(".spdx.json"))
+	core.RegisterFormat("spdx", core.Lockfile, &spdxTagValueParser{}, core.SuffixMatch(".spdx"))
+}
+
+// purlEcosystems maps PURL types to this module's ecosystem names where
+// the two differ.
+var purlEcosystems = map[string]string{
+	"apk":           "alpine",
+	"alpm":          "arch",
+	"githubactions": "github-actions",
+}
+
+// component is a package as described by an SBOM, before it becomes a
+// dependency.
+type component struct {
+	id      string
+	name    string
+	version string
+	purl    string
+	// scope is empty when the SBOM doesn't give one.
+	scope core.Scope
+	// hashes maps SRI algorithm names to hex digests.
+	hashes map[string]string
+}
+
+// dependency converts a component. The ecosystem, name and version come
+// from the PURL when there is one, since SBOM names are often display
+// names. Components without one belong to the "generic" PURL type.
+func (c *component) dependency() core.Dependency {
+	dep := core.Dependency{
+		Name:      c.name,
+		Version:   c.version,
+		Scope:     c.scope,
+		Integrity: integrity(c.hashes),
+		Ecosystem: "generic",
+	}
+	if dep.Scope == "" {
+		dep.Scope = core.Runtime
+	}
+	if c.purl == "" {
+		return dep
+	}
+	p, err := purl.Parse(c.purl)
+	if err != nil {
+		return dep
+	}
+	dep.PURL = c.purl
+	dep.Ecosystem = p.Type
+	if eco, ok := purlEcosystems[p.Type]; ok {
+		dep.Ecosystem = eco
+	}
+	dep.Name = p.FullName()
+	if p.Version != "" {
+		dep.Version = p.Version
+	}
+	dep.RegistryURL = p.Qualifier("repository_url")
+	return dep
+}
+
+// hashPreference lists SRI algorithms strongest first.
+var hashPreference = []string{"sha512", "sha384", "sha256", "sha1", "md5"}
+
+// integrity returns the strongest hash as an SRI-style value with a hex
+// digest, as the Cargo and Composer parsers record them.
+func integrity(hashes map[string]string) string {
+	for _, alg := range hashPreference {
+		if digest, ok := hashes[alg]; ok && digest != "" {
+			return alg + "-" + strings.ToLower(digest)
+		}
+	}
+	return ""
+}
+
+// sriAlgorithm normalises an SBOM hash algorithm name ("SHA-256",
+// "SHA256", "sha256") to its SRI form. Returns "" for algorithms SRI
+// has no name for.
+func sriAlgorithm(alg string) string {
+	alg = strings.ToLower(strings.ReplaceAll(alg, "-", ""))
+	for _, known := range hashPreference {
+		if alg == known {
+			return alg
+		}
+	}
+	return ""
+}
+
+// document collects the components and relationships of an SBOM and
+// turns them into a result.
+type document struct {
+	name, version string
+	components    []*component
+	byID          map[string]*component
+	// edges maps a component ID to the IDs it depends on.
+	edges map[string][]string
+	// roots are the IDs the described project depends on directly.
+	roots []string
+}
+
+func newDocument() *document {
+	return &document{
+		byID:  make(map[string]*component),
+		edges: make(map[string][]string),
+	}
+}
+
+func (d *document) add(c *component) {
+	if c.name == "" && c.purl == "" {
+		return
+	}
+	if c.id != "" {
+		if _, ok := d.byID[c.id]; ok {
+			return
+		}
+		d.byID[c.id] = c
+	}
+	d.components = append(d.components, c)
+}
+
+func (d *document) addEdge(from, to string) {
+	d.edges[from] = append(d.edges[from], to)
+}
+
+// result builds the dependencies and graph. Roots are marked direct;
+// without any, every component is treated as direct, since a flat SBOM
+// doesn't say otherwise.
+func (d *document) result() *core.Result {
+	deps := make([]core.Dependency, len(d.components))
+	nodes := make(map[string]core.Node, len(d.components))
+	for i, c := range d.components {
+		deps[i] = c.dependency()
+		if c.id != "" {
+			nodes[c.id] = core.Node{Name: deps[i].Name, Version: deps[i].Version}
+		}
+	}
+
+	roots := make(map[string]bool, len(d.roots))
+	for _, id := range d.roots {
+		roots[id] = true
+	}
+	for i, c := range d.components {
+		deps[i].Direct = len(roots) == 0 || roots[c.id]
+	}
+
+	res := &core.Result{Name: d.name, Version: d.version, Dependencies: deps}
+	if len(d.edges) == 0 && len(d.roots) == 0 {
+		return res
+	}
+	b := core.NewGraphBuilder()
+	for _, c := range d.components {
+		if n, ok := nodes[c.id]; ok {
+			b.AddNode(n)
+		}
+	}
+	for _, id := range d.roots {
+		if n, ok := nodes[id]; ok {
+			b.AddRoot(n)
+		}
+	}
+	for _, c := range d.components {
+		for _, to := range d.edges[c.id] {
+			from, okFrom := nodes[c.id]
+			n, okTo := nodes[to]
+			if okFrom && okTo {
+				b.AddEdge(from, n)
+			}
+		}
+	}
+	res.Graph = b.Graph()
+	return res
+}
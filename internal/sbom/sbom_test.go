@@ -0,0 +1,174 @@
+package sbom
+
+import (
+	"os"
+	"testing"
+
+	"github.com/git-pkgs/manifests/internal/core"
+)
+
+func parseFixture(t *testing.T, parser core.Parser, path string) *core.Result {
+	t.Helper()
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read fixture: %v", err)
+	}
+	res, err := parser.Parse(path, content)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	return res
+}
+
+func depsByName(deps []core.Dependency) map[string]core.Dependency {
+	m := make(map[string]core.Dependency)
+	for _, d := range deps {
+		m[d.Name] = d
+	}
+	return m
+}
+
+func TestCycloneDXJSON(t *testing.T) {
+	res := parseFixture(t, &cycloneDXJSONParser{}, "../../testdata/sbom/bom.json")
+
+	if res.Name != "webapp" || res.Version != "2.1.0" {
+		t.Errorf("project = %s %s, want webapp 2.1.0", res.Name, res.Version)
+	}
+	// The operating-system component is skipped
+	if len(res.Dependencies) != 4 {
+		t.Fatalf("expected 4 dependencies, got %d", len(res.Dependencies))
+	}
+	deps := depsByName(res.Dependencies)
+
+	express := deps["express"]
+	if express.Ecosystem != "npm" || express.Version != "4.18.2" || express.PURL != "pkg:npm/express@4.18.2" {
+		t.Errorf("express = %+v", express)
+	}
+	if !express.Direct || express.Scope != core.Runtime {
+		t.Errorf("express direct %v, scope %q", express.Direct, express.Scope)
+	}
+	if len(express.Integrity) != len("sha512-")+128 || express.Integrity[:7] != "sha512-" {
+		t.Errorf("express integrity = %q, want the SHA-512 hash", express.Integrity)
+	}
+
+	if bp, ok := deps["body-parser"]; !ok || bp.Direct {
+		t.Errorf("nested body-parser should be a transitive dependency, got %+v", bp)
+	}
+	if types := deps["@types/node"]; types.Scope != core.Development || types.Ecosystem != "npm" {
+		t.Errorf("@types/node = %+v", types)
+	}
+	if lang := deps["org.apache.commons:commons-lang3"]; lang.Scope != core.Optional || lang.Ecosystem != "maven" {
+		t.Errorf("commons-lang3 = %+v", lang)
+	}
+
+	if res.Graph == nil {
+		t.Fatal("expected a graph")
+	}
+	if len(res.Graph.Roots) != 3 {
+		t.Errorf("expected 3 roots, got %v", res.Graph.Roots)
+	}
+	children := res.Graph.Children(core.Node{Name: "express", Version: "4.18.2"})
+	if len(children) != 1 || children[0].Name != "body-parser" {
+		t.Errorf("express children = %v", children)
+	}
+}
+
+func TestCycloneDXXML(t *testing.T) {
+	res := parseFixture(t, &cycloneDXXMLParser{}, "../../testdata/sbom/bom.xml")
+
+	if res.Name != "webapp" {
+		t.Errorf("project name = %q", res.Name)
+	}
+	deps := depsByName(res.Dependencies)
+	if len(deps) != 3 {
+		t.Fatalf("expected 3 dependencies, got %d", len(deps))
+	}
+	serde := deps["serde"]
+	if serde.Ecosystem != "cargo" || !serde.Direct ||
+		serde.Integrity != "sha256-63261df402c67811e9ac6def069e4786148c4563f4b50fd4bf30aa370d626b02" {
+		t.Errorf("serde = %+v", serde)
+	}
+	if deps["serde_derive"].Direct {
+		t.Error("serde_derive should be transitive")
+	}
+	if checkout := deps["actions/checkout"]; checkout.Ecosystem != "github-actions" || checkout.Version != "v4" {
+		t.Errorf("checkout = %+v", checkout)
+	}
+}
+
+func TestSPDXJSON(t *testing.T) {
+	res := parseFixture(t, &spdxJSONParser{}, "../../testdata/sbom/example.spdx.json")
+
+	if res.Name != "webapp" || res.Version != "2.1.0" {
+		t.Errorf("project = %s %s, want webapp 2.1.0", res.Name, res.Version)
+	}
+	deps := depsByName(res.Dependencies)
+	if len(deps) != 3 {
+		t.Fatalf("expected 3 dependencies, got %d", len(deps))
+	}
+
+	requests := deps["requests"]
+	if requests.Ecosystem != "pypi" || requests.PURL != "pkg:pypi/requests@2.31.0" || !requests.Direct {
+		t.Errorf("requests = %+v", requests)
+	}
+	if requests.Integrity != "sha256-942c5a758f98d790eaed1a29cb6eefc7ffb0d1cf7af05c3d2791656dbd6ad1e1" {
+		t.Errorf("requests integrity = %q", requests.Integrity)
+	}
+	if pytest := deps["pytest"]; pytest.Scope != core.Development || !pytest.Direct {
+		t.Errorf("pytest = %+v", pytest)
+	}
+	if deps["urllib3"].Direct {
+		t.Error("urllib3 should be transitive")
+	}
+	if path := res.Graph.Path("urllib3", ""); len(path) != 2 || path[0].Name != "requests" {
+		t.Errorf("urllib3 path = %v", path)
+	}
+}
+
+func TestSPDXTagValue(t *testing.T) {
+	res := parseFixture(t, &spdxTagValueParser{}, "../../testdata/sbom/example.spdx")
+
+	if res.Name != "webapp" {
+		t.Errorf("project name = %q", res.Name)
+	}
+	deps := depsByName(res.Dependencies)
+	if len(deps) != 3 {
+		t.Fatalf("expected 3 dependencies, got %d", len(deps))
+	}
+	rails := deps["rails"]
+	if rails.Ecosystem != "gem" || rails.Version != "7.1.3" || !rails.Direct || rails.Scope != core.Runtime {
+		t.Errorf("rails = %+v", rails)
+	}
+	if rails.Integrity != "sha256-1e1a2b3c4d5e6f708192a3b4c5d6e7f8091a2b3c4d5e6f708192a3b4c5d6e7f8" {
+		t.Errorf("rails integrity = %q", rails.Integrity)
+	}
+	if rspec := deps["rspec"]; rspec.Scope != core.Test || !rspec.Direct {
+		t.Errorf("rspec = %+v", rspec)
+	}
+	if deps["actionpack"].Direct {
+		t.Error("actionpack should be transitive")
+	}
+}
+
+func TestSPDXFlat(t *testing.T) {
+	content := []byte(`{
+  "spdxVersion": "SPDX-2.3",
+  "documentDescribes": ["SPDXRef-a"],
+  "packages": [
+    {"SPDXID": "SPDXRef-a", "name": "a", "versionInfo": "1.0.0"},
+    {"SPDXID": "SPDXRef-b", "name": "b", "versionInfo": "2.0.0"}
+  ]
+}`)
+	res, err := (&spdxJSONParser{}).Parse("flat.spdx.json", content)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if len(res.Dependencies) != 2 || res.Name != "" || res.Graph != nil {
+		t.Errorf("expected two flat dependencies, got %+v", res)
+	}
+	for _, d := range res.Dependencies {
+		if !d.Direct || d.Ecosystem != "generic" {
+			t.Errorf("%s: direct %v, ecosystem %q", d.Name, d.Direct, d.Ecosystem)
+		}
+	}
+}
@@ -0,0 +1,277 @@
+package sbom
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"strings"
+
+	"github.com/git-pkgs/manifests/internal/core"
+)
+
+// spdxScopes maps SPDX relationships that point from a dependency to
+// the package needing it onto dependency scopes.
+var spdxScopes = map[string]core.Scope{
+	"DEPENDENCY_OF":          core.Runtime,
+	"RUNTIME_DEPENDENCY_OF":  core.Runtime,
+	"DEV_DEPENDENCY_OF":      core.Development,
+	"TEST_DEPENDENCY_OF":     core.Test,
+	"BUILD_DEPENDENCY_OF":    core.Build,
+	"OPTIONAL_DEPENDENCY_OF": core.Optional,
+	"PROVIDED_DEPENDENCY_OF": core.Runtime,
+}
+
+// spdxPackage is the part of an SPDX 2.x package this parser reads.
+type spdxPackage struct {
+	SPDXID       string            `json:"SPDXID"`
+	Name         string            `json:"name"`
+	VersionInfo  string            `json:"versionInfo"`
+	ExternalRefs []spdxExternalRef `json:"externalRefs"`
+	Checksums    []spdxChecksum    `json:"checksums"`
+}
+
+type spdxExternalRef struct {
+	ReferenceType    string `json:"referenceType"`
+	ReferenceLocator string `json:"referenceLocator"`
+}
+
+type spdxChecksum struct {
+	Algorithm     string `json:"algorithm"`
+	ChecksumValue string `json:"checksumValue"`
+}
+
+type spdxRelationship struct {
+	From string `json:"spdxElementId"`
+	Type string `json:"relationshipType"`
+	To   string `json:"relatedSpdxElement"`
+}
+
+// spdxJSONParser parses SPDX 2.x JSON documents, and SPDX 3.0 JSON-LD
+// ones, which share the .spdx.json extension.
+type spdxJSONParser struct{}
+
+func (p *spdxJSONParser) Parse(filename string, content []byte) (*core.Result, error) {
+	var doc struct {
+		DocumentDescribes []string           `json:"documentDescribes"`
+		Packages          []spdxPackage      `json:"packages"`
+		Relationships     []spdxRelationship `json:"relationships"`
+		Graph             []json.RawMessage  `json:"@graph"`
+	}
+	if err := json.Unmarshal(content, &doc); err != nil {
+		return nil, &core.ParseError{Filename: filename, Err: err}
+	}
+	if doc.Graph != nil {
+		return spdx3Result(doc.Graph), nil
+	}
+	for _, id := range doc.DocumentDescribes {
+		doc.Relationships = append(doc.Relationships, spdxRelationship{From: "SPDXRef-DOCUMENT", Type: "DESCRIBES", To: id})
+	}
+	return spdxResult(doc.Packages, doc.Relationships), nil
+}
+
+// spdxTagValueParser parses SPDX 2.x tag-value documents (*.spdx).
+type spdxTagValueParser struct{}
+
+func (p *spdxTagValueParser) Parse(filename string, content []byte) (*core.Result, error) {
+	var packages []spdxPackage
+	var relationships []spdxRelationship
+	// pkg is the index of the package being read, or -1 outside one
+	pkg := -1
+	inText := false
+
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	scanner.Buffer(make([]byte, 0, 64*1024), len(content)+1)
+	for scanner.Scan() {
+		line := scanner.Text()
+		// Multi-line values are wrapped in <text>...</text>
+		if inText {
+			inText = !strings.Contains(line, "</text>")
+			continue
+		}
+		tag, value, ok := strings.Cut(line, ":")
+		if !ok || strings.HasPrefix(strings.TrimSpace(line), "#") {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		if strings.HasPrefix(value, "<text>") && !strings.Contains(value, "</text>") {
+			inText = true
+			continue
+		}
+
+		switch strings.TrimSpace(tag) {
+		case "PackageName":
+			packages = append(packages, spdxPackage{Name: value})
+			pkg = len(packages) - 1
+		case "FileName", "SnippetSPDXID", "LicenseID":
+			// A new section that isn't a package
+			pkg = -1
+		case "SPDXID":
+			if pkg >= 0 {
+				packages[pkg].SPDXID = value
+			}
+		case "PackageVersion":
+			if pkg >= 0 {
+				packages[pkg].VersionInfo = value
+			}
+		case "PackageChecksum":
+			if pkg >= 0 {
+				alg, digest, _ := strings.Cut(value, ":")
+				packages[pkg].Checksums = append(packages[pkg].Checksums, spdxChecksum{strings.TrimSpace(alg), strings.TrimSpace(digest)})
+			}
+		case "ExternalRef":
+			// ExternalRef: PACKAGE-MANAGER purl pkg:npm/lodash@4.17.21
+			fields := strings.Fields(value)
+			if pkg >= 0 && len(fields) == 3 {
+				packages[pkg].ExternalRefs = append(packages[pkg].ExternalRefs, spdxExternalRef{fields[1], fields[2]})
+			}
+		case "Relationship":
+			fields := strings.Fields(value)
+			if len(fields) == 3 {
+				relationships = append(relationships, spdxRelationship{From: fields[0], Type: fields[1], To: fields[2]})
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, &core.ParseError{Filename: filename, Err: err}
+	}
+	return spdxResult(packages, relationships), nil
+}
+
+// spdxResult builds a result from SPDX 2.x packages and relationships.
+// A package the document describes that depends on others is the
+// project itself: it names the result and its dependencies are the
+// roots. Described packages without dependencies are ordinary entries.
+func spdxResult(packages []spdxPackage, relationships []spdxRelationship) *core.Result {
+	// Normalise every dependency relationship to parent -> child
+	type edge struct {
+		from, to string
+		scope    core.Scope
+	}
+	var edges []edge
+	described := make(map[string]bool)
+	hasChildren := make(map[string]bool)
+	for _, r := range relationships {
+		switch {
+		case r.Type == "DESCRIBES" && r.From == "SPDXRef-DOCUMENT":
+			described[r.To] = true
+		case r.Type == "DESCRIBED_BY" && r.To == "SPDXRef-DOCUMENT":
+			described[r.From] = true
+		case r.Type == "DEPENDS_ON":
+			edges = append(edges, edge{from: r.From, to: r.To})
+			hasChildren[r.From] = true
+		default:
+			if scope, ok := spdxScopes[r.Type]; ok {
+				edges = append(edges, edge{from: r.To, to: r.From, scope: scope})
+				hasChildren[r.To] = true
+			}
+		}
+	}
+
+	doc := newDocument()
+	project := make(map[string]bool)
+	byID := make(map[string]*component)
+	for i := range packages {
+		p := &packages[i]
+		if described[p.SPDXID] && hasChildren[p.SPDXID] {
+			project[p.SPDXID] = true
+			if doc.name == "" {
+				doc.name, doc.version = p.Name, p.VersionInfo
+			}
+			continue
+		}
+		c := &component{id: p.SPDXID, name: p.Name, version: p.VersionInfo, hashes: make(map[string]string)}
+		for _, ref := range p.ExternalRefs {
+			if ref.ReferenceType == "purl" && c.purl == "" {
+				c.purl = ref.ReferenceLocator
+			}
+		}
+		for _, sum := range p.Checksums {
+			if alg := sriAlgorithm(sum.Algorithm); alg != "" {
+				c.hashes[alg] = sum.ChecksumValue
+			}
+		}
+		byID[p.SPDXID] = c
+		doc.add(c)
+	}
+
+	for _, e := range edges {
+		if !project[e.from] {
+			doc.addEdge(e.from, e.to)
+			continue
+		}
+		doc.roots = append(doc.roots, e.to)
+		if c, ok := byID[e.to]; ok && e.scope != "" {
+			c.scope = e.scope
+		}
+	}
+	return doc.result()
+}
+
+// spdx3ScopedRelationships maps SPDX 3.0 lifecycle scopes on a
+// dependsOn relationship to the equivalent SPDX 2.x relationship.
+var spdx3ScopedRelationships = map[string]string{
+	"runtime":     "RUNTIME_DEPENDENCY_OF",
+	"development": "DEV_DEPENDENCY_OF",
+	"test":        "TEST_DEPENDENCY_OF",
+	"build":       "BUILD_DEPENDENCY_OF",
+}
+
+// spdx3Result builds a result from the @graph of an SPDX 3.0 JSON-LD
+// document, reading software_Package elements and their dependsOn and
+// hasOptionalDependency relationships. The document's root elements
+// play the part DESCRIBES does in 2.x.
+func spdx3Result(graph []json.RawMessage) *core.Result {
+	var packages []spdxPackage
+	var relationships []spdxRelationship
+	for _, raw := range graph {
+		var el struct {
+			Type          string   `json:"type"`
+			SPDXID        string   `json:"spdxId"`
+			Name          string   `json:"name"`
+			Version       string   `json:"software_packageVersion"`
+			PURL          string   `json:"software_packageUrl"`
+			From          string   `json:"from"`
+			To            []string `json:"to"`
+			Relationship  string   `json:"relationshipType"`
+			Scope         string   `json:"scope"`
+			RootElement   []string `json:"rootElement"`
+			VerifiedUsing []struct {
+				Algorithm string `json:"algorithm"`
+				HashValue string `json:"hashValue"`
+			} `json:"verifiedUsing"`
+		}
+		if json.Unmarshal(raw, &el) != nil {
+			continue
+		}
+		switch el.Type {
+		case "software_Package":
+			p := spdxPackage{SPDXID: el.SPDXID, Name: el.Name, VersionInfo: el.Version}
+			if el.PURL != "" {
+				p.ExternalRefs = append(p.ExternalRefs, spdxExternalRef{"purl", el.PURL})
+			}
+			for _, h := range el.VerifiedUsing {
+				p.Checksums = append(p.Checksums, spdxChecksum{h.Algorithm, h.HashValue})
+			}
+			packages = append(packages, p)
+		case "Relationship", "LifecycleScopedRelationship":
+			for _, to := range el.To {
+				switch el.Relationship {
+				case "dependsOn":
+					if kind, ok := spdx3ScopedRelationships[el.Scope]; ok {
+						relationships = append(relationships, spdxRelationship{From: to, Type: kind, To: el.From})
+					} else {
+						relationships = append(relationships, spdxRelationship{From: el.From, Type: "DEPENDS_ON", To: to})
+					}
+				case "hasOptionalDependency":
+					relationships = append(relationships, spdxRelationship{From: to, Type: "OPTIONAL_DEPENDENCY_OF", To: el.From})
+				}
+			}
+		case "SpdxDocument":
+			for _, id := range el.RootElement {
+				relationships = append(relationships, spdxRelationship{From: "SPDXRef-DOCUMENT", Type: "DESCRIBES", To: id})
+			}
+		}
+	}
+
+	return spdxResult(packages, relationships)
+}
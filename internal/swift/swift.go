@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"github.com/git-pkgs/manifests/internal/core"
 	"regexp"
+	"strconv"
 	"strings"
 )
 
@@ -119,6 +120,10 @@ type packageResolvedPinV2 struct {
 	} `json:"state"`
 }
 
+// latestPackageResolvedVersion is the newest Package.resolved format
+// version. Version 3 only adds originHash to the version 2 layout.
+const latestPackageResolvedVersion = 3
+
 func (p *packageResolvedParser) Parse(filename string, content []byte) (*core.Result, error) {
 	// Try to detect version
 	var versionCheck struct {
@@ -127,6 +132,13 @@ func (p *packageResolvedParser) Parse(filename string, content []byte) (*core.Re
 	if err := json.Unmarshal(content, &versionCheck); err != nil {
 		return nil, &core.ParseError{Filename: filename, Err: err}
 	}
+	formatVersion := ""
+	if versionCheck.Version > 0 {
+		formatVersion = strconv.Itoa(versionCheck.Version)
+	}
+	if err := core.CheckFormatVersion(filename, formatVersion, latestPackageResolvedVersion); err != nil {
+		return nil, err
+	}
 
 	const resolvedV2 = 2
 	var deps []core.Dependency
@@ -136,7 +148,10 @@ func (p *packageResolvedParser) Parse(filename string, content []byte) (*core.Re
 	} else {
 		deps, err = parsePackageResolvedV1(filename, content)
 	}
-	return &core.Result{Dependencies: deps}, err
+	if err != nil {
+		return nil, err
+	}
+	return &core.Result{Dependencies: deps, FormatVersion: formatVersion}, nil
 }
 
 func parsePackageResolvedV1(filename string, content []byte) ([]core.Dependency, error) {
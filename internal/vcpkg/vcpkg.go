@@ -3,6 +3,7 @@ package vcpkg
 import (
 	"encoding/json"
 	"github.com/git-pkgs/manifests/internal/core"
+	"strings"
 )
 
 func init() {
@@ -16,7 +17,12 @@ type vcpkgJSON struct {
 	Name          string `json:"name"`
 	Version       string `json:"version"`
 	VersionString string `json:"version-string"`
-	Dependencies  []any  `json:"dependencies"`
+	License       string `json:"license"`
+	Homepage      string `json:"homepage"`
+	// Each of these is a string or an array of strings
+	Description  any   `json:"description"`
+	Maintainers  any   `json:"maintainers"`
+	Dependencies []any `json:"dependencies"`
 }
 
 func (p *vcpkgJSONParser) Parse(filename string, content []byte) (*core.Result, error) {
@@ -56,5 +62,29 @@ func (p *vcpkgJSONParser) Parse(filename string, content []byte) (*core.Result,
 	if version == "" {
 		version = pkg.VersionString
 	}
-	return &core.Result{Name: pkg.Name, Version: version, Dependencies: deps}, nil
+	metadata := core.Metadata{
+		Description: strings.Join(vcpkgStrings(pkg.Description), "\n"),
+		License:     pkg.License,
+		Homepage:    pkg.Homepage,
+		Authors:     vcpkgStrings(pkg.Maintainers),
+	}
+	return &core.Result{Name: pkg.Name, Version: version, Metadata: metadata, Dependencies: deps}, nil
+}
+
+// vcpkgStrings reads a field that's either a string or an array of
+// strings.
+func vcpkgStrings(v any) []string {
+	switch v := v.(type) {
+	case string:
+		return []string{v}
+	case []any:
+		var values []string
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				values = append(values, s)
+			}
+		}
+		return values
+	}
+	return nil
 }
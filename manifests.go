@@ -16,6 +16,11 @@
 package manifests
 
 import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+
 	"github.com/git-pkgs/manifests/internal/core"
 	"github.com/git-pkgs/purl"
 )
@@ -25,6 +30,25 @@ type (
 	Kind       = core.Kind
 	Scope      = core.Scope
 	Dependency = core.Dependency
+	Hash       = core.Hash
+	Position   = core.Position
+	Graph      = core.Graph
+	Node       = core.Node
+	Edge       = core.Edge
+
+	VersionRange    = core.VersionRange
+	VersionInterval = core.VersionInterval
+
+	Diagnostic = core.Diagnostic
+	Severity   = core.Severity
+
+	Metadata = core.Metadata
+
+	Replacement         = core.Replacement
+	Exclusion           = core.Exclusion
+	Retraction          = core.Retraction
+	Requirement         = core.Requirement
+	WorkspaceDefinition = core.WorkspaceDefinition
 )
 
 // Re-export constants.
@@ -38,6 +62,16 @@ const (
 	Test        Scope = core.Test
 	Build       Scope = core.Build
 	Optional    Scope = core.Optional
+
+	SeverityWarning Severity = core.SeverityWarning
+	SeverityInfo    Severity = core.SeverityInfo
+
+	CodeUnresolvedVariable   = core.CodeUnresolvedVariable
+	CodeUnfollowedInclude    = core.CodeUnfollowedInclude
+	CodeSkippedDependency    = core.CodeSkippedDependency
+	CodeNonRegistrySource    = core.CodeNonRegistrySource
+	CodeUnrecognisedLine     = core.CodeUnrecognisedLine
+	CodeUnknownFormatVersion = core.CodeUnknownFormatVersion
 )
 
 // ParseResult contains the parsed dependencies from a manifest or lockfile.
@@ -50,8 +84,44 @@ type ParseResult struct {
 	Name string
 	// Version is the package's own version as declared in the
 	// manifest, when present.
-	Version      string
+	Version string
+	// Metadata is the license, description, links, authors and
+	// keywords the manifest declares for the package. Zero for
+	// lockfiles and for formats that don't declare any.
+	Metadata     Metadata
 	Dependencies []Dependency
+	// Graph holds the parent→child relationships between dependencies
+	// for lockfiles that record them (package-lock.json, pnpm-lock.yaml,
+	// Cargo.lock, poetry.lock, Gemfile.lock, go.graph). Nil otherwise.
+	Graph *Graph
+	// Diagnostics reports input the parser skipped or only partly
+	// understood, in line order, so that a file with no dependencies
+	// can be told apart from one whose dependencies weren't understood.
+	// Empty when everything was read.
+	Diagnostics []Diagnostic
+	// FormatVersion is the lockfile format version the file declares:
+	// lockfileVersion in package-lock.json and pnpm-lock.yaml, the
+	// __metadata version in a Yarn Berry yarn.lock ("1" for classic
+	// Yarn), version in Cargo.lock, uv.lock, Package.resolved and
+	// flake.lock, and lock-version in poetry.lock. Empty for other
+	// formats and for files that don't record one.
+	FormatVersion string
+	// Workspace holds the member directories of go.work's use
+	// directives. Nil for other files; use Workspaces to find the
+	// workspaces in a tree.
+	Workspace *WorkspaceDefinition
+	// Replacements lists replace directives in go.mod and go.work, in
+	// file order. Dependencies they apply to carry the one used in
+	// Dependency.Replacement.
+	Replacements []Replacement
+	// Excludes and Retractions list go.mod's exclude and retract
+	// directives.
+	Excludes    []Exclusion
+	Retractions []Retraction
+	// Runtimes lists the runtime and toolchain versions the manifest
+	// declares it needs, such as package.json engines or go.mod's go
+	// directive. Empty when it declares none.
+	Runtimes []Requirement
 }
 
 // Options configures Parse.
@@ -63,49 +133,176 @@ type Options struct {
 	// filesystem access occurs; this is the safe choice for untrusted
 	// input.
 	FSRoot string
+	// Strict makes Parse fail with a *StrictError instead of returning a
+	// result that has warning diagnostics, i.e. whenever the parser had
+	// to skip or guess at part of the file. Info diagnostics don't
+	// count.
+	Strict bool
+
+	// The limits below bound the work done on untrusted input. Exceeding
+	// one fails the parse with a *LimitError. Zero means no limit.
+
+	// MaxInputSize is the largest file, in bytes, that will be parsed.
+	MaxInputSize int
+	// MaxDependencies is the most dependencies a result may hold. The
+	// package-lock.json, npm-shrinkwrap.json, pnpm-lock.yaml, yarn.lock
+	// and Cargo.lock parsers stop as soon as it's passed; other files are
+	// checked once parsed.
+	MaxDependencies int
+	// MaxDepth is the deepest nesting of YAML documents, pom.xml
+	// elements and package-lock.json v1 dependency trees that will be
+	// decoded.
+	MaxDepth int
+	// MaxAliases is the most YAML alias expansions a document may
+	// need, counting an alias again each time the anchor holding it is
+	// expanded. It guards against "billion laughs" documents.
+	MaxAliases int
+}
+
+// limits returns the Options' resource limits.
+func (o Options) limits() core.Limits {
+	return core.Limits{
+		MaxInputSize:    o.MaxInputSize,
+		MaxDependencies: o.MaxDependencies,
+		MaxDepth:        o.MaxDepth,
+		MaxAliases:      o.MaxAliases,
+	}
 }
 
 // Parse parses a manifest or lockfile and returns its dependencies.
+// It is safe to call from multiple goroutines at once; see ParseAll for
+// parsing many files concurrently.
 func Parse(filename string, content []byte, opts ...Options) (*ParseResult, error) {
+	return ParseContext(context.Background(), filename, content, opts...)
+}
+
+// ParseContext is Parse with a context. The large lockfile parsers
+// (package-lock.json, npm-shrinkwrap.json, pnpm-lock.yaml, yarn.lock
+// and Cargo.lock) check ctx and MaxDependencies as they go, so
+// cancelling stops them part way; pom.xml passes ctx on to parent
+// resolution. Other parsers are quick enough that ctx is only checked
+// before they start and after they return, and MaxDependencies against
+// their finished result. MaxInputSize, MaxDepth and MaxAliases are
+// checked before the content is decoded.
+func ParseContext(ctx context.Context, filename string, content []byte, opts ...Options) (*ParseResult, error) {
 	var o Options
 	if len(opts) > 0 {
 		o = opts[0]
 	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 
 	parser, eco, kind := core.IdentifyParser(filename)
 	if parser == nil {
 		return nil, &UnknownFileError{Filename: filename}
 	}
 
+	limits := o.limits()
+	if limits.MaxInputSize > 0 && len(content) > limits.MaxInputSize {
+		return nil, &LimitError{Filename: filename, Err: ErrInputTooLarge, Limit: limits.MaxInputSize}
+	}
+
 	var res *core.Result
 	var err error
-	if fp, ok := parser.(core.FSRootParser); ok {
-		res, err = fp.ParseInRoot(filename, content, o.FSRoot)
-	} else {
+	switch p := parser.(type) {
+	case core.FSRootParser:
+		res, err = p.ParseInRoot(ctx, filename, content, o.FSRoot, limits)
+	case core.LimitedParser:
+		res, err = p.ParseLimited(ctx, filename, content, limits)
+	default:
 		res, err = parser.Parse(filename, content)
 	}
 	if err != nil {
 		return nil, err
 	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 	if res == nil {
 		res = &core.Result{}
 	}
+	if limits.MaxDependencies > 0 && len(res.Dependencies) > limits.MaxDependencies {
+		return nil, &LimitError{Filename: filename, Err: ErrTooManyDependencies, Limit: limits.MaxDependencies}
+	}
 
-	// Generate PURLs for all dependencies
+	// Generate PURLs and version ranges for all dependencies and tag
+	// positions with the file. Parsers that read PURLs from the file
+	// (SBOMs) set them already.
 	for i := range res.Dependencies {
+		dep := &res.Dependencies[i]
+		if dep.Position.IsValid() {
+			dep.Position.File = filename
+		}
+		depEco := eco
+		if dep.Ecosystem != "" {
+			depEco = dep.Ecosystem
+		}
 		version := ""
 		if kind == Lockfile || kind == Supplement {
-			version = res.Dependencies[i].Version
+			version = dep.Version
+			dep.Range = core.ExactVersionRange(depEco, dep.Version)
+		} else {
+			dep.Range = core.ParseVersionRange(depEco, dep.Version)
+		}
+		if dep.PURL == "" {
+			dep.PURL = makePURL(depEco, dep.Name, version, dep.RegistryURL)
+		}
+	}
+
+	for i := range res.Replacements {
+		if res.Replacements[i].Position.IsValid() {
+			res.Replacements[i].Position.File = filename
+		}
+	}
+	for i := range res.Excludes {
+		if res.Excludes[i].Position.IsValid() {
+			res.Excludes[i].Position.File = filename
+		}
+	}
+	for i := range res.Retractions {
+		if res.Retractions[i].Position.IsValid() {
+			res.Retractions[i].Position.File = filename
+		}
+	}
+
+	// Parsers that walk maps report diagnostics in no particular order.
+	// Those without a line go last.
+	sort.SliceStable(res.Diagnostics, func(i, j int) bool {
+		a, b := res.Diagnostics[i], res.Diagnostics[j]
+		if a.Line != b.Line {
+			return b.Line == 0 || (a.Line != 0 && a.Line < b.Line)
+		}
+		return a.Message < b.Message
+	})
+
+	if o.Strict {
+		var warnings []Diagnostic
+		for _, d := range res.Diagnostics {
+			if d.Severity == SeverityWarning {
+				warnings = append(warnings, d)
+			}
+		}
+		if len(warnings) > 0 {
+			return nil, &StrictError{Filename: filename, Diagnostics: warnings}
 		}
-		res.Dependencies[i].PURL = makePURL(eco, res.Dependencies[i].Name, version, res.Dependencies[i].RegistryURL)
 	}
 
 	return &ParseResult{
-		Ecosystem:    eco,
-		Kind:         kind,
-		Name:         res.Name,
-		Version:      res.Version,
-		Dependencies: res.Dependencies,
+		Ecosystem:     eco,
+		Kind:          kind,
+		Name:          res.Name,
+		Version:       res.Version,
+		Metadata:      res.Metadata,
+		Dependencies:  res.Dependencies,
+		Graph:         res.Graph,
+		Diagnostics:   res.Diagnostics,
+		FormatVersion: res.FormatVersion,
+		Workspace:     res.Workspace,
+		Replacements:  res.Replacements,
+		Excludes:      res.Excludes,
+		Retractions:   res.Retractions,
+		Runtimes:      res.Runtimes,
 	}, nil
 }
 
@@ -131,7 +328,9 @@ func IdentifyAll(filename string) []Match {
 	return core.IdentifyAllParsers(filename)
 }
 
-// Ecosystems returns a list of all supported PURL ecosystem types.
+// Ecosystems returns a list of all supported PURL ecosystem types. SBOM
+// formats, which Parse reads as "cyclonedx" and "spdx" lockfiles, list
+// packages from many ecosystems and aren't included.
 func Ecosystems() []string {
 	return core.SupportedEcosystems()
 }
@@ -145,5 +344,44 @@ func (e *UnknownFileError) Error() string {
 	return "unknown manifest file: " + e.Filename
 }
 
+// StrictError is returned by Parse in strict mode when the parser
+// skipped or couldn't understand part of the file.
+type StrictError struct {
+	Filename string
+	// Diagnostics holds the warnings, in line order.
+	Diagnostics []Diagnostic
+}
+
+func (e *StrictError) Error() string {
+	d := e.Diagnostics[0]
+	msg := "strict: " + e.Filename
+	if d.Line > 0 {
+		msg += ":" + strconv.Itoa(d.Line)
+	}
+	msg += ": " + d.Message
+	if n := len(e.Diagnostics) - 1; n > 0 {
+		msg += fmt.Sprintf(" (and %d more)", n)
+	}
+	return msg
+}
+
 // ParseError is re-exported from internal/core.
 type ParseError = core.ParseError
+
+// LimitError is returned by Parse when a file exceeds one of the
+// Options limits. It wraps ErrInputTooLarge, ErrTooManyDependencies,
+// ErrTooDeep or ErrTooManyAliases.
+type LimitError = core.LimitError
+
+// Errors wrapped by LimitError, for use with errors.Is.
+var (
+	ErrInputTooLarge       = core.ErrInputTooLarge
+	ErrTooManyDependencies = core.ErrTooManyDependencies
+	ErrTooDeep             = core.ErrTooDeep
+	ErrTooManyAliases      = core.ErrTooManyAliases
+)
+
+// UnsupportedFormatVersionError is returned by Parse when a lockfile
+// declares a format version newer than the parser knows, rather than
+// reading it with older rules and possibly missing dependencies.
+type UnsupportedFormatVersionError = core.UnsupportedFormatVersionError
@@ -1,8 +1,12 @@
 package manifests
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
 
@@ -137,6 +141,31 @@ func TestParseName(t *testing.T) {
 	}
 }
 
+func TestEcosystems(t *testing.T) {
+	ecosystems := Ecosystems()
+	seen := make(map[string]bool)
+	for _, eco := range ecosystems {
+		if seen[eco] {
+			t.Errorf("%s listed twice", eco)
+		}
+		seen[eco] = true
+	}
+	for _, eco := range []string{"npm", "golang", "maven"} {
+		if !seen[eco] {
+			t.Errorf("%s missing from %v", eco, ecosystems)
+		}
+	}
+	// SBOMs are formats, not ecosystems, though Parse still reads them
+	for _, format := range []string{"cyclonedx", "spdx"} {
+		if seen[format] {
+			t.Errorf("%s listed as an ecosystem", format)
+		}
+	}
+	if _, err := Parse("bom.json", []byte(`{"bomFormat": "CycloneDX", "specVersion": "1.5", "components": []}`)); err != nil {
+		t.Errorf("Parse bom.json: %v", err)
+	}
+}
+
 func TestIdentifyFiles(t *testing.T) {
 	testCases := []struct {
 		filename  string
@@ -192,6 +221,13 @@ func TestIdentifyFiles(t *testing.T) {
 		{"Dockerfile", "docker", Manifest, true},
 		{"docker-compose.yml", "docker", Manifest, true},
 
+		// sboms
+		{"bom.json", "cyclonedx", Lockfile, true},
+		{"app.cdx.json", "cyclonedx", Lockfile, true},
+		{"bom.xml", "cyclonedx", Lockfile, true},
+		{"app.spdx.json", "spdx", Lockfile, true},
+		{"app.spdx", "spdx", Lockfile, true},
+
 		// unknown
 		{"unknown.txt", "", "", false},
 		{"random.file", "", "", false},
@@ -270,6 +306,564 @@ func TestIntegrity(t *testing.T) {
 	}
 }
 
+func TestGraph(t *testing.T) {
+	testCases := []struct {
+		path     string
+		hasGraph bool
+	}{
+		{"testdata/npm/package-lock.json", true},
+		{"testdata/npm/pnpm-lock.yaml", true},
+		{"testdata/cargo/Cargo.lock", true},
+		{"testdata/gem/Gemfile.lock", true},
+		{"testdata/pypi/poetry.lock", true},
+		{"testdata/golang/go.graph", true},
+		{"testdata/npm/package.json", false},
+		{"testdata/npm/yarn.lock", false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.path, func(t *testing.T) {
+			content, err := os.ReadFile(tc.path)
+			if err != nil {
+				t.Skipf("fixture not found: %v", err)
+			}
+			result, err := Parse(filepath.Base(tc.path), content)
+			if err != nil {
+				t.Fatalf("Parse failed: %v", err)
+			}
+			if (result.Graph != nil) != tc.hasGraph {
+				t.Errorf("Graph present = %v, want %v", result.Graph != nil, tc.hasGraph)
+			}
+		})
+	}
+}
+
+func TestGraphPath(t *testing.T) {
+	content, err := os.ReadFile("testdata/npm/package-lock.json")
+	if err != nil {
+		t.Skipf("fixture not found: %v", err)
+	}
+
+	result, err := Parse("package-lock.json", content)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	// Every locked package should be explainable from some root
+	for _, d := range result.Dependencies {
+		path := result.Graph.Path(d.Name, d.Version)
+		if len(path) == 0 {
+			t.Errorf("no path to %s@%s", d.Name, d.Version)
+			continue
+		}
+		if last := path[len(path)-1]; last.Name != d.Name || last.Version != d.Version {
+			t.Errorf("Path(%s@%s) ends at %v", d.Name, d.Version, last)
+		}
+	}
+
+	var nilGraph *Graph
+	if p := nilGraph.Path("express", ""); p != nil {
+		t.Errorf("nil Graph Path = %v, want nil", p)
+	}
+}
+
+func TestDependencyPositions(t *testing.T) {
+	testCases := []struct {
+		path string
+		dep  string
+		line int
+		raw  string
+	}{
+		{"testdata/gem/Gemfile", "rails", 5, `gem "rails", "4.2.0"`},
+		{"testdata/pypi/requirements.txt", "zope.component", 3, "zope.component==4.2.2"},
+		{"testdata/golang/go.mod", "github.com/gomodule/redigo", 11, "github.com/gomodule/redigo v2.0.0+incompatible // indirect"},
+		{"testdata/docker/Dockerfile", "ruby", 1, "FROM ruby:3.1.2-alpine"},
+		{"testdata/maven/build.gradle", "com.squareup.okhttp:okhttp", 119, "compile 'com.squareup.okhttp:okhttp:2.1.0'"},
+		{"testdata/npm/package.json", "@some-scope/actual-package", 18, `"alias-package-name": "npm:@some-scope/actual-package@^1.1.3"`},
+		{"testdata/cargo/Cargo.toml", "regex", 11, `regex = {version = "*"}`},
+		{"testdata/composer/composer.json", "phpunit/phpunit", 12, `"phpunit/phpunit": "~4.0"`},
+		{"testdata/gem/devise.gemspec", "warden", 21, `s.add_dependency("warden", "~> 1.2.3")`},
+		{"testdata/maven/pom.xml", "org.glassfish.jersey.core:jersey-server", 109, `<dependency>
+            <groupId>org.glassfish.jersey.core</groupId>
+            <artifactId>jersey-server</artifactId>
+            <version>${jersey.version}</version>
+        </dependency>`},
+		// groupId is ${project.groupId}, located by artifactId
+		{"testdata/maven/pom.xml", "org.accidia:echo-parent", 103, "-"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.path+"/"+tc.dep, func(t *testing.T) {
+			content, err := os.ReadFile(tc.path)
+			if err != nil {
+				t.Skipf("fixture not found: %v", err)
+			}
+			filename := filepath.Base(tc.path)
+			result, err := Parse(filename, content)
+			if err != nil {
+				t.Fatalf("Parse failed: %v", err)
+			}
+
+			for _, d := range result.Dependencies {
+				if d.Name != tc.dep {
+					continue
+				}
+				if d.Position.File != filename {
+					t.Errorf("File = %q, want %q", d.Position.File, filename)
+				}
+				if d.Position.StartLine != tc.line {
+					t.Errorf("StartLine = %d, want %d", d.Position.StartLine, tc.line)
+				}
+				if tc.raw != "-" && d.Raw != tc.raw {
+					t.Errorf("Raw = %q, want %q", d.Raw, tc.raw)
+				}
+				// Raw must be exactly the text at Position
+				lines := strings.Split(string(content), "\n")
+				first := lines[d.Position.StartLine-1]
+				if !strings.HasPrefix(first[d.Position.StartColumn-1:], strings.SplitN(d.Raw, "\n", 2)[0]) {
+					t.Errorf("Raw %q does not start at line %d column %d", d.Raw, d.Position.StartLine, d.Position.StartColumn)
+				}
+				last := lines[d.Position.EndLine-1]
+				if !strings.HasSuffix(last[:d.Position.EndColumn-1], d.Raw[strings.LastIndex(d.Raw, "\n")+1:]) {
+					t.Errorf("Raw %q does not end at line %d column %d", d.Raw, d.Position.EndLine, d.Position.EndColumn)
+				}
+				return
+			}
+			t.Errorf("dependency %s not found", tc.dep)
+		})
+	}
+}
+
+func TestDiagnostics(t *testing.T) {
+	content := []byte(`[package]
+name = "app"
+
+[dependencies]
+zeta = { path = "../zeta" }
+serde = "1.0"
+alpha = { path = "../alpha" }
+`)
+	result, err := Parse("Cargo.toml", content)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if len(result.Dependencies) != 1 {
+		t.Errorf("expected 1 dependency, got %d", len(result.Dependencies))
+	}
+	// The parser walks a map; Parse orders diagnostics by line
+	if len(result.Diagnostics) != 2 || result.Diagnostics[0].Line != 5 || result.Diagnostics[1].Line != 7 {
+		t.Fatalf("unexpected diagnostics %+v", result.Diagnostics)
+	}
+	for _, d := range result.Diagnostics {
+		if d.Severity != SeverityInfo || d.Code != CodeSkippedDependency {
+			t.Errorf("unexpected diagnostic %+v", d)
+		}
+	}
+
+	result, err = Parse("Cargo.toml", []byte("[dependencies]\nserde = \"1.0\"\n"))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if len(result.Diagnostics) != 0 {
+		t.Errorf("expected no diagnostics, got %+v", result.Diagnostics)
+	}
+}
+
+func TestStrict(t *testing.T) {
+	content := []byte("ARG BASE\nFROM ${BASE}\nFROM nginx:1.25\n")
+
+	result, err := Parse("Dockerfile", content)
+	if err != nil {
+		t.Fatalf("lenient Parse failed: %v", err)
+	}
+	if len(result.Dependencies) != 1 || len(result.Diagnostics) != 1 {
+		t.Errorf("expected 1 dependency and 1 diagnostic, got %+v", result)
+	}
+
+	_, err = Parse("Dockerfile", content, Options{Strict: true})
+	var strictErr *StrictError
+	if !errors.As(err, &strictErr) {
+		t.Fatalf("expected *StrictError, got %v", err)
+	}
+	if strictErr.Filename != "Dockerfile" || len(strictErr.Diagnostics) != 1 || strictErr.Diagnostics[0].Line != 2 {
+		t.Errorf("unexpected error %+v", strictErr)
+	}
+	if want := "strict: Dockerfile:2: base image ${BASE} depends on a build argument"; err.Error() != want {
+		t.Errorf("Error() = %q, want %q", err.Error(), want)
+	}
+
+	// Info diagnostics don't fail strict parsing
+	cargo := []byte("[dependencies]\nlocal = { path = \"../local\" }\n")
+	result, err = Parse("Cargo.toml", cargo, Options{Strict: true})
+	if err != nil {
+		t.Fatalf("strict Parse failed: %v", err)
+	}
+	if len(result.Diagnostics) != 1 {
+		t.Errorf("expected the info diagnostic to be kept, got %+v", result.Diagnostics)
+	}
+}
+
+// TestStrictSources checks that dependencies a parser can't resolve to
+// registry packages fail strict parsing.
+func TestStrictSources(t *testing.T) {
+	testCases := []struct {
+		filename string
+		content  string
+		code     string
+		line     int
+	}{
+		{"Gemfile", "source 'https://rubygems.org'\ngem 'rails'\neval_gemfile 'Gemfile.local'\n", CodeUnfollowedInclude, 3},
+		{"Gemfile", "eval_gemfile(File.join(__dir__, 'plugins.rb'))\n", CodeUnfollowedInclude, 1},
+		{"package.json", `{"dependencies": {"react": "^18.0.0", "local": "file:../local"}}`, CodeNonRegistrySource, 1},
+		{"package.json", "{\n  \"devDependencies\": {\n    \"lib\": \"git+https://github.com/acme/lib.git#v1\"\n  }\n}", CodeNonRegistrySource, 3},
+		{"package.json", `{"dependencies": {"lib": "acme/lib#main"}}`, CodeNonRegistrySource, 1},
+		{"Pipfile", "[packages]\nrequests = \"*\"\nmine = {path = \".\", editable = true}\n", CodeNonRegistrySource, 0},
+		{"Pipfile", "[dev-packages]\nlib = {git = \"https://github.com/acme/lib.git\", ref = \"main\"}\n", CodeNonRegistrySource, 0},
+		{"setup.py", "from setuptools import setup\n\nREQUIRES = ['requests']\nsetup(\n    name='x',\n    install_requires=REQUIRES,\n)\n", CodeUnresolvedVariable, 6},
+		{"setup.py", "setup(\n    install_requires=[\n        'requests',\n    ] ,\n    extras_require=extras,\n)\n", CodeUnresolvedVariable, 5},
+		{"setup.py", "setup(install_requires=['requests', *BASE])\n", CodeUnresolvedVariable, 1},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.filename, func(t *testing.T) {
+			_, err := Parse(tc.filename, []byte(tc.content), Options{Strict: true})
+			var strictErr *StrictError
+			if !errors.As(err, &strictErr) {
+				t.Fatalf("expected *StrictError, got %v", err)
+			}
+			if len(strictErr.Diagnostics) != 1 {
+				t.Fatalf("expected 1 warning, got %+v", strictErr.Diagnostics)
+			}
+			if d := strictErr.Diagnostics[0]; d.Code != tc.code || d.Line != tc.line {
+				t.Errorf("expected %s at line %d, got %+v", tc.code, tc.line, d)
+			}
+		})
+	}
+
+	// Registry requirements and workspace members stay strict-clean
+	clean := `{"dependencies": {"a": "^1.0.0", "b": "npm:c@^2", "d": "workspace:*", "e": "latest"}}`
+	if _, err := Parse("package.json", []byte(clean), Options{Strict: true}); err != nil {
+		t.Errorf("strict Parse failed: %v", err)
+	}
+}
+
+func TestMetadata(t *testing.T) {
+	testCases := []struct {
+		path string
+		want Metadata
+	}{
+		{"testdata/npm/package.json", Metadata{
+			License:     "MIT",
+			Description: "Integrate GH webhooks with Libraries.io",
+			Authors:     []string{"Mauro Pompilio"},
+		}},
+		{"testdata/maven/pom.xml", Metadata{
+			License:     "Accidia License",
+			Description: "Echo provides a simple interface to store and retrieve generic protocol buffer objects",
+			Homepage:    "https://github.com/accidia/echo",
+			Repository:  "https://github.com/accidia/echo.git",
+			Authors:     []string{"Paymon Teyer <id at accidia.org>"},
+		}},
+		{"testdata/composer/composer.json", Metadata{
+			License:     "MIT",
+			Description: "The Laravel Framework.",
+			Keywords:    []string{"framework", "laravel"},
+		}},
+		{"testdata/gem/devise.gemspec", Metadata{
+			License:     "MIT",
+			Description: "Flexible authentication solution for Rails with Warden",
+			Homepage:    "https://github.com/plataformatec/devise",
+			Authors:     []string{"José Valim", "Carlos Antônio"},
+		}},
+		{"testdata/nuget/example.nuspec", Metadata{
+			Description: "Shared libraries for runtime and deployment packaging of .Net applications",
+			Homepage:    "http://fubumvc.com",
+			Authors:     []string{"Jeremy D. Miller", "Dru Sellers", "et al."},
+			Keywords:    []string{"packaging", "areas", "slices"},
+		}},
+		{"testdata/cran/DESCRIPTION", Metadata{
+			License:     "GPL-2",
+			Description: "An Implementation of the Grammar of Graphics",
+			Homepage:    "http://ggplot2.org",
+			Repository:  "https://github.com/hadley/ggplot2",
+			Authors:     []string{"Hadley Wickham <hadley@rstudio.com>", "Winston Chang <winston@rstudio.com>"},
+		}},
+		{"testdata/arch/PKGBUILD", Metadata{
+			License:     "GPL3",
+			Description: "The GNU Hello program",
+			Homepage:    "https://www.gnu.org/software/hello/",
+		}},
+		{"testdata/npm/package-lock.json", Metadata{}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.path, func(t *testing.T) {
+			content, err := os.ReadFile(tc.path)
+			if err != nil {
+				t.Fatalf("failed to read fixture: %v", err)
+			}
+			result, err := Parse(filepath.Base(tc.path), content)
+			if err != nil {
+				t.Fatalf("Parse failed: %v", err)
+			}
+			if !reflect.DeepEqual(result.Metadata, tc.want) {
+				t.Errorf("Metadata = %+v\nwant %+v", result.Metadata, tc.want)
+			}
+		})
+	}
+}
+
+func TestFormatVersion(t *testing.T) {
+	testCases := []struct {
+		path     string
+		filename string
+		want     string
+	}{
+		{"testdata/npm/npm-lockfile-version-2/package-lock.json", "package-lock.json", "2"},
+		{"testdata/npm/pnpm-lockfile-version-9/pnpm-lock.yaml", "pnpm-lock.yaml", "9.0"},
+		{"testdata/npm/yarn.lock", "yarn.lock", "1"},
+		{"testdata/npm/yarn-v4-lockfile/yarn.lock", "yarn.lock", "8"},
+		{"testdata/cargo/Cargo.lock", "Cargo.lock", "3"},
+		{"testdata/pypi/poetry.lock", "poetry.lock", "2.1"},
+		{"testdata/pypi/uv.lock", "uv.lock", "1"},
+		{"testdata/swift/Package.resolved.2", "Package.resolved", "2"},
+		{"testdata/nix/flake.lock", "flake.lock", "7"},
+		{"testdata/npm/package.json", "package.json", ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.path, func(t *testing.T) {
+			content, err := os.ReadFile(tc.path)
+			if err != nil {
+				t.Fatalf("failed to read fixture: %v", err)
+			}
+			result, err := Parse(tc.filename, content)
+			if err != nil {
+				t.Fatalf("Parse failed: %v", err)
+			}
+			if result.FormatVersion != tc.want {
+				t.Errorf("FormatVersion = %q, want %q", result.FormatVersion, tc.want)
+			}
+		})
+	}
+}
+
+func TestUnsupportedFormatVersion(t *testing.T) {
+	testCases := []struct {
+		filename string
+		content  string
+		version  string
+	}{
+		{"Cargo.lock", "version = 5\n\n[[package]]\nname = \"serde\"\nversion = \"1.0.0\"\n", "5"},
+		{"poetry.lock", "[metadata]\nlock-version = \"3.0\"\n", "3.0"},
+		{"uv.lock", "version = 2\n", "2"},
+		{"Package.resolved", `{"pins": [], "version": 4}`, "4"},
+		{"flake.lock", `{"nodes": {}, "root": "root", "version": 8}`, "8"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.filename, func(t *testing.T) {
+			_, err := Parse(tc.filename, []byte(tc.content))
+			var unsupported *UnsupportedFormatVersionError
+			if !errors.As(err, &unsupported) {
+				t.Fatalf("expected *UnsupportedFormatVersionError, got %v", err)
+			}
+			if unsupported.Filename != tc.filename || unsupported.Version != tc.version {
+				t.Errorf("unexpected error %+v", unsupported)
+			}
+		})
+	}
+}
+
+// laughs returns a docker-compose file whose anchors each alias the
+// previous one ten times, expanding to 10^levels strings.
+func laughs(levels int) string {
+	var b strings.Builder
+	b.WriteString("x-0: &l0 lol\n")
+	for i := 1; i <= levels; i++ {
+		fmt.Fprintf(&b, "x-%d: &l%d [", i, i)
+		for j := range 10 {
+			if j > 0 {
+				b.WriteString(", ")
+			}
+			fmt.Fprintf(&b, "*l%d", i-1)
+		}
+		b.WriteString("]\n")
+	}
+	b.WriteString("services:\n  web:\n    image: nginx:1.25\n")
+	return b.String()
+}
+
+func TestLimits(t *testing.T) {
+	deepLock := `{"lockfileVersion": 1, "dependencies": {"a": {"version": "1.0.0", "dependencies": {"b": {"version": "1.0.0", "dependencies": {"c": {"version": "1.0.0"}}}}}}}`
+	deepYAML := "services:\n  web:\n    image: nginx\n    labels:\n      a:\n        b:\n          c: d\n"
+	pomXML := `<project><groupId>g</groupId><artifactId>a</artifactId><version>1</version><dependencies><dependency><groupId>g</groupId><artifactId>b</artifactId><version>1.0</version></dependency></dependencies></project>`
+
+	testCases := []struct {
+		name     string
+		filename string
+		content  string
+		opts     Options
+		want     error
+	}{
+		{"input size", "package.json", `{"dependencies": {"react": "^18.0.0"}}`, Options{MaxInputSize: 10}, ErrInputTooLarge},
+		{"dependencies", "Gemfile", "gem 'rails'\ngem 'puma'\ngem 'pg'\n", Options{MaxDependencies: 2}, ErrTooManyDependencies},
+		{"package-lock v1 depth", "package-lock.json", deepLock, Options{MaxDepth: 5}, ErrTooDeep},
+		{"yaml depth", "docker-compose.yml", deepYAML, Options{MaxDepth: 4}, ErrTooDeep},
+		{"yaml aliases", "docker-compose.yml", laughs(9), Options{MaxAliases: 1000}, ErrTooManyAliases},
+		{"pom depth", "pom.xml", pomXML, Options{MaxDepth: 3}, ErrTooDeep},
+		{"pom within limits", "pom.xml", pomXML, Options{MaxDependencies: 1, MaxDepth: 4}, nil},
+		{"within limits", "package-lock.json", deepLock, Options{MaxDepth: 8, MaxDependencies: 3, MaxInputSize: 1 << 10}, nil},
+		{"yaml within limits", "docker-compose.yml", laughs(2), Options{MaxDepth: 3, MaxAliases: 120}, nil},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := Parse(tc.filename, []byte(tc.content), tc.opts)
+			if tc.want == nil {
+				if err != nil {
+					t.Fatalf("Parse failed: %v", err)
+				}
+				if len(result.Dependencies) == 0 {
+					t.Errorf("expected dependencies")
+				}
+				return
+			}
+			var limitErr *LimitError
+			if !errors.As(err, &limitErr) || !errors.Is(err, tc.want) {
+				t.Fatalf("expected *LimitError wrapping %v, got %v", tc.want, err)
+			}
+			if limitErr.Filename != tc.filename || limitErr.Limit == 0 {
+				t.Errorf("unexpected error %+v", limitErr)
+			}
+
+			// Without limits the same input parses
+			if _, err := Parse(tc.filename, []byte(tc.content)); err != nil && tc.want != ErrTooManyAliases {
+				t.Errorf("unlimited Parse failed: %v", err)
+			}
+		})
+	}
+}
+
+func TestParseContextCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := ParseContext(ctx, "package.json", []byte(`{}`))
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+}
+
+func TestVersionRange(t *testing.T) {
+	testCases := []struct {
+		filename string
+		content  string
+		dep      string
+		vers     string
+		in       []string
+		out      []string
+	}{
+		{"package.json", `{"dependencies": {"lodash": "^4.17.0"}}`, "lodash",
+			"vers:npm/>=4.17.0|<5.0.0", []string{"4.17.0", "4.17.21"}, []string{"4.16.9", "5.0.0"}},
+		{"package.json", `{"dependencies": {"a": ">=1 <2 || 3.x"}}`, "a",
+			"vers:npm/>=1.0.0|<2.0.0|>=3.0.0|<4.0.0", []string{"1.5.0", "3.2.0"}, []string{"2.0.0", "4.0.0"}},
+		{"go.mod", "module m\n\nrequire example.com/a v1.2.3\n", "example.com/a",
+			"vers:golang/>=v1.2.3", []string{"v1.2.3", "v1.2.4", "v1.10.0"}, []string{"v1.2.2", "v0.9.0"}},
+		{"Gemfile", "gem 'rails', '~> 7.1'\n", "rails",
+			"vers:gem/>=7.1|<8.0", []string{"7.1.0", "7.2.3"}, []string{"7.0.9", "8.0.0"}},
+		{"Gemfile", "gem 'rails', '~> 7.0', '>= 7.0.1', require: false\n", "rails",
+			"", []string{"7.0.1", "7.9"}, []string{"7.0.0", "8.0"}},
+		{"requirements.txt", "requests>=2.0,<3,!=2.5.0\n", "requests",
+			"", []string{"2.0", "2.31.0"}, []string{"1.9", "2.5.0", "3.0"}},
+		{"requirements.txt", "django==4.2.*\n", "django",
+			"", []string{"4.2.0", "4.2.11"}, []string{"4.1.0", "4.3.0"}},
+		{"Cargo.toml", "[dependencies]\nserde = \"1.0\"\n", "serde",
+			"vers:cargo/>=1.0.0|<2.0.0", []string{"1.0.0", "1.0.190"}, []string{"0.9.0", "2.0.0"}},
+		{"Cargo.toml", "[dependencies]\nserde = \"1.2.*\"\n", "serde",
+			"vers:cargo/>=1.2.0|<1.3.0", []string{"1.2.0", "1.2.9"}, []string{"1.1.9", "1.3.0"}},
+		{"Cargo.toml", "[dependencies]\nserde = \"1.*\"\n", "serde",
+			"vers:cargo/>=1.0.0|<2.0.0", []string{"1.0.0", "1.9.0"}, []string{"0.9.0", "2.0.0"}},
+		{"Cargo.toml", "[dependencies]\nserde = \"=1.2\"\n", "serde",
+			"vers:cargo/>=1.2.0|<1.3.0", []string{"1.2.0", "1.2.7"}, []string{"1.1.0", "1.3.0"}},
+		{"Cargo.toml", "[dependencies]\nserde = \"=1\"\n", "serde",
+			"vers:cargo/>=1.0.0|<2.0.0", []string{"1.0.0", "1.5.0"}, []string{"0.9.0", "2.0.0"}},
+		{"Cargo.toml", "[dependencies]\nserde = \"=1.2.3\"\n", "serde",
+			"vers:cargo/1.2.3", []string{"1.2.3"}, []string{"1.2.4"}},
+		{"Cargo.toml", "[dependencies]\nserde = \">=1.2, 1.4.*\"\n", "serde",
+			"", []string{"1.4.0", "1.4.5"}, []string{"1.3.0", "1.5.0"}},
+		{"composer.json", `{"require": {"monolog/monolog": "~1.2"}}`, "monolog/monolog",
+			"", []string{"1.2.0", "1.9.0"}, []string{"1.1.0", "2.0.0"}},
+		{"pom.xml", "<project><dependencies><dependency><groupId>g</groupId><artifactId>a</artifactId><version>[1.0,2.0)</version></dependency></dependencies></project>", "g:a",
+			"vers:maven/>=1.0|<2.0", []string{"1.0", "1.5"}, []string{"0.9", "2.0"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.filename+"/"+tc.dep, func(t *testing.T) {
+			result, err := Parse(tc.filename, []byte(tc.content))
+			if err != nil {
+				t.Fatalf("Parse failed: %v", err)
+			}
+			for _, d := range result.Dependencies {
+				if d.Name != tc.dep {
+					continue
+				}
+				if d.Range == nil {
+					t.Fatalf("Range is nil for %q", d.Version)
+				}
+				if tc.vers != "" && d.Range.VERS != tc.vers {
+					t.Errorf("VERS = %q, want %q", d.Range.VERS, tc.vers)
+				}
+				for _, v := range tc.in {
+					if !d.Range.Satisfies(v) {
+						t.Errorf("%q should satisfy %q", v, d.Version)
+					}
+				}
+				for _, v := range tc.out {
+					if d.Range.Satisfies(v) {
+						t.Errorf("%q should not satisfy %q", v, d.Version)
+					}
+				}
+				return
+			}
+			t.Errorf("dependency %s not found", tc.dep)
+		})
+	}
+}
+
+func TestVersionRangeUnparseable(t *testing.T) {
+	content := []byte(`{"dependencies": {"a": "latest", "b": "git+https://github.com/x/b.git", "c": "file:../c", "d": ""}}`)
+	result, err := Parse("package.json", content)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	for _, d := range result.Dependencies {
+		switch d.Name {
+		case "d":
+			if d.Range == nil || !d.Range.Satisfies("1.0.0") {
+				t.Errorf("empty requirement should match any version, got %+v", d.Range)
+			}
+		default:
+			if d.Range != nil {
+				t.Errorf("%s: expected nil Range for %q, got %s", d.Name, d.Version, d.Range.VERS)
+			}
+		}
+	}
+
+	lock, err := os.ReadFile("testdata/cargo/Cargo.lock")
+	if err != nil {
+		t.Fatalf("failed to read fixture: %v", err)
+	}
+	result, err = Parse("Cargo.lock", lock)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	for _, d := range result.Dependencies {
+		if d.Range == nil || len(d.Range.Intervals) != 1 || !d.Range.Satisfies(d.Version) {
+			t.Errorf("%s: lockfile range should hold exactly %s, got %+v", d.Name, d.Version, d.Range)
+		}
+	}
+}
+
 func TestPURL(t *testing.T) {
 	// Test PURL generation
 	content, err := os.ReadFile("testdata/npm/package-lock.json")
@@ -293,6 +887,34 @@ func TestPURL(t *testing.T) {
 	t.Error("express dependency not found")
 }
 
+func TestSBOMPURL(t *testing.T) {
+	content := []byte(`{
+  "spdxVersion": "SPDX-2.3",
+  "packages": [
+    {"SPDXID": "SPDXRef-a", "name": "a", "versionInfo": "1.0.0"},
+    {"SPDXID": "SPDXRef-b", "name": "b", "versionInfo": "2.0.0",
+     "externalRefs": [{"referenceType": "purl", "referenceLocator": "pkg:npm/b@2.0.0?repository_url=https://npm.example.com/"}]}
+  ]
+}`)
+	result, err := Parse("app.spdx.json", content)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	want := map[string]string{
+		"a": "pkg:generic/a@1.0.0",
+		"b": "pkg:npm/b@2.0.0?repository_url=https://npm.example.com/",
+	}
+	for _, d := range result.Dependencies {
+		if d.PURL != want[d.Name] {
+			t.Errorf("%s PURL = %q, want %q", d.Name, d.PURL, want[d.Name])
+		}
+		if d.Range == nil {
+			t.Errorf("%s: expected a version range", d.Name)
+		}
+	}
+}
+
 func TestRegistryURLNotIncludedForDefaultRegistry(t *testing.T) {
 	// Test that default registry URLs don't add repository_url qualifier
 	testCases := []struct {
@@ -0,0 +1,318 @@
+package manifests
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// Project joins a manifest with the lockfile that resolves it.
+type Project struct {
+	Ecosystem string
+	// Dir is the slash-separated directory holding the files, as
+	// reported by Scan. Empty when built with Pair.
+	Dir string
+	// Name and Version are the package's own identity from the manifest.
+	Name    string
+	Version string
+	// Manifest and Lockfile are the inputs. Either may be nil.
+	Manifest *ParseResult
+	Lockfile *ParseResult
+	// Supplements are the sibling supplement files (go.sum) that
+	// Projects applied to the manifest.
+	Supplements []*ParseResult
+	// MissingIntegrity lists manifest dependencies the supplements had
+	// no hash for. Only set when Supplements is non-empty.
+	MissingIntegrity []Dependency
+	// Dependencies lists the manifest's dependencies in declaration
+	// order, followed by the remaining lockfile entries.
+	Dependencies []ProjectDependency
+	// Graph is the lockfile's graph, when it records one.
+	Graph *Graph
+}
+
+// ProjectDependency is a dependency as seen from both the manifest and
+// the lockfile.
+//
+// The embedded Dependency merges the two: Version, Integrity,
+// RegistryURL, PURL, License and Hashes come from the lockfile when the
+// package is locked, while Scope, Position and Raw come from the manifest when it
+// is declared there. Direct is true exactly when the manifest declares
+// the package, which corrects lockfiles that can't tell on their own.
+type ProjectDependency struct {
+	Dependency
+	// Constraint is the version requirement as declared in the manifest.
+	// Empty for transitive dependencies.
+	Constraint string
+	// Declared is the manifest entry, or nil for transitive dependencies.
+	Declared *Dependency
+	// Locked is the lockfile entry, or nil if the package isn't locked.
+	Locked *Dependency
+}
+
+// lockfilePairs maps a manifest filename to the lockfiles that resolve
+// it, in order of preference.
+var lockfilePairs = map[string][]string{
+	"package.json":     {"package-lock.json", "npm-shrinkwrap.json", "yarn.lock", "pnpm-lock.yaml", "bun.lock"},
+	"Cargo.toml":       {"Cargo.lock"},
+	"Gemfile":          {"Gemfile.lock"},
+	"gems.rb":          {"gems.locked"},
+	"pyproject.toml":   {"poetry.lock", "uv.lock", "pdm.lock", "pylock.toml"},
+	"Pipfile":          {"Pipfile.lock"},
+	"composer.json":    {"composer.lock"},
+	"pubspec.yaml":     {"pubspec.lock"},
+	"mix.exs":          {"mix.lock"},
+	"rebar.config":     {"rebar.lock"},
+	"Package.swift":    {"Package.resolved"},
+	"Podfile":          {"Podfile.lock"},
+	"Cartfile":         {"Cartfile.resolved"},
+	"cpanfile":         {"cpanfile.snapshot"},
+	"shard.yml":        {"shard.lock"},
+	"deno.json":        {"deno.lock"},
+	"deno.jsonc":       {"deno.lock"},
+	"Project.toml":     {"Manifest.toml"},
+	"flake.nix":        {"flake.lock"},
+	"environment.yml":  {"conda-lock.yml"},
+	"environment.yaml": {"conda-lock.yml"},
+	"Brewfile":         {"Brewfile.lock.json"},
+	"conanfile.txt":    {"conan.lock"},
+	"conanfile.py":     {"conan.lock"},
+	"DESCRIPTION":      {"renv.lock"},
+	"lakefile.toml":    {"lake-manifest.json"},
+	"lakefile.lean":    {"lake-manifest.json"},
+	"build.gradle":     {"gradle.lockfile"},
+	"build.gradle.kts": {"gradle.lockfile"},
+	"Gopkg.toml":       {"Gopkg.lock"},
+	"glide.yaml":       {"glide.lock"},
+}
+
+// Pair joins a manifest result with its lockfile result. Either may be
+// nil, but not both, and both must come from the same ecosystem.
+//
+// Each declared dependency is matched by name to a locked entry. When
+// the lockfile holds several versions of the package, the entry the
+// lockfile records as a graph root or marks as direct is preferred,
+// then one that satisfies the declared range.
+func Pair(manifest, lockfile *ParseResult) (*Project, error) {
+	if manifest == nil && lockfile == nil {
+		return nil, fmt.Errorf("pair: no manifest or lockfile")
+	}
+	if manifest != nil && manifest.Kind != Manifest {
+		return nil, fmt.Errorf("pair: manifest has kind %q", manifest.Kind)
+	}
+	if lockfile != nil && lockfile.Kind != Lockfile {
+		return nil, fmt.Errorf("pair: lockfile has kind %q", lockfile.Kind)
+	}
+	if manifest != nil && lockfile != nil && manifest.Ecosystem != lockfile.Ecosystem {
+		return nil, fmt.Errorf("pair: ecosystems differ: %s manifest, %s lockfile", manifest.Ecosystem, lockfile.Ecosystem)
+	}
+
+	p := &Project{Manifest: manifest, Lockfile: lockfile}
+	if manifest != nil {
+		p.Ecosystem = manifest.Ecosystem
+		p.Name = manifest.Name
+		p.Version = manifest.Version
+	} else {
+		p.Ecosystem = lockfile.Ecosystem
+	}
+
+	if manifest == nil {
+		p.Graph = lockfile.Graph
+		for i := range lockfile.Dependencies {
+			locked := &lockfile.Dependencies[i]
+			p.Dependencies = append(p.Dependencies, ProjectDependency{Dependency: *locked, Locked: locked})
+		}
+		return p, nil
+	}
+
+	var locked []Dependency
+	byName := make(map[string][]int)
+	roots := make(map[Node]bool)
+	if lockfile != nil {
+		p.Graph = lockfile.Graph
+		locked = lockfile.Dependencies
+		for i := range locked {
+			key := projectKey(p.Ecosystem, locked[i].Name)
+			byName[key] = append(byName[key], i)
+		}
+		if lockfile.Graph != nil {
+			for _, r := range lockfile.Graph.Roots {
+				roots[r] = true
+			}
+		}
+	}
+
+	used := make([]bool, len(locked))
+	for i := range manifest.Dependencies {
+		declared := &manifest.Dependencies[i]
+		pd := ProjectDependency{
+			Dependency: *declared,
+			Constraint: declared.Version,
+			Declared:   declared,
+		}
+		pd.Direct = true
+		pd.Version = ""
+
+		if j, ok := pickLocked(locked, byName[projectKey(p.Ecosystem, declared.Name)], roots, declared.Range); ok {
+			used[j] = true
+			l := &locked[j]
+			pd.Locked = l
+			pd.Version = l.Version
+			pd.Integrity = l.Integrity
+			pd.RegistryURL = l.RegistryURL
+			pd.PURL = l.PURL
+			pd.License = l.License
+			pd.Hashes = l.Hashes
+		}
+		p.Dependencies = append(p.Dependencies, pd)
+	}
+
+	for i := range locked {
+		if used[i] {
+			continue
+		}
+		pd := ProjectDependency{Dependency: locked[i], Locked: &locked[i]}
+		pd.Direct = false
+		p.Dependencies = append(p.Dependencies, pd)
+	}
+
+	return p, nil
+}
+
+// pickLocked chooses among the lockfile entries sharing a declared
+// dependency's name: a graph root first, then an entry the lockfile
+// marks direct, then one satisfying the declared range.
+func pickLocked(locked []Dependency, candidates []int, roots map[Node]bool, declared *VersionRange) (int, bool) {
+	if len(candidates) == 0 {
+		return 0, false
+	}
+	for _, i := range candidates {
+		if roots[Node{Name: locked[i].Name, Version: locked[i].Version}] {
+			return i, true
+		}
+	}
+	for _, i := range candidates {
+		if locked[i].Direct {
+			return i, true
+		}
+	}
+	for _, i := range candidates {
+		if declared.Satisfies(locked[i].Version) {
+			return i, true
+		}
+	}
+	return candidates[0], true
+}
+
+var pythonNameSeparators = regexp.MustCompile(`[-_.]+`)
+
+// projectKey normalises a package name for matching between a manifest
+// and a lockfile in ecosystems where the two may spell it differently.
+func projectKey(ecosystem, name string) string {
+	switch ecosystem {
+	case "pypi":
+		return pythonNameSeparators.ReplaceAllString(strings.ToLower(name), "-")
+	case "composer", "nuget":
+		return strings.ToLower(name)
+	}
+	return name
+}
+
+// Projects groups scanned files into projects, pairing each manifest
+// with a sibling lockfile that resolves it (package.json with
+// package-lock.json, Cargo.toml with Cargo.lock, and so on). Every
+// manifest and lockfile ends up in exactly one project; files with no
+// partner form a project on their own. Sibling supplements (go.sum next
+// to go.mod) are applied to the manifest with ApplySupplements;
+// supplements without a manifest are dropped.
+//
+// Supplements that can't be applied, and files that can't be paired,
+// are reported as *ScanError values joined in the returned error. The
+// manifest is then used without its supplements, or the lockfile is
+// left to form a project of its own, so the projects that could be
+// built are returned alongside it.
+func Projects(files []ScannedFile) ([]*Project, error) {
+	type key struct{ dir, name string }
+	lockfiles := make(map[key]int)
+	supplements := make(map[key]int)
+	for i, f := range files {
+		switch f.Kind {
+		case Lockfile:
+			lockfiles[key{path.Dir(f.Path), path.Base(f.Path)}] = i
+		case Supplement:
+			supplements[key{path.Dir(f.Path), path.Base(f.Path)}] = i
+		}
+	}
+
+	paired := make(map[int]bool)
+	var projects []*Project
+	var errs []error
+	for _, f := range files {
+		if f.Kind != Manifest {
+			continue
+		}
+		dir := path.Dir(f.Path)
+		var lock *ParseResult
+		lockIndex := -1
+		for _, name := range lockfilePairs[path.Base(f.Path)] {
+			if i, ok := lockfiles[key{dir, name}]; ok && !paired[i] && files[i].Ecosystem == f.Ecosystem {
+				paired[i] = true
+				lock = files[i].ParseResult
+				lockIndex = i
+				break
+			}
+		}
+
+		manifest := f.ParseResult
+		var supps []*ParseResult
+		for _, name := range supplementPairs[path.Base(f.Path)] {
+			if i, ok := supplements[key{dir, name}]; ok && files[i].Ecosystem == f.Ecosystem {
+				supps = append(supps, files[i].ParseResult)
+			}
+		}
+		var missing []Dependency
+		if len(supps) > 0 {
+			applied, err := ApplySupplements(manifest, supps...)
+			if err != nil {
+				errs = append(errs, &ScanError{Path: f.Path, Err: err})
+				supps = nil
+			} else {
+				manifest = applied.ParseResult
+				missing = applied.Missing
+			}
+		}
+
+		p, err := Pair(manifest, lock)
+		if err != nil {
+			errs = append(errs, &ScanError{Path: f.Path, Err: err})
+			if lockIndex < 0 {
+				continue
+			}
+			// Try the lockfile on its own below
+			paired[lockIndex] = false
+			if p, err = Pair(manifest, nil); err != nil {
+				continue
+			}
+		}
+		p.Dir = dir
+		p.Supplements = supps
+		p.MissingIntegrity = missing
+		projects = append(projects, p)
+	}
+
+	for i, f := range files {
+		if f.Kind != Lockfile || paired[i] {
+			continue
+		}
+		p, err := Pair(nil, f.ParseResult)
+		if err != nil {
+			errs = append(errs, &ScanError{Path: f.Path, Err: err})
+			continue
+		}
+		p.Dir = path.Dir(f.Path)
+		projects = append(projects, p)
+	}
+	return projects, errors.Join(errs...)
+}
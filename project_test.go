@@ -0,0 +1,217 @@
+package manifests
+
+import (
+	"os"
+	"testing"
+	"testing/fstest"
+)
+
+const projectPackageJSON = `{
+  "name": "app",
+  "version": "1.0.0",
+  "dependencies": {
+    "lodash": "^4.17.0",
+    "left-pad": "^1.0.0"
+  },
+  "devDependencies": {
+    "mocha": "^10.0.0"
+  }
+}`
+
+const projectPackageLock = `{
+  "name": "app",
+  "version": "1.0.0",
+  "lockfileVersion": 3,
+  "requires": true,
+  "packages": {
+    "": {
+      "name": "app",
+      "version": "1.0.0",
+      "dependencies": {
+        "lodash": "^4.17.0",
+        "left-pad": "^1.0.0"
+      },
+      "devDependencies": {
+        "mocha": "^10.0.0"
+      }
+    },
+    "node_modules/lodash": {
+      "version": "4.17.21",
+      "resolved": "https://registry.npmjs.org/lodash/-/lodash-4.17.21.tgz",
+      "integrity": "sha512-v2kDEe57lecTulaDIuNTPy3Ry4gLGJ6Z1O3vE1krgXZNrsQ+LFTGHVxVjcXPs17LhbZVGedAJv8XZ1tvj5FvSg=="
+    },
+    "node_modules/mocha": {
+      "version": "10.2.0",
+      "dev": true,
+      "dependencies": {
+        "ms": "2.1.3"
+      }
+    },
+    "node_modules/ms": {
+      "version": "2.1.3",
+      "dev": true
+    }
+  }
+}`
+
+func TestPair(t *testing.T) {
+	manifest, err := Parse("package.json", []byte(projectPackageJSON))
+	if err != nil {
+		t.Fatalf("Parse manifest failed: %v", err)
+	}
+	lockfile, err := Parse("package-lock.json", []byte(projectPackageLock))
+	if err != nil {
+		t.Fatalf("Parse lockfile failed: %v", err)
+	}
+
+	p, err := Pair(manifest, lockfile)
+	if err != nil {
+		t.Fatalf("Pair failed: %v", err)
+	}
+	if p.Ecosystem != "npm" || p.Name != "app" || p.Version != "1.0.0" {
+		t.Errorf("project = %s %s %s", p.Ecosystem, p.Name, p.Version)
+	}
+
+	deps := make(map[string]ProjectDependency)
+	for _, d := range p.Dependencies {
+		deps[d.Name] = d
+	}
+	if len(deps) != 4 {
+		t.Fatalf("expected 4 dependencies, got %d", len(deps))
+	}
+
+	lodash := deps["lodash"]
+	if !lodash.Direct || lodash.Constraint != "^4.17.0" || lodash.Version != "4.17.21" {
+		t.Errorf("lodash = direct %v, constraint %q, version %q", lodash.Direct, lodash.Constraint, lodash.Version)
+	}
+	if lodash.Integrity == "" {
+		t.Error("lodash integrity should come from the lockfile")
+	}
+	if lodash.PURL != "pkg:npm/lodash@4.17.21" {
+		t.Errorf("lodash PURL = %q", lodash.PURL)
+	}
+	if !lodash.Position.IsValid() {
+		t.Error("lodash position should come from the manifest")
+	}
+
+	mocha := deps["mocha"]
+	if !mocha.Direct || mocha.Scope != Development || mocha.Version != "10.2.0" {
+		t.Errorf("mocha = direct %v, scope %q, version %q", mocha.Direct, mocha.Scope, mocha.Version)
+	}
+
+	leftPad := deps["left-pad"]
+	if !leftPad.Direct || leftPad.Locked != nil || leftPad.Version != "" {
+		t.Errorf("left-pad should be declared but unlocked, got %+v", leftPad)
+	}
+
+	ms := deps["ms"]
+	if ms.Direct || ms.Declared != nil || ms.Constraint != "" || ms.Version != "2.1.3" {
+		t.Errorf("ms should be transitive, got %+v", ms)
+	}
+}
+
+func TestPairPicksRootVersion(t *testing.T) {
+	readParse := func(path string) *ParseResult {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("failed to read fixture: %v", err)
+		}
+		res, err := Parse(path, content)
+		if err != nil {
+			t.Fatalf("Parse failed: %v", err)
+		}
+		return res
+	}
+
+	manifest := &ParseResult{
+		Ecosystem:    "cargo",
+		Kind:         Manifest,
+		Dependencies: []Dependency{{Name: "serde", Version: "1.0"}},
+	}
+	lockfile := &ParseResult{
+		Ecosystem: "cargo",
+		Kind:      Lockfile,
+		Dependencies: []Dependency{
+			{Name: "serde", Version: "0.9.15"},
+			{Name: "serde", Version: "1.0.190"},
+		},
+		Graph: &Graph{Roots: []Node{{Name: "serde", Version: "1.0.190"}}},
+	}
+	p, err := Pair(manifest, lockfile)
+	if err != nil {
+		t.Fatalf("Pair failed: %v", err)
+	}
+	if p.Dependencies[0].Version != "1.0.190" {
+		t.Errorf("serde version = %q, want 1.0.190", p.Dependencies[0].Version)
+	}
+	if len(p.Dependencies) != 2 || p.Dependencies[1].Direct {
+		t.Errorf("other serde version should be transitive, got %+v", p.Dependencies)
+	}
+
+	if _, err := Pair(readParse("testdata/cargo/Cargo.toml"), readParse("testdata/gem/Gemfile.lock")); err == nil {
+		t.Error("expected error pairing different ecosystems")
+	}
+	if _, err := Pair(readParse("testdata/cargo/Cargo.lock"), nil); err == nil {
+		t.Error("expected error passing a lockfile as the manifest")
+	}
+}
+
+func TestPairPythonNames(t *testing.T) {
+	manifest := &ParseResult{
+		Ecosystem:    "pypi",
+		Kind:         Manifest,
+		Dependencies: []Dependency{{Name: "Zope_Interface", Version: ">=6"}},
+	}
+	lockfile := &ParseResult{
+		Ecosystem:    "pypi",
+		Kind:         Lockfile,
+		Dependencies: []Dependency{{Name: "zope-interface", Version: "6.3"}},
+	}
+	p, err := Pair(manifest, lockfile)
+	if err != nil {
+		t.Fatalf("Pair failed: %v", err)
+	}
+	if len(p.Dependencies) != 1 || p.Dependencies[0].Version != "6.3" {
+		t.Errorf("expected Zope_Interface to match zope-interface, got %+v", p.Dependencies)
+	}
+}
+
+func TestProjects(t *testing.T) {
+	fsys := fstest.MapFS{
+		"package.json":           {Data: []byte(projectPackageJSON)},
+		"package-lock.json":      {Data: []byte(projectPackageLock)},
+		"crates/core/Cargo.toml": scanFixture(t, "testdata/cargo/Cargo.toml"),
+		"crates/core/Cargo.lock": scanFixture(t, "testdata/cargo/Cargo.lock"),
+		"tools/Gemfile.lock":     scanFixture(t, "testdata/gem/Gemfile.lock"),
+		"Dockerfile":             scanFixture(t, "testdata/docker/Dockerfile"),
+	}
+	res, err := Scan(fsys)
+	if err != nil {
+		t.Fatalf("Scan failed: %v", err)
+	}
+
+	projects, err := Projects(res.Files)
+	if err != nil {
+		t.Fatalf("Projects failed: %v", err)
+	}
+	byDir := make(map[string]*Project)
+	for _, p := range projects {
+		byDir[p.Dir+" "+p.Ecosystem] = p
+	}
+	if len(projects) != 4 {
+		t.Fatalf("expected 4 projects, got %d", len(projects))
+	}
+
+	if p := byDir[". npm"]; p == nil || p.Manifest == nil || p.Lockfile == nil {
+		t.Errorf("npm project not paired: %+v", p)
+	}
+	if p := byDir["crates/core cargo"]; p == nil || p.Manifest == nil || p.Lockfile == nil {
+		t.Errorf("cargo project not paired: %+v", p)
+	}
+	if p := byDir["tools gem"]; p == nil || p.Manifest != nil || p.Lockfile == nil {
+		t.Errorf("gem lockfile should stand alone: %+v", p)
+	}
+	if p := byDir[". docker"]; p == nil || p.Lockfile != nil {
+		t.Errorf("docker project should have no lockfile: %+v", p)
+	}
+}
@@ -0,0 +1,293 @@
+package manifests
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"regexp"
+	"strings"
+)
+
+// sbomHash is a decoded integrity value.
+type sbomHash struct {
+	alg    string // lowercase SRI name: md5, sha1, sha256, sha384, sha512
+	digest string // lowercase hex
+}
+
+// hashSizes maps SRI algorithm names to their digest length in bytes.
+var hashSizes = map[string]int{
+	"md5":    16,
+	"sha1":   20,
+	"sha256": 32,
+	"sha384": 48,
+	"sha512": 64,
+}
+
+// integrityHashes decodes a Dependency.Integrity value into hex digests.
+// Parsers record SRI values with either base64 digests (npm, NuGet) or
+// hex digests (Cargo, Composer, PyPI), so both are accepted. Values that
+// aren't a plain file hash, such as go.sum's "h1:" directory hashes, are
+// skipped.
+func integrityHashes(integrity string) []sbomHash {
+	var out []sbomHash
+	for _, field := range strings.Fields(integrity) {
+		alg, value, ok := strings.Cut(field, "-")
+		if !ok {
+			continue
+		}
+		// SRI allows "?options" after the digest
+		if i := strings.IndexByte(value, '?'); i >= 0 {
+			value = value[:i]
+		}
+		if h, ok := decodeHash(alg, value); ok {
+			out = append(out, h)
+		}
+	}
+	return out
+}
+
+// decodeHash normalises a hex or base64 digest to lowercase hex,
+// reporting false for unknown algorithms and digests of the wrong size.
+func decodeHash(alg, value string) (sbomHash, bool) {
+	alg = strings.ToLower(alg)
+	size, ok := hashSizes[alg]
+	if !ok {
+		return sbomHash{}, false
+	}
+	if b, err := hex.DecodeString(value); err == nil && len(b) == size {
+		return sbomHash{alg: alg, digest: strings.ToLower(value)}, true
+	}
+	for _, enc := range []*base64.Encoding{base64.StdEncoding, base64.RawStdEncoding, base64.URLEncoding} {
+		if b, err := enc.DecodeString(value); err == nil && len(b) == size {
+			return sbomHash{alg: alg, digest: hex.EncodeToString(b)}, true
+		}
+	}
+	return sbomHash{}, false
+}
+
+// componentHashes returns the hashes to export for a dependency: its
+// integrity value followed by every entry of Dependency.Hashes, with
+// duplicates dropped.
+func componentHashes(dep *Dependency) []sbomHash {
+	out := integrityHashes(dep.Integrity)
+	seen := make(map[sbomHash]bool, len(out))
+	for _, h := range out {
+		seen[h] = true
+	}
+	for _, dh := range dep.Hashes {
+		h, ok := decodeHash(dh.Algorithm, dh.Value)
+		if !ok || seen[h] {
+			continue
+		}
+		seen[h] = true
+		out = append(out, h)
+	}
+	return out
+}
+
+// isSPDXExpression reports whether license reads as an SPDX license
+// expression, such as "MIT" or "(Apache-2.0 OR MIT) AND BSD-3-Clause".
+// It checks the syntax only; identifiers aren't looked up in the SPDX
+// license list. Free-text values like "GPL (>= 2)" fail.
+func isSPDXExpression(license string) bool {
+	tokens := strings.Fields(strings.NewReplacer("(", " ( ", ")", " ) ").Replace(license))
+	if len(tokens) == 0 {
+		return false
+	}
+	depth := 0
+	operand := true // whether the next token must start an operand
+	for _, t := range tokens {
+		switch {
+		case t == "(":
+			if !operand {
+				return false
+			}
+			depth++
+		case t == ")":
+			if operand || depth == 0 {
+				return false
+			}
+			depth--
+		case t == "AND" || t == "OR" || t == "WITH" || t == "and" || t == "or" || t == "with":
+			if operand {
+				return false
+			}
+			operand = true
+		default:
+			if !operand || !spdxLicenseID.MatchString(t) {
+				return false
+			}
+			operand = false
+		}
+	}
+	return depth == 0 && !operand
+}
+
+// spdxLicenseID matches a license or exception identifier, optionally
+// followed by "+", or a LicenseRef.
+var spdxLicenseID = regexp.MustCompile(`^(?:DocumentRef-[A-Za-z0-9.-]+:)?[A-Za-z0-9][A-Za-z0-9.-]*\+?$`)
+
+// sbomComponent is a dependency as it appears in an exported SBOM.
+type sbomComponent struct {
+	ref       string
+	ecosystem string
+	dep       *Dependency
+	// version is the resolved version, empty for manifest requirements.
+	version string
+}
+
+// sbomInventory is the deduplicated set of packages across the results
+// being exported.
+type sbomInventory struct {
+	components []*sbomComponent
+	byRef      map[string]*sbomComponent
+	// resolved maps an ecosystem and normalised name to the first
+	// resolved component, so manifest requirements can point at it.
+	resolved map[[2]string]*sbomComponent
+	// nodes maps each graph node of each result to its component.
+	nodes map[*ParseResult]map[Node]*sbomComponent
+}
+
+// newSBOMInventory collects the components to export. Lockfile entries
+// come first; manifest requirements are only added as components of
+// their own when no lockfile in results resolves them, since they carry
+// no version.
+func newSBOMInventory(results []*ParseResult) *sbomInventory {
+	inv := &sbomInventory{
+		byRef:    make(map[string]*sbomComponent),
+		resolved: make(map[[2]string]*sbomComponent),
+		nodes:    make(map[*ParseResult]map[Node]*sbomComponent),
+	}
+	for _, res := range results {
+		if res == nil || res.Kind == Manifest {
+			continue
+		}
+		nodes := make(map[Node]*sbomComponent)
+		inv.nodes[res] = nodes
+		for i := range res.Dependencies {
+			d := &res.Dependencies[i]
+			c := inv.add(res.Ecosystem, d, d.Version)
+			nodes[Node{Name: d.Name, Version: d.Version}] = c
+			key := [2]string{res.Ecosystem, projectKey(res.Ecosystem, d.Name)}
+			if _, ok := inv.resolved[key]; !ok {
+				inv.resolved[key] = c
+			}
+		}
+	}
+	for _, res := range results {
+		if res == nil || res.Kind != Manifest {
+			continue
+		}
+		for i := range res.Dependencies {
+			d := &res.Dependencies[i]
+			if inv.lookup(res.Ecosystem, d.Name) == nil {
+				inv.add(res.Ecosystem, d, "")
+			}
+		}
+	}
+	return inv
+}
+
+func (inv *sbomInventory) add(ecosystem string, d *Dependency, version string) *sbomComponent {
+	ref := d.PURL
+	if ref == "" {
+		ref = ecosystem + ":" + d.Name
+		if version != "" {
+			ref += "@" + version
+		}
+	}
+	if c, ok := inv.byRef[ref]; ok {
+		return c
+	}
+	c := &sbomComponent{ref: ref, ecosystem: ecosystem, dep: d, version: version}
+	inv.components = append(inv.components, c)
+	inv.byRef[ref] = c
+	return c
+}
+
+// lookup returns the component for a manifest requirement: the resolved
+// component of that name if there is one, otherwise the unversioned
+// component added for it.
+func (inv *sbomInventory) lookup(ecosystem, name string) *sbomComponent {
+	if c, ok := inv.resolved[[2]string{ecosystem, projectKey(ecosystem, name)}]; ok {
+		return c
+	}
+	for _, c := range inv.components {
+		if c.version == "" && c.ecosystem == ecosystem && projectKey(ecosystem, c.dep.Name) == projectKey(ecosystem, name) {
+			return c
+		}
+	}
+	return nil
+}
+
+// direct returns the components the project itself depends on: the
+// manifests' requirements and the lockfiles' graph roots, or the entries
+// a lockfile marks direct when it records no roots.
+func (inv *sbomInventory) direct(results []*ParseResult) []*sbomComponent {
+	var out []*sbomComponent
+	seen := make(map[*sbomComponent]bool)
+	addDirect := func(c *sbomComponent) {
+		if c != nil && !seen[c] {
+			seen[c] = true
+			out = append(out, c)
+		}
+	}
+	for _, res := range results {
+		if res == nil {
+			continue
+		}
+		switch {
+		case res.Kind == Manifest:
+			for _, d := range res.Dependencies {
+				addDirect(inv.lookup(res.Ecosystem, d.Name))
+			}
+		case res.Graph != nil && len(res.Graph.Roots) > 0:
+			for _, r := range res.Graph.Roots {
+				addDirect(inv.nodes[res][r])
+			}
+		default:
+			for _, d := range res.Dependencies {
+				if d.Direct {
+					addDirect(inv.nodes[res][Node{Name: d.Name, Version: d.Version}])
+				}
+			}
+		}
+	}
+	return out
+}
+
+// edges returns each component's dependencies as recorded by the
+// lockfile graphs in results, in component order. Components with no
+// recorded dependencies are omitted.
+func (inv *sbomInventory) edges(results []*ParseResult) map[*sbomComponent][]*sbomComponent {
+	out := make(map[*sbomComponent][]*sbomComponent)
+	seen := make(map[[2]*sbomComponent]bool)
+	for _, res := range results {
+		if res == nil || res.Graph == nil {
+			continue
+		}
+		nodes := inv.nodes[res]
+		for _, e := range res.Graph.Edges {
+			from, to := nodes[e.From], nodes[e.To]
+			if from == nil || to == nil || seen[[2]*sbomComponent{from, to}] {
+				continue
+			}
+			seen[[2]*sbomComponent{from, to}] = true
+			out[from] = append(out[from], to)
+		}
+	}
+	return out
+}
+
+// sbomName picks the name and version of the document's subject: the
+// ones given in options, or else the first result that declares its own.
+func sbomName(results []*ParseResult, name, version string) (string, string) {
+	if name != "" {
+		return name, version
+	}
+	for _, res := range results {
+		if res != nil && res.Name != "" {
+			return res.Name, res.Version
+		}
+	}
+	return "", ""
+}
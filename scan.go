@@ -0,0 +1,155 @@
+package manifests
+
+import (
+	"io/fs"
+	"path"
+	"strings"
+)
+
+// DefaultIgnores lists the directory names Scan skips unless
+// ScanOptions.NoDefaultIgnores is set. They hold installed or vendored
+// copies of dependencies, VCS metadata, or test fixtures rather than the
+// project's own manifests.
+var DefaultIgnores = []string{
+	".git",
+	".hg",
+	".svn",
+	"node_modules",
+	"bower_components",
+	"vendor",
+	"testdata",
+}
+
+// ScanOptions configures Scan.
+type ScanOptions struct {
+	// Ignore holds extra glob patterns (path.Match syntax) for files and
+	// directories to skip. A pattern matches if it matches either the
+	// entry's name or its slash-separated path relative to the root.
+	Ignore []string
+	// NoDefaultIgnores disables DefaultIgnores.
+	NoDefaultIgnores bool
+	// MaxDepth limits how deep below the root Scan looks. Files in the
+	// root are at depth 1. Zero means no limit.
+	MaxDepth int
+	// Parse is passed through to Parse for each file.
+	Parse Options
+}
+
+// ScannedFile is a parsed file found by Scan.
+type ScannedFile struct {
+	// Path is slash-separated and relative to the scanned root.
+	Path string
+	*ParseResult
+}
+
+// ScanError records a file or directory Scan could not read or parse.
+type ScanError struct {
+	Path string
+	Err  error
+}
+
+func (e *ScanError) Error() string {
+	return e.Path + ": " + e.Err.Error()
+}
+
+func (e *ScanError) Unwrap() error {
+	return e.Err
+}
+
+// ScanResult holds everything Scan found.
+type ScanResult struct {
+	// Files are in lexical path order.
+	Files []ScannedFile
+	// Errors are collected per file rather than aborting the scan.
+	Errors []*ScanError
+}
+
+// Scan walks fsys and parses every file a registered parser recognises:
+// manifests, lockfiles and supplements. Paths are matched the same way
+// Parse matches filenames, using the path relative to the root, so
+// files such as Dockerfile.prod and .github/workflows/*.yml are found.
+//
+// Files that fail to read or parse are recorded in ScanResult.Errors
+// and the walk continues. An error is returned only if the root itself
+// cannot be read.
+func Scan(fsys fs.FS, opts ...ScanOptions) (*ScanResult, error) {
+	var o ScanOptions
+	if len(opts) > 0 {
+		o = opts[0]
+	}
+
+	ignores := o.Ignore
+	if !o.NoDefaultIgnores {
+		ignores = append(append([]string{}, DefaultIgnores...), o.Ignore...)
+	}
+
+	result := &ScanResult{}
+	err := fs.WalkDir(fsys, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if p == "." {
+				return err
+			}
+			result.Errors = append(result.Errors, &ScanError{Path: p, Err: err})
+			return nil
+		}
+		if p == "." {
+			return nil
+		}
+
+		if scanIgnored(p, d.Name(), ignores) {
+			if d.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+
+		depth := strings.Count(p, "/") + 1
+		if d.IsDir() {
+			if o.MaxDepth > 0 && depth >= o.MaxDepth {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if o.MaxDepth > 0 && depth > o.MaxDepth {
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+
+		if len(IdentifyAll(p)) == 0 {
+			return nil
+		}
+
+		content, err := fs.ReadFile(fsys, p)
+		if err != nil {
+			result.Errors = append(result.Errors, &ScanError{Path: p, Err: err})
+			return nil
+		}
+		res, err := Parse(p, content, o.Parse)
+		if err != nil {
+			result.Errors = append(result.Errors, &ScanError{Path: p, Err: err})
+			return nil
+		}
+		result.Files = append(result.Files, ScannedFile{Path: p, ParseResult: res})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// scanIgnored reports whether any pattern matches the entry's name or
+// its path relative to the scan root.
+func scanIgnored(p, name string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+		if ok, _ := path.Match(pattern, p); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,130 @@
+package manifests
+
+import (
+	"os"
+	"testing"
+	"testing/fstest"
+)
+
+func scanFixture(t *testing.T, path string) *fstest.MapFile {
+	t.Helper()
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read fixture: %v", err)
+	}
+	return &fstest.MapFile{Data: content}
+}
+
+func scannedPaths(res *ScanResult) []string {
+	var paths []string
+	for _, f := range res.Files {
+		paths = append(paths, f.Path)
+	}
+	return paths
+}
+
+func TestScan(t *testing.T) {
+	fsys := fstest.MapFS{
+		"package.json":                     scanFixture(t, "testdata/npm/package.json"),
+		"package-lock.json":                scanFixture(t, "testdata/npm/package-lock.json"),
+		"README.md":                        {Data: []byte("# readme\n")},
+		"Dockerfile.prod":                  scanFixture(t, "testdata/docker/Dockerfile"),
+		".github/workflows/ci.yml":         scanFixture(t, "testdata/github-actions/workflow.yml"),
+		"services/api/go.mod":              scanFixture(t, "testdata/golang/go.mod"),
+		"services/api/go.sum":              scanFixture(t, "testdata/golang/go.sum"),
+		"services/api/vendor/x/go.mod":     scanFixture(t, "testdata/golang/go.mod"),
+		"node_modules/lodash/package.json": scanFixture(t, "testdata/npm/package.json"),
+		"testdata/Gemfile":                 scanFixture(t, "testdata/gem/Gemfile"),
+		"broken/composer.lock":             {Data: []byte("{not json")},
+		".git/HEAD":                        {Data: []byte("ref: refs/heads/main\n")},
+		"services/web/Cargo.toml":          scanFixture(t, "testdata/cargo/Cargo.toml"),
+		"services/web/examples/Cargo.toml": scanFixture(t, "testdata/cargo/Cargo.toml"),
+		"services/web/examples/Cargo.lock": scanFixture(t, "testdata/cargo/Cargo.lock"),
+	}
+
+	res, err := Scan(fsys)
+	if err != nil {
+		t.Fatalf("Scan failed: %v", err)
+	}
+
+	want := []string{
+		".github/workflows/ci.yml",
+		"Dockerfile.prod",
+		"package-lock.json",
+		"package.json",
+		"services/api/go.mod",
+		"services/api/go.sum",
+		"services/web/Cargo.toml",
+		"services/web/examples/Cargo.lock",
+		"services/web/examples/Cargo.toml",
+	}
+	got := scannedPaths(res)
+	if len(got) != len(want) {
+		t.Fatalf("paths = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("paths[%d] = %q, want %q", i, got[i], want[i])
+		}
+	}
+
+	for _, f := range res.Files {
+		if f.Path == "services/api/go.sum" && f.Kind != Supplement {
+			t.Errorf("go.sum kind = %q, want %q", f.Kind, Supplement)
+		}
+		if f.Path == ".github/workflows/ci.yml" && f.Ecosystem != "github-actions" {
+			t.Errorf("workflow ecosystem = %q, want github-actions", f.Ecosystem)
+		}
+		for _, dep := range f.Dependencies {
+			if dep.Position.IsValid() && dep.Position.File != f.Path {
+				t.Errorf("%s: position file = %q", f.Path, dep.Position.File)
+			}
+		}
+	}
+
+	if len(res.Errors) != 1 {
+		t.Fatalf("expected 1 error, got %v", res.Errors)
+	}
+	if res.Errors[0].Path != "broken/composer.lock" {
+		t.Errorf("error path = %q, want broken/composer.lock", res.Errors[0].Path)
+	}
+}
+
+func TestScanOptions(t *testing.T) {
+	fsys := fstest.MapFS{
+		"package.json":                 scanFixture(t, "testdata/npm/package.json"),
+		"a/Gemfile":                    scanFixture(t, "testdata/gem/Gemfile"),
+		"a/b/Cargo.toml":               scanFixture(t, "testdata/cargo/Cargo.toml"),
+		"vendor/github.com/x/y/go.mod": scanFixture(t, "testdata/golang/go.mod"),
+		"examples/demo/package.json":   scanFixture(t, "testdata/npm/package.json"),
+	}
+
+	tests := []struct {
+		name string
+		opts ScanOptions
+		want []string
+	}{
+		{"max depth 1", ScanOptions{MaxDepth: 1}, []string{"package.json"}},
+		{"max depth 2", ScanOptions{MaxDepth: 2}, []string{"a/Gemfile", "package.json"}},
+		{"ignore globs", ScanOptions{Ignore: []string{"examples", "a/b/*"}}, []string{"a/Gemfile", "package.json"}},
+		{"no default ignores", ScanOptions{NoDefaultIgnores: true, Ignore: []string{"a", "examples"}}, []string{"package.json", "vendor/github.com/x/y/go.mod"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := Scan(fsys, tt.opts)
+			if err != nil {
+				t.Fatalf("Scan failed: %v", err)
+			}
+			got := scannedPaths(res)
+			if len(got) != len(tt.want) {
+				t.Fatalf("paths = %v, want %v", got, tt.want)
+			}
+			for i := range tt.want {
+				if got[i] != tt.want[i] {
+					t.Errorf("paths[%d] = %q, want %q", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
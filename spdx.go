@@ -0,0 +1,511 @@
+package manifests
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SPDXFormat selects the serialisation written by WriteSPDX.
+type SPDXFormat string
+
+const (
+	// SPDXJSON is the JSON serialisation: SPDX 2.3 JSON, or JSON-LD for
+	// SPDX 3.0.
+	SPDXJSON SPDXFormat = "json"
+	// SPDXTagValue is the SPDX 2.3 tag-value format.
+	SPDXTagValue SPDXFormat = "tag-value"
+)
+
+// SPDXOptions configures WriteSPDX.
+type SPDXOptions struct {
+	// SpecVersion is the SPDX version to write, "2.3" or "3.0".
+	// Defaults to "2.3". Tag-value is only defined for 2.3.
+	SpecVersion string
+	// Format defaults to SPDXJSON.
+	Format SPDXFormat
+	// Name and Version describe the project the document is for. Default
+	// to the first result's own name.
+	Name    string
+	Version string
+	// Namespace is the document's unique URI. Defaults to one under
+	// https://spdx.org/spdxdocs/ derived from the contents and creation
+	// time.
+	Namespace string
+	// Timestamp is the creation time. Defaults to the current time.
+	Timestamp time.Time
+}
+
+// spdxCreator identifies this library in creation info.
+const spdxCreator = "git-pkgs-manifests"
+
+// spdxHashAlgs maps SRI algorithm names to SPDX 2.3 checksum algorithms.
+// SPDX 3.0 uses the SRI names unchanged.
+var spdxHashAlgs = map[string]string{
+	"md5":    "MD5",
+	"sha1":   "SHA1",
+	"sha256": "SHA256",
+	"sha384": "SHA384",
+	"sha512": "SHA512",
+}
+
+// spdxScopeRelationships gives the SPDX 2.3 relationship from a
+// dependency to the package that needs it, by scope.
+var spdxScopeRelationships = map[Scope]string{
+	Development: "DEV_DEPENDENCY_OF",
+	Test:        "TEST_DEPENDENCY_OF",
+	Build:       "BUILD_DEPENDENCY_OF",
+	Optional:    "OPTIONAL_DEPENDENCY_OF",
+}
+
+// spdxDocument is the format-independent content of an SPDX document.
+type spdxDocument struct {
+	name      string
+	namespace string
+	created   string
+	root      *spdxPackage
+	packages  []*spdxPackage
+	// describes lists the SPDX IDs the document is about: the root
+	// package, or the direct dependencies when there is no root.
+	describes     []string
+	relationships []spdxRelationship
+}
+
+type spdxPackage struct {
+	id       string
+	name     string
+	version  string
+	download string
+	purl     string
+	hashes   []sbomHash
+	// license is the declared license expression, or NOASSERTION.
+	license string
+	scope   Scope
+}
+
+// spdxRelationship is an SPDX 2.3 relationship: from's relationship to
+// to. For 3.0 the dependency relationships are turned around.
+type spdxRelationship struct {
+	from, kind, to string
+	// scope is set on relationships with the root package.
+	scope Scope
+}
+
+// WriteSPDX writes the dependencies of one or more parse results as an
+// SPDX document, in SPDX 2.3 JSON or tag-value, or SPDX 3.0 JSON-LD.
+//
+// Each distinct package becomes an SPDX Package with a purl external
+// reference, folded together as in WriteCycloneDX. Integrity values and
+// Hashes become checksums, with SRI digests decoded to hex and
+// duplicates dropped. License becomes the declared license when it
+// reads as an SPDX license expression, and NOASSERTION otherwise (in
+// 3.0, a hasDeclaredLicense relationship, left out when there is no
+// expression). When the project
+// has a name, it becomes the package the document describes, related
+// to its direct dependencies by scope: DEPENDS_ON for runtime, and
+// DEV_DEPENDENCY_OF, TEST_DEPENDENCY_OF, BUILD_DEPENDENCY_OF or
+// OPTIONAL_DEPENDENCY_OF otherwise (in 3.0, dependsOn relationships
+// scoped to the lifecycle). Edges from lockfile graphs become DEPENDS_ON.
+func WriteSPDX(w io.Writer, results []*ParseResult, opts ...SPDXOptions) error {
+	var o SPDXOptions
+	if len(opts) > 0 {
+		o = opts[0]
+	}
+	if o.SpecVersion == "" {
+		o.SpecVersion = "2.3"
+	}
+	if o.Format == "" {
+		o.Format = SPDXJSON
+	}
+	switch {
+	case o.SpecVersion != "2.3" && o.SpecVersion != "3.0":
+		return fmt.Errorf("spdx: unsupported spec version %q", o.SpecVersion)
+	case o.Format != SPDXJSON && o.Format != SPDXTagValue:
+		return fmt.Errorf("spdx: unsupported format %q", o.Format)
+	case o.Format == SPDXTagValue && o.SpecVersion != "2.3":
+		return fmt.Errorf("spdx: tag-value isn't defined for SPDX %s", o.SpecVersion)
+	}
+
+	doc := newSPDXDocument(results, o)
+	switch {
+	case o.SpecVersion == "3.0":
+		return writeSPDX3(w, doc)
+	case o.Format == SPDXTagValue:
+		return writeSPDXTagValue(w, doc)
+	}
+	return writeSPDX2JSON(w, doc)
+}
+
+func newSPDXDocument(results []*ParseResult, o SPDXOptions) *spdxDocument {
+	timestamp := o.Timestamp
+	if timestamp.IsZero() {
+		timestamp = time.Now()
+	}
+	inv := newSBOMInventory(results)
+	doc := &spdxDocument{created: timestamp.UTC().Format(time.RFC3339)}
+
+	ids := make(map[string]bool)
+	newID := func(parts ...string) string {
+		base := "SPDXRef"
+		for _, part := range parts {
+			if part != "" {
+				base += "-" + sanitizeSPDXID(part)
+			}
+		}
+		id := base
+		for n := 2; ids[id]; n++ {
+			id = base + "-" + strconv.Itoa(n)
+		}
+		ids[id] = true
+		return id
+	}
+
+	name, version := sbomName(results, o.Name, o.Version)
+	doc.name = name
+	if name != "" {
+		doc.root = &spdxPackage{id: newID("Project", name), name: name, version: version, download: "NOASSERTION", license: "NOASSERTION"}
+	} else {
+		doc.name = "manifests"
+	}
+
+	byComponent := make(map[*sbomComponent]*spdxPackage, len(inv.components))
+	for _, c := range inv.components {
+		p := &spdxPackage{
+			id:       newID("Package", c.ecosystem, c.dep.Name, c.version),
+			name:     c.dep.Name,
+			version:  c.version,
+			download: "NOASSERTION",
+			purl:     c.dep.PURL,
+			hashes:   componentHashes(c.dep),
+			license:  "NOASSERTION",
+			scope:    c.dep.Scope,
+		}
+		if isSPDXExpression(c.dep.License) {
+			p.license = c.dep.License
+		}
+		if strings.HasPrefix(c.dep.RegistryURL, "https://") || strings.HasPrefix(c.dep.RegistryURL, "http://") {
+			p.download = c.dep.RegistryURL
+		}
+		byComponent[c] = p
+		doc.packages = append(doc.packages, p)
+	}
+
+	direct := inv.direct(results)
+	if doc.root != nil {
+		doc.describes = []string{doc.root.id}
+		for _, c := range direct {
+			p := byComponent[c]
+			if kind, ok := spdxScopeRelationships[p.scope]; ok {
+				doc.relationships = append(doc.relationships, spdxRelationship{from: p.id, kind: kind, to: doc.root.id, scope: p.scope})
+			} else {
+				doc.relationships = append(doc.relationships, spdxRelationship{from: doc.root.id, kind: "DEPENDS_ON", to: p.id, scope: p.scope})
+			}
+		}
+	} else {
+		if len(direct) == 0 {
+			direct = inv.components
+		}
+		for _, c := range direct {
+			doc.describes = append(doc.describes, byComponent[c].id)
+		}
+	}
+
+	edges := inv.edges(results)
+	for _, c := range inv.components {
+		for _, child := range edges[c] {
+			doc.relationships = append(doc.relationships, spdxRelationship{from: byComponent[c].id, kind: "DEPENDS_ON", to: byComponent[child].id})
+		}
+	}
+
+	doc.namespace = o.Namespace
+	if doc.namespace == "" {
+		h := sha256.New()
+		h.Write([]byte(doc.created))
+		for _, p := range doc.packages {
+			h.Write([]byte(p.id))
+		}
+		doc.namespace = "https://spdx.org/spdxdocs/" + sanitizeSPDXID(doc.name) + "-" + hex.EncodeToString(h.Sum(nil)[:16])
+	}
+	return doc
+}
+
+// sanitizeSPDXID replaces characters not allowed in SPDX IDs.
+func sanitizeSPDXID(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '-':
+			return r
+		}
+		return '-'
+	}, s)
+}
+
+type spdx2Document struct {
+	SPDXVersion       string              `json:"spdxVersion"`
+	DataLicense       string              `json:"dataLicense"`
+	SPDXID            string              `json:"SPDXID"`
+	Name              string              `json:"name"`
+	DocumentNamespace string              `json:"documentNamespace"`
+	CreationInfo      spdx2CreationInfo   `json:"creationInfo"`
+	Packages          []spdx2Package      `json:"packages"`
+	Relationships     []spdx2Relationship `json:"relationships"`
+}
+
+type spdx2CreationInfo struct {
+	Created  string   `json:"created"`
+	Creators []string `json:"creators"`
+}
+
+type spdx2Package struct {
+	SPDXID           string             `json:"SPDXID"`
+	Name             string             `json:"name"`
+	VersionInfo      string             `json:"versionInfo,omitempty"`
+	DownloadLocation string             `json:"downloadLocation"`
+	FilesAnalyzed    bool               `json:"filesAnalyzed"`
+	Checksums        []spdx2Checksum    `json:"checksums,omitempty"`
+	LicenseDeclared  string             `json:"licenseDeclared"`
+	ExternalRefs     []spdx2ExternalRef `json:"externalRefs,omitempty"`
+	PrimaryPurpose   string             `json:"primaryPackagePurpose,omitempty"`
+}
+
+type spdx2Checksum struct {
+	Algorithm     string `json:"algorithm"`
+	ChecksumValue string `json:"checksumValue"`
+}
+
+type spdx2ExternalRef struct {
+	ReferenceCategory string `json:"referenceCategory"`
+	ReferenceType     string `json:"referenceType"`
+	ReferenceLocator  string `json:"referenceLocator"`
+}
+
+type spdx2Relationship struct {
+	SPDXElementID      string `json:"spdxElementId"`
+	RelationshipType   string `json:"relationshipType"`
+	RelatedSPDXElement string `json:"relatedSpdxElement"`
+}
+
+func writeSPDX2JSON(w io.Writer, doc *spdxDocument) error {
+	out := spdx2Document{
+		SPDXVersion:       "SPDX-2.3",
+		DataLicense:       "CC0-1.0",
+		SPDXID:            "SPDXRef-DOCUMENT",
+		Name:              doc.name,
+		DocumentNamespace: doc.namespace,
+		CreationInfo:      spdx2CreationInfo{Created: doc.created, Creators: []string{"Tool: " + spdxCreator}},
+		Packages:          []spdx2Package{},
+	}
+	add := func(p *spdxPackage, purpose string) {
+		pkg := spdx2Package{
+			SPDXID:           p.id,
+			Name:             p.name,
+			VersionInfo:      p.version,
+			DownloadLocation: p.download,
+			LicenseDeclared:  p.license,
+			PrimaryPurpose:   purpose,
+		}
+		for _, h := range p.hashes {
+			pkg.Checksums = append(pkg.Checksums, spdx2Checksum{Algorithm: spdxHashAlgs[h.alg], ChecksumValue: h.digest})
+		}
+		if p.purl != "" {
+			pkg.ExternalRefs = []spdx2ExternalRef{{ReferenceCategory: "PACKAGE-MANAGER", ReferenceType: "purl", ReferenceLocator: p.purl}}
+		}
+		out.Packages = append(out.Packages, pkg)
+	}
+	if doc.root != nil {
+		add(doc.root, "APPLICATION")
+	}
+	for _, p := range doc.packages {
+		add(p, "LIBRARY")
+	}
+	for _, id := range doc.describes {
+		out.Relationships = append(out.Relationships, spdx2Relationship{"SPDXRef-DOCUMENT", "DESCRIBES", id})
+	}
+	for _, r := range doc.relationships {
+		out.Relationships = append(out.Relationships, spdx2Relationship{r.from, r.kind, r.to})
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	return enc.Encode(out)
+}
+
+func writeSPDXTagValue(w io.Writer, doc *spdxDocument) error {
+	var b strings.Builder
+	b.WriteString("SPDXVersion: SPDX-2.3\n")
+	b.WriteString("DataLicense: CC0-1.0\n")
+	b.WriteString("SPDXID: SPDXRef-DOCUMENT\n")
+	b.WriteString("DocumentName: " + doc.name + "\n")
+	b.WriteString("DocumentNamespace: " + doc.namespace + "\n")
+	b.WriteString("Creator: Tool: " + spdxCreator + "\n")
+	b.WriteString("Created: " + doc.created + "\n")
+
+	add := func(p *spdxPackage, purpose string) {
+		b.WriteString("\nPackageName: " + p.name + "\n")
+		b.WriteString("SPDXID: " + p.id + "\n")
+		if p.version != "" {
+			b.WriteString("PackageVersion: " + p.version + "\n")
+		}
+		b.WriteString("PackageDownloadLocation: " + p.download + "\n")
+		b.WriteString("FilesAnalyzed: false\n")
+		for _, h := range p.hashes {
+			b.WriteString("PackageChecksum: " + spdxHashAlgs[h.alg] + ": " + h.digest + "\n")
+		}
+		b.WriteString("PackageLicenseDeclared: " + p.license + "\n")
+		if p.purl != "" {
+			b.WriteString("ExternalRef: PACKAGE-MANAGER purl " + p.purl + "\n")
+		}
+		b.WriteString("PrimaryPackagePurpose: " + purpose + "\n")
+	}
+	if doc.root != nil {
+		add(doc.root, "APPLICATION")
+	}
+	for _, p := range doc.packages {
+		add(p, "LIBRARY")
+	}
+
+	b.WriteString("\n")
+	for _, id := range doc.describes {
+		b.WriteString("Relationship: SPDXRef-DOCUMENT DESCRIBES " + id + "\n")
+	}
+	for _, r := range doc.relationships {
+		b.WriteString("Relationship: " + r.from + " " + r.kind + " " + r.to + "\n")
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// spdx3Scopes maps scopes to SPDX 3.0 lifecycle scopes.
+var spdx3Scopes = map[Scope]string{
+	Runtime:     "runtime",
+	Development: "development",
+	Test:        "test",
+	Build:       "build",
+}
+
+func writeSPDX3(w io.Writer, doc *spdxDocument) error {
+	iri := func(id string) string { return doc.namespace + "#" + id }
+	const creationInfo = "_:creationinfo"
+	agent := iri("SPDXRef-Tool-" + spdxCreator)
+
+	graph := []map[string]any{
+		{
+			"type":        "CreationInfo",
+			"@id":         creationInfo,
+			"specVersion": "3.0.1",
+			"created":     doc.created,
+			"createdBy":   []string{agent},
+		},
+		{
+			"type":         "SoftwareAgent",
+			"spdxId":       agent,
+			"creationInfo": creationInfo,
+			"name":         spdxCreator,
+		},
+	}
+
+	var elements []string
+	add := func(p *spdxPackage, purpose string) {
+		pkg := map[string]any{
+			"type":                    "software_Package",
+			"spdxId":                  iri(p.id),
+			"creationInfo":            creationInfo,
+			"name":                    p.name,
+			"software_primaryPurpose": purpose,
+		}
+		if p.version != "" {
+			pkg["software_packageVersion"] = p.version
+		}
+		if p.download != "NOASSERTION" {
+			pkg["software_downloadLocation"] = p.download
+		}
+		if p.purl != "" {
+			pkg["software_packageUrl"] = p.purl
+		}
+		if len(p.hashes) > 0 {
+			var hashes []map[string]string
+			for _, h := range p.hashes {
+				hashes = append(hashes, map[string]string{"type": "Hash", "algorithm": h.alg, "hashValue": h.digest})
+			}
+			pkg["verifiedUsing"] = hashes
+		}
+		elements = append(elements, iri(p.id))
+		graph = append(graph, pkg)
+		if p.license != "NOASSERTION" {
+			license, rel := iri(p.id+"-License"), iri(p.id+"-DeclaredLicense")
+			graph = append(graph, map[string]any{
+				"type":                              "simplelicensing_LicenseExpression",
+				"spdxId":                            license,
+				"creationInfo":                      creationInfo,
+				"simplelicensing_licenseExpression": p.license,
+			}, map[string]any{
+				"type":             "Relationship",
+				"spdxId":           rel,
+				"creationInfo":     creationInfo,
+				"from":             iri(p.id),
+				"relationshipType": "hasDeclaredLicense",
+				"to":               []string{license},
+			})
+			elements = append(elements, license, rel)
+		}
+	}
+	if doc.root != nil {
+		add(doc.root, "application")
+	}
+	for _, p := range doc.packages {
+		add(p, "library")
+	}
+
+	for i, r := range doc.relationships {
+		from, kind, to := r.from, "dependsOn", r.to
+		if r.kind == "OPTIONAL_DEPENDENCY_OF" {
+			from, kind, to = r.to, "hasOptionalDependency", r.from
+		} else if r.kind != "DEPENDS_ON" {
+			from, to = r.to, r.from
+		}
+		id := iri("SPDXRef-Relationship-" + strconv.Itoa(i+1))
+		rel := map[string]any{
+			"type":             "Relationship",
+			"spdxId":           id,
+			"creationInfo":     creationInfo,
+			"from":             iri(from),
+			"relationshipType": kind,
+			"to":               []string{iri(to)},
+		}
+		if scope, ok := spdx3Scopes[r.scope]; ok && kind == "dependsOn" {
+			rel["type"] = "LifecycleScopedRelationship"
+			rel["scope"] = scope
+		}
+		elements = append(elements, id)
+		graph = append(graph, rel)
+	}
+
+	var roots []string
+	for _, id := range doc.describes {
+		roots = append(roots, iri(id))
+	}
+	graph = append(graph, map[string]any{
+		"type":               "SpdxDocument",
+		"spdxId":             iri("SPDXRef-DOCUMENT"),
+		"creationInfo":       creationInfo,
+		"name":               doc.name,
+		"profileConformance": []string{"core", "software"},
+		"rootElement":        roots,
+		"element":            elements,
+	})
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	return enc.Encode(map[string]any{
+		"@context": "https://spdx.org/rdf/3.0.1/spdx-context.jsonld",
+		"@graph":   graph,
+	})
+}
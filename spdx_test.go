@@ -0,0 +1,271 @@
+package manifests
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func spdxProject(t *testing.T) []*ParseResult {
+	t.Helper()
+	manifest, err := Parse("package.json", []byte(projectPackageJSON))
+	if err != nil {
+		t.Fatalf("Parse manifest failed: %v", err)
+	}
+	lockfile, err := Parse("package-lock.json", []byte(projectPackageLock))
+	if err != nil {
+		t.Fatalf("Parse lockfile failed: %v", err)
+	}
+	return []*ParseResult{manifest, lockfile}
+}
+
+var spdxTimestamp = time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+func TestWriteSPDXJSON(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteSPDX(&buf, spdxProject(t), SPDXOptions{Timestamp: spdxTimestamp}); err != nil {
+		t.Fatalf("WriteSPDX failed: %v", err)
+	}
+
+	var doc struct {
+		SPDXVersion       string `json:"spdxVersion"`
+		Name              string `json:"name"`
+		DocumentNamespace string `json:"documentNamespace"`
+		CreationInfo      struct {
+			Created string `json:"created"`
+		} `json:"creationInfo"`
+		Packages []struct {
+			SPDXID       string `json:"SPDXID"`
+			Name         string `json:"name"`
+			VersionInfo  string `json:"versionInfo"`
+			Download     string `json:"downloadLocation"`
+			Checksums    []spdx2Checksum
+			ExternalRefs []spdx2ExternalRef
+		} `json:"packages"`
+		Relationships []spdx2Relationship `json:"relationships"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("output is not valid JSON: %v", err)
+	}
+	if doc.SPDXVersion != "SPDX-2.3" || doc.Name != "app" || doc.CreationInfo.Created != "2024-05-01T12:00:00Z" {
+		t.Errorf("document = %s %s %s", doc.SPDXVersion, doc.Name, doc.CreationInfo.Created)
+	}
+	if !strings.HasPrefix(doc.DocumentNamespace, "https://spdx.org/spdxdocs/app-") {
+		t.Errorf("documentNamespace = %q", doc.DocumentNamespace)
+	}
+
+	// app, lodash, mocha, ms and the unlocked left-pad
+	if len(doc.Packages) != 5 {
+		t.Fatalf("expected 5 packages, got %d", len(doc.Packages))
+	}
+	lodash := doc.Packages[1]
+	if lodash.SPDXID != "SPDXRef-Package-npm-lodash-4.17.21" || lodash.VersionInfo != "4.17.21" {
+		t.Errorf("lodash = %+v", lodash)
+	}
+	if lodash.Download != "https://registry.npmjs.org/lodash/-/lodash-4.17.21.tgz" {
+		t.Errorf("lodash downloadLocation = %q", lodash.Download)
+	}
+	if len(lodash.Checksums) != 1 || lodash.Checksums[0].Algorithm != "SHA512" || len(lodash.Checksums[0].ChecksumValue) != 128 {
+		t.Errorf("lodash checksums = %+v", lodash.Checksums)
+	}
+	if len(lodash.ExternalRefs) != 1 || lodash.ExternalRefs[0].ReferenceLocator != "pkg:npm/lodash@4.17.21" {
+		t.Errorf("lodash externalRefs = %+v", lodash.ExternalRefs)
+	}
+
+	want := map[spdx2Relationship]bool{
+		{"SPDXRef-DOCUMENT", "DESCRIBES", "SPDXRef-Project-app"}:                           true,
+		{"SPDXRef-Project-app", "DEPENDS_ON", "SPDXRef-Package-npm-lodash-4.17.21"}:        true,
+		{"SPDXRef-Project-app", "DEPENDS_ON", "SPDXRef-Package-npm-left-pad"}:              true,
+		{"SPDXRef-Package-npm-mocha-10.2.0", "DEV_DEPENDENCY_OF", "SPDXRef-Project-app"}:   true,
+		{"SPDXRef-Package-npm-mocha-10.2.0", "DEPENDS_ON", "SPDXRef-Package-npm-ms-2.1.3"}: true,
+	}
+	if len(doc.Relationships) != len(want) {
+		t.Errorf("expected %d relationships, got %+v", len(want), doc.Relationships)
+	}
+	for _, r := range doc.Relationships {
+		if !want[r] {
+			t.Errorf("unexpected relationship %+v", r)
+		}
+	}
+}
+
+func TestWriteSPDXTagValue(t *testing.T) {
+	var buf bytes.Buffer
+	opts := SPDXOptions{Format: SPDXTagValue, Timestamp: spdxTimestamp, Namespace: "https://example.com/app"}
+	if err := WriteSPDX(&buf, spdxProject(t), opts); err != nil {
+		t.Fatalf("WriteSPDX failed: %v", err)
+	}
+	out := buf.String()
+	for _, line := range []string{
+		"SPDXVersion: SPDX-2.3\n",
+		"DocumentName: app\n",
+		"DocumentNamespace: https://example.com/app\n",
+		"Created: 2024-05-01T12:00:00Z\n",
+		"PackageName: lodash\nSPDXID: SPDXRef-Package-npm-lodash-4.17.21\nPackageVersion: 4.17.21\n",
+		"PackageChecksum: SHA512: bf690311ee7b95e7",
+		"ExternalRef: PACKAGE-MANAGER purl pkg:npm/lodash@4.17.21\n",
+		"Relationship: SPDXRef-DOCUMENT DESCRIBES SPDXRef-Project-app\n",
+		"Relationship: SPDXRef-Package-npm-mocha-10.2.0 DEV_DEPENDENCY_OF SPDXRef-Project-app\n",
+	} {
+		if !strings.Contains(out, line) {
+			t.Errorf("output missing %q", line)
+		}
+	}
+
+	if err := WriteSPDX(&buf, nil, SPDXOptions{SpecVersion: "3.0", Format: SPDXTagValue}); err == nil {
+		t.Error("expected error for SPDX 3.0 tag-value")
+	}
+	if err := WriteSPDX(&buf, nil, SPDXOptions{SpecVersion: "2.2"}); err == nil {
+		t.Error("expected error for unsupported spec version")
+	}
+}
+
+func TestWriteSPDX3(t *testing.T) {
+	var buf bytes.Buffer
+	opts := SPDXOptions{SpecVersion: "3.0", Timestamp: spdxTimestamp, Namespace: "https://example.com/app"}
+	if err := WriteSPDX(&buf, spdxProject(t), opts); err != nil {
+		t.Fatalf("WriteSPDX failed: %v", err)
+	}
+
+	var doc struct {
+		Context string           `json:"@context"`
+		Graph   []map[string]any `json:"@graph"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("output is not valid JSON: %v", err)
+	}
+	if doc.Context != "https://spdx.org/rdf/3.0.1/spdx-context.jsonld" {
+		t.Errorf("@context = %q", doc.Context)
+	}
+
+	types := make(map[string]int)
+	for _, el := range doc.Graph {
+		types[el["type"].(string)]++
+		if el["spdxId"] == "https://example.com/app#SPDXRef-Package-npm-lodash-4.17.21" {
+			if el["software_packageUrl"] != "pkg:npm/lodash@4.17.21" || el["software_packageVersion"] != "4.17.21" {
+				t.Errorf("lodash = %v", el)
+			}
+			hashes, _ := el["verifiedUsing"].([]any)
+			if len(hashes) != 1 || hashes[0].(map[string]any)["algorithm"] != "sha512" {
+				t.Errorf("lodash verifiedUsing = %v", el["verifiedUsing"])
+			}
+		}
+		if el["type"] == "LifecycleScopedRelationship" && el["scope"] == "development" {
+			if el["from"] != "https://example.com/app#SPDXRef-Project-app" {
+				t.Errorf("development relationship should be from the project, got %v", el)
+			}
+		}
+	}
+	if types["software_Package"] != 5 || types["SpdxDocument"] != 1 || types["CreationInfo"] != 1 {
+		t.Errorf("element types = %v", types)
+	}
+	if types["LifecycleScopedRelationship"] != 3 || types["Relationship"] != 1 {
+		t.Errorf("relationship types = %v", types)
+	}
+}
+
+func TestWriteSPDXHashesAndLicenses(t *testing.T) {
+	const sha256 = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+	const wheel = "5feb91325bbceade6afab43eb3b508c63ee53579fe896c77137ded51c6b6958e"
+	results := []*ParseResult{{
+		Ecosystem: "pypi",
+		Kind:      Lockfile,
+		Dependencies: []Dependency{
+			{
+				Name:      "requests",
+				Version:   "2.31.0",
+				PURL:      "pkg:pypi/requests@2.31.0",
+				Integrity: "sha256-" + sha256,
+				Hashes: []Hash{
+					{Algorithm: "sha256", Value: sha256, Artifact: "requests-2.31.0.tar.gz"},
+					{Algorithm: "sha256", Value: wheel, Artifact: "requests-2.31.0-py3-none-any.whl"},
+				},
+				License: "Apache-2.0",
+			},
+			{Name: "certifi", Version: "2024.2.2", PURL: "pkg:pypi/certifi@2024.2.2", License: "GPL (>= 2)"},
+		},
+	}}
+	opts := SPDXOptions{Timestamp: spdxTimestamp, Namespace: "https://example.com/app"}
+
+	var buf bytes.Buffer
+	if err := WriteSPDX(&buf, results, opts); err != nil {
+		t.Fatalf("WriteSPDX failed: %v", err)
+	}
+	var doc struct {
+		Packages []struct {
+			Name            string `json:"name"`
+			Checksums       []spdx2Checksum
+			LicenseDeclared string `json:"licenseDeclared"`
+		} `json:"packages"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("output is not valid JSON: %v", err)
+	}
+	if len(doc.Packages) != 2 {
+		t.Fatalf("expected 2 packages, got %d", len(doc.Packages))
+	}
+	requests := doc.Packages[0]
+	if len(requests.Checksums) != 2 || requests.Checksums[0].ChecksumValue != sha256 || requests.Checksums[1].ChecksumValue != wheel {
+		t.Errorf("requests checksums = %+v, want the integrity hash and the wheel's", requests.Checksums)
+	}
+	if requests.LicenseDeclared != "Apache-2.0" {
+		t.Errorf("requests licenseDeclared = %q", requests.LicenseDeclared)
+	}
+	if certifi := doc.Packages[1]; certifi.LicenseDeclared != "NOASSERTION" {
+		t.Errorf("certifi licenseDeclared = %q, want NOASSERTION", certifi.LicenseDeclared)
+	}
+
+	buf.Reset()
+	opts.Format = SPDXTagValue
+	if err := WriteSPDX(&buf, results, opts); err != nil {
+		t.Fatalf("WriteSPDX tag-value failed: %v", err)
+	}
+	for _, line := range []string{
+		"PackageChecksum: SHA256: " + wheel + "\n",
+		"PackageLicenseDeclared: Apache-2.0\n",
+		"PackageLicenseDeclared: NOASSERTION\n",
+	} {
+		if !strings.Contains(buf.String(), line) {
+			t.Errorf("tag-value output missing %q", line)
+		}
+	}
+
+	buf.Reset()
+	opts.Format, opts.SpecVersion = SPDXJSON, "3.0"
+	if err := WriteSPDX(&buf, results, opts); err != nil {
+		t.Fatalf("WriteSPDX 3.0 failed: %v", err)
+	}
+	var doc3 struct {
+		Graph []map[string]any `json:"@graph"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &doc3); err != nil {
+		t.Fatalf("output is not valid JSON: %v", err)
+	}
+	const pkg = "https://example.com/app#SPDXRef-Package-pypi-requests-2.31.0"
+	var license string
+	for _, el := range doc3.Graph {
+		if el["type"] == "simplelicensing_LicenseExpression" && el["simplelicensing_licenseExpression"] == "Apache-2.0" {
+			license, _ = el["spdxId"].(string)
+		}
+	}
+	found := false
+	for _, el := range doc3.Graph {
+		if el["relationshipType"] == "hasDeclaredLicense" {
+			to, _ := el["to"].([]any)
+			if el["from"] != pkg || len(to) != 1 || to[0] != license {
+				t.Errorf("unexpected hasDeclaredLicense relationship %v", el)
+			}
+			found = true
+		}
+		if el["spdxId"] == pkg {
+			if hashes, _ := el["verifiedUsing"].([]any); len(hashes) != 2 {
+				t.Errorf("requests verifiedUsing = %v", el["verifiedUsing"])
+			}
+		}
+	}
+	if license == "" || !found {
+		t.Errorf("expected a declared license for requests only, got license %q", license)
+	}
+}
@@ -0,0 +1,75 @@
+package manifests
+
+import "fmt"
+
+// supplementPairs maps a manifest filename to the supplement files that
+// carry extra data for its dependencies.
+var supplementPairs = map[string][]string{
+	"go.mod":  {"go.sum"},
+	"go.work": {"go.work.sum"},
+}
+
+// SupplementResult is a manifest result with supplement data applied.
+type SupplementResult struct {
+	*ParseResult
+	// Missing lists the dependencies that no supplement had an
+	// integrity hash for, e.g. go.mod requirements absent from go.sum.
+	// Requirements replaced by a local directory aren't listed.
+	Missing []Dependency
+}
+
+// ApplySupplements attaches data from supplement files, such as go.sum,
+// to the matching dependencies of a manifest result. Dependencies are
+// matched by name and exact version, since a hash only holds for the
+// version it was computed from. Integrity is filled in where the
+// manifest has none.
+//
+// result is not modified; the returned result holds a copy of its
+// dependencies. All inputs must share an ecosystem.
+func ApplySupplements(result *ParseResult, supplements ...*ParseResult) (*SupplementResult, error) {
+	if result == nil {
+		return nil, fmt.Errorf("apply supplements: no result")
+	}
+
+	type key struct{ name, version string }
+	entries := make(map[key]Dependency)
+	for _, s := range supplements {
+		if s == nil {
+			continue
+		}
+		if s.Kind != Supplement {
+			return nil, fmt.Errorf("apply supplements: %s result has kind %q", s.Ecosystem, s.Kind)
+		}
+		if s.Ecosystem != result.Ecosystem {
+			return nil, fmt.Errorf("apply supplements: ecosystems differ: %s result, %s supplement", result.Ecosystem, s.Ecosystem)
+		}
+		for _, d := range s.Dependencies {
+			k := key{projectKey(s.Ecosystem, d.Name), d.Version}
+			if _, ok := entries[k]; !ok {
+				entries[k] = d
+			}
+		}
+	}
+
+	out := *result
+	out.Dependencies = make([]Dependency, len(result.Dependencies))
+	copy(out.Dependencies, result.Dependencies)
+
+	sr := &SupplementResult{ParseResult: &out}
+	for i := range out.Dependencies {
+		d := &out.Dependencies[i]
+		// Modules replaced by a local directory are never hashed, and a
+		// hash for the required version isn't for the code in use
+		if d.Replacement != nil && d.Replacement.NewVersion == "" {
+			continue
+		}
+		s, ok := entries[key{projectKey(out.Ecosystem, d.Name), d.Version}]
+		if d.Integrity == "" && ok {
+			d.Integrity = s.Integrity
+		}
+		if d.Integrity == "" {
+			sr.Missing = append(sr.Missing, *d)
+		}
+	}
+	return sr, nil
+}
@@ -0,0 +1,101 @@
+package manifests
+
+import (
+	"os"
+	"testing"
+	"testing/fstest"
+)
+
+func TestApplySupplements(t *testing.T) {
+	modContent, err := os.ReadFile("testdata/golang/go.mod")
+	if err != nil {
+		t.Fatalf("failed to read fixture: %v", err)
+	}
+	sumContent, err := os.ReadFile("testdata/golang/go.sum")
+	if err != nil {
+		t.Fatalf("failed to read fixture: %v", err)
+	}
+	mod, err := Parse("go.mod", modContent)
+	if err != nil {
+		t.Fatalf("Parse go.mod failed: %v", err)
+	}
+	sum, err := Parse("go.sum", sumContent)
+	if err != nil {
+		t.Fatalf("Parse go.sum failed: %v", err)
+	}
+
+	res, err := ApplySupplements(mod, sum)
+	if err != nil {
+		t.Fatalf("ApplySupplements failed: %v", err)
+	}
+
+	integrity := make(map[string]string)
+	for _, d := range res.Dependencies {
+		integrity[d.Name] = d.Integrity
+	}
+	if got := integrity["github.com/kr/pretty"]; got != "h1:L/CwN0zerZDmRFUapSPitk6f+Q3+0za1rQkzVuMiMFI=" {
+		t.Errorf("kr/pretty integrity = %q", got)
+	}
+	if integrity["gopkg.in/yaml.v1"] == "" {
+		t.Error("gopkg.in/yaml.v1 should have integrity from go.sum")
+	}
+
+	var missing []string
+	for _, d := range res.Missing {
+		missing = append(missing, d.Name)
+	}
+	// golang.org/x/net is replaced, so go.sum would hold the fork's hash
+	want := []string{
+		"github.com/jstemmer/go-junit-report",
+		"github.com/jstemmer/go-junit-report/v2",
+		"example.com/fork/net",
+	}
+	if len(missing) != len(want) {
+		t.Fatalf("missing = %v, want %v", missing, want)
+	}
+	for i := range want {
+		if missing[i] != want[i] {
+			t.Errorf("missing[%d] = %q, want %q", i, missing[i], want[i])
+		}
+	}
+
+	for _, d := range mod.Dependencies {
+		if d.Integrity != "" {
+			t.Errorf("input result was modified: %s has integrity %q", d.Name, d.Integrity)
+		}
+	}
+
+	if _, err := ApplySupplements(sum, mod); err == nil {
+		t.Error("expected error when a manifest is passed as a supplement")
+	}
+}
+
+func TestProjectsApplySupplements(t *testing.T) {
+	fsys := fstest.MapFS{
+		"go.mod": scanFixture(t, "testdata/golang/go.mod"),
+		"go.sum": scanFixture(t, "testdata/golang/go.sum"),
+	}
+	res, err := Scan(fsys)
+	if err != nil {
+		t.Fatalf("Scan failed: %v", err)
+	}
+	projects, err := Projects(res.Files)
+	if err != nil {
+		t.Fatalf("Projects failed: %v", err)
+	}
+	if len(projects) != 1 {
+		t.Fatalf("expected 1 project, got %d", len(projects))
+	}
+	p := projects[0]
+	if len(p.Supplements) != 1 {
+		t.Errorf("expected go.sum to be applied, got %d supplements", len(p.Supplements))
+	}
+	if len(p.MissingIntegrity) != 3 {
+		t.Errorf("expected 3 dependencies missing integrity, got %d", len(p.MissingIntegrity))
+	}
+	for _, d := range p.Dependencies {
+		if d.Name == "github.com/kr/pretty" && d.Integrity == "" {
+			t.Error("kr/pretty should carry its go.sum hash")
+		}
+	}
+}
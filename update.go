@@ -0,0 +1,133 @@
+package manifests
+
+import (
+	"errors"
+	"slices"
+
+	"github.com/git-pkgs/manifests/internal/core"
+)
+
+// Errors wrapped by UpdateError, for use with errors.Is.
+var (
+	// ErrUpdateUnsupported means the file's format can't be updated.
+	ErrUpdateUnsupported = errors.New("updating this kind of file is not supported")
+	// ErrDependencyNotFound means no declaration of the dependency, with
+	// a known position, was found.
+	ErrDependencyNotFound = errors.New("dependency not found")
+	// ErrNoVersion means the dependency was found but none of its
+	// declarations has a version of its own, e.g. one managed by a parent
+	// POM or a Cargo workspace.
+	ErrNoVersion = errors.New("dependency has no version to update")
+	// ErrUnencodableVersion means the new version can't be written where
+	// the old one was, e.g. one with a newline or an unescapable quote.
+	ErrUnencodableVersion = core.ErrUnencodableVersion
+	// ErrSharedVersion means the version is set where other dependencies
+	// read it too, e.g. a pom.xml property another artifact's <version>
+	// or a plugin also refers to, so it isn't changed.
+	ErrSharedVersion = core.ErrSharedVersion
+)
+
+// UpdateError is returned by UpdateDependency when a file can't be
+// updated.
+type UpdateError struct {
+	Filename string
+	Name     string
+	Err      error
+}
+
+func (e *UpdateError) Error() string {
+	return "update " + e.Name + " in " + e.Filename + ": " + e.Err.Error()
+}
+
+func (e *UpdateError) Unwrap() error {
+	return e.Err
+}
+
+// UpdateDependency returns a copy of content with the version of the
+// named dependency set to newVersion. Only the version text changes;
+// comments, ordering and whitespace are kept byte for byte. Every
+// declaration of the dependency is updated, such as one listed in both
+// dependencies and devDependencies.
+//
+// newVersion replaces what Parse reports as the dependency's Version, so
+// it carries whatever operator the format puts there: "^4.17.21" in
+// package.json, "==2.32.0" in requirements.txt, "v0.5.0" in go.mod, or a
+// tag or digest for a Dockerfile FROM image. Names match as in the
+// ecosystem, so "Requests" finds "requests" in a Python file, and a
+// replaced Go module can be named by its original path.
+//
+// A pom.xml version taken from a ${property} is changed where this file
+// sets the property, as long as nothing but this artifact's own
+// declarations refers to it. Otherwise updating it would silently move
+// other artifacts too, and ErrSharedVersion is returned instead.
+//
+// These files are supported: package.json, go.mod, Cargo.toml,
+// requirements.txt (and its variants), pyproject.toml (PEP 621 and
+// Poetry), Gemfile and gems.rb, pom.xml, and Dockerfile FROM lines.
+// Unknown files give an *UnknownFileError, parse failures a *ParseError,
+// and the rest an *UpdateError.
+func UpdateDependency(filename string, content []byte, name, newVersion string) ([]byte, error) {
+	parser, eco, _ := core.IdentifyParser(filename)
+	if parser == nil {
+		return nil, &UnknownFileError{Filename: filename}
+	}
+	editor, ok := parser.(core.VersionEditor)
+	if !ok {
+		return nil, &UpdateError{Filename: filename, Name: name, Err: ErrUpdateUnsupported}
+	}
+	res, err := parser.Parse(filename, content)
+	if err != nil {
+		return nil, err
+	}
+
+	lines := core.NewLineIndex(content)
+	key := projectKey(eco, name)
+	found := false
+	var edits []core.Edit
+	for i := range res.Dependencies {
+		dep := &res.Dependencies[i]
+		if !dep.Position.IsValid() || !dependencyNamed(eco, dep, key) {
+			continue
+		}
+		found = true
+		start, end := lines.Offsets(dep.Position)
+		edit, ok, err := editor.EditVersion(content, core.Span{Start: start, End: end}, dep, newVersion)
+		if err != nil {
+			return nil, &UpdateError{Filename: filename, Name: name, Err: err}
+		}
+		// Declarations can share a version, as with a POM property
+		if ok && !slices.Contains(edits, edit) {
+			edits = append(edits, edit)
+		}
+	}
+	switch {
+	case !found:
+		return nil, &UpdateError{Filename: filename, Name: name, Err: ErrDependencyNotFound}
+	case len(edits) == 0:
+		return nil, &UpdateError{Filename: filename, Name: name, Err: ErrNoVersion}
+	}
+	return applyEdits(content, edits), nil
+}
+
+// dependencyNamed reports whether dep is the dependency whose project
+// key is key.
+func dependencyNamed(eco string, dep *Dependency, key string) bool {
+	if projectKey(eco, dep.Name) == key {
+		return true
+	}
+	return dep.Replacement != nil && projectKey(eco, dep.Replacement.Name) == key
+}
+
+// applyEdits returns a copy of content with the edits, which mustn't
+// overlap, made.
+func applyEdits(content []byte, edits []core.Edit) []byte {
+	slices.SortFunc(edits, func(a, b core.Edit) int { return a.Start - b.Start })
+	out := make([]byte, 0, len(content))
+	last := 0
+	for _, e := range edits {
+		out = append(out, content[last:e.Start]...)
+		out = append(out, e.Text...)
+		last = e.End
+	}
+	return append(out, content[last:]...)
+}
@@ -0,0 +1,248 @@
+package manifests
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestUpdateDependency(t *testing.T) {
+	tests := []struct {
+		filename, name, version string
+		before, after           string
+	}{
+		{
+			filename: "package.json", name: "lodash", version: "^4.17.21",
+			before: `{
+  "dependencies": {
+    "lodash":   "^4.17.0",
+    "react": "^18.0.0"
+  },
+  "devDependencies": {"lodash": "~4.17.0", "my-lodash": "npm:lodash@^3.0.0"}
+}
+`,
+			after: `{
+  "dependencies": {
+    "lodash":   "^4.17.21",
+    "react": "^18.0.0"
+  },
+  "devDependencies": {"lodash": "^4.17.21", "my-lodash": "npm:lodash@^4.17.21"}
+}
+`,
+		},
+		{
+			filename: "go.mod", name: "golang.org/x/net", version: "v0.30.0",
+			before: "module example.com/app\n\nrequire golang.org/x/net v0.20.0 // pinned\n\nrequire (\n\tgolang.org/x/text  v0.14.0 // indirect\n)\n",
+			after:  "module example.com/app\n\nrequire golang.org/x/net v0.30.0 // pinned\n\nrequire (\n\tgolang.org/x/text  v0.14.0 // indirect\n)\n",
+		},
+		{
+			filename: "go.mod", name: "golang.org/x/text", version: "v0.15.0",
+			before: "module example.com/app\n\nrequire (\n\tgolang.org/x/text  v0.14.0 // indirect\n)\n\nreplace golang.org/x/text => example.com/fork/text v0.14.1\n",
+			after:  "module example.com/app\n\nrequire (\n\tgolang.org/x/text  v0.15.0 // indirect\n)\n\nreplace golang.org/x/text => example.com/fork/text v0.14.1\n",
+		},
+		{
+			filename: "Cargo.toml", name: "serde", version: "1.0.210",
+			before: `[package]
+name = "app"
+version = "0.1.0"
+
+[dependencies]
+serde = { version = "1.0", features = ["derive"] } # serialisation
+tokio = "1"
+
+[dev-dependencies.serde]
+features = ["std"]
+version = '1.0'
+
+[build-dependencies]
+serde.version = "1.0"
+`,
+			after: `[package]
+name = "app"
+version = "0.1.0"
+
+[dependencies]
+serde = { version = "1.0.210", features = ["derive"] } # serialisation
+tokio = "1"
+
+[dev-dependencies.serde]
+features = ["std"]
+version = '1.0.210'
+
+[build-dependencies]
+serde.version = "1.0.210"
+`,
+		},
+		{
+			filename: "requirements-dev.txt", name: "Requests", version: "==2.32.0",
+			before: "# web\nrequests[socks] == 2.31.0 ; python_version >= '3.8'  # pinned\nflask>=2.0\n",
+			after:  "# web\nrequests[socks] ==2.32.0 ; python_version >= '3.8'  # pinned\nflask>=2.0\n",
+		},
+		{
+			filename: "pyproject.toml", name: "requests", version: ">=2.32",
+			before: `[project]
+name = "app"
+dependencies = [
+    "requests[socks]>=2.28; python_version >= '3.8'",  # http
+    'flask',
+]
+
+[project.optional-dependencies]
+dev = ["requests>=2.28"]
+`,
+			after: `[project]
+name = "app"
+dependencies = [
+    "requests[socks]>=2.32; python_version >= '3.8'",  # http
+    'flask',
+]
+
+[project.optional-dependencies]
+dev = ["requests>=2.32"]
+`,
+		},
+		{
+			filename: "pyproject.toml", name: "django", version: "^5.1",
+			before: `[tool.poetry.dependencies]
+python = "^3.11"
+Django = { version = "^4.2", extras = ["bcrypt"] }
+
+[tool.poetry.group.test.dependencies.django]
+version = "^4.2"
+`,
+			after: `[tool.poetry.dependencies]
+python = "^3.11"
+Django = { version = "^5.1", extras = ["bcrypt"] }
+
+[tool.poetry.group.test.dependencies.django]
+version = "^5.1"
+`,
+		},
+		{
+			filename: "Gemfile", name: "rails", version: ">= 7.1, < 8",
+			before: "source \"https://rubygems.org\"\n\ngem 'rails', '~> 7.0.0', require: false # web\ngem \"puma\"\n",
+			after:  "source \"https://rubygems.org\"\n\ngem 'rails', '>= 7.1', '< 8', require: false # web\ngem \"puma\"\n",
+		},
+		{
+			// Parse reports both requirements as the version, so both are
+			// replaced
+			filename: "Gemfile", name: "rails", version: "~> 7.1",
+			before: "gem 'rails', '~> 7.0', '>= 7.0.1'\ngem 'puma', '~> 6.0'\n",
+			after:  "gem 'rails', '~> 7.1'\ngem 'puma', '~> 6.0'\n",
+		},
+		{
+			filename: "pom.xml", name: "junit:junit", version: "4.13.2",
+			before: `<project>
+  <properties>
+    <junit.version> 4.12 </junit.version>
+  </properties>
+  <dependencies>
+    <dependency>
+      <groupId>junit</groupId>
+      <artifactId>junit</artifactId>
+      <version>${junit.version}</version>
+    </dependency>
+    <dependency>
+      <groupId>org.slf4j</groupId>
+      <artifactId>slf4j-api</artifactId>
+      <version>2.0.9</version>
+    </dependency>
+  </dependencies>
+</project>
+`,
+			after: `<project>
+  <properties>
+    <junit.version> 4.13.2 </junit.version>
+  </properties>
+  <dependencies>
+    <dependency>
+      <groupId>junit</groupId>
+      <artifactId>junit</artifactId>
+      <version>${junit.version}</version>
+    </dependency>
+    <dependency>
+      <groupId>org.slf4j</groupId>
+      <artifactId>slf4j-api</artifactId>
+      <version>2.0.9</version>
+    </dependency>
+  </dependencies>
+</project>
+`,
+		},
+		{
+			// The property is only used by this artifact, so it's changed
+			// once for both declarations
+			filename: "pom.xml", name: "junit:junit", version: "4.13.2",
+			before: "<project><properties><junit.version>4.12</junit.version></properties>" +
+				"<dependencyManagement><dependencies><dependency><groupId>junit</groupId><artifactId>junit</artifactId><version>${junit.version}</version></dependency></dependencies></dependencyManagement>" +
+				"<dependencies><dependency><groupId>junit</groupId><artifactId>junit</artifactId><version>${junit.version}</version><scope>test</scope></dependency></dependencies></project>",
+			after: "<project><properties><junit.version>4.13.2</junit.version></properties>" +
+				"<dependencyManagement><dependencies><dependency><groupId>junit</groupId><artifactId>junit</artifactId><version>${junit.version}</version></dependency></dependencies></dependencyManagement>" +
+				"<dependencies><dependency><groupId>junit</groupId><artifactId>junit</artifactId><version>${junit.version}</version><scope>test</scope></dependency></dependencies></project>",
+		},
+		{
+			filename: "pom.xml", name: "org.slf4j:slf4j-api", version: "2.0.16",
+			before: "<project><dependencies><dependency><groupId>org.slf4j</groupId><artifactId>slf4j-api</artifactId><version>2.0.9</version></dependency></dependencies></project>",
+			after:  "<project><dependencies><dependency><groupId>org.slf4j</groupId><artifactId>slf4j-api</artifactId><version>2.0.16</version></dependency></dependencies></project>",
+		},
+		{
+			filename: "Dockerfile", name: "node", version: "22-alpine",
+			before: "FROM node:20-alpine AS build\nRUN npm ci\nFROM node AS runtime\n",
+			after:  "FROM node:22-alpine AS build\nRUN npm ci\nFROM node:22-alpine AS runtime\n",
+		},
+		{
+			filename: "Dockerfile.prod", name: "ghcr.io/acme/base", version: "sha256:2222",
+			before: "from ghcr.io/acme/base:1@sha256:1111\n",
+			after:  "from ghcr.io/acme/base:1@sha256:2222\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.filename+"/"+tt.name, func(t *testing.T) {
+			got, err := UpdateDependency(tt.filename, []byte(tt.before), tt.name, tt.version)
+			if err != nil {
+				t.Fatalf("UpdateDependency: %v", err)
+			}
+			if string(got) != tt.after {
+				t.Errorf("got:\n%s\nwant:\n%s", got, tt.after)
+			}
+		})
+	}
+}
+
+func TestUpdateDependencyErrors(t *testing.T) {
+	tests := []struct {
+		filename, content, name, version string
+		want                             error
+	}{
+		{"package.json", `{"dependencies": {"react": "^18.0.0"}}`, "lodash", "1.0.0", ErrDependencyNotFound},
+		{"package.json", `{"dependencies": {"react": "^18.0.0"}}`, "react", "1\n", ErrUnencodableVersion},
+		{"package-lock.json", `{"lockfileVersion": 3}`, "react", "1.0.0", ErrUpdateUnsupported},
+		{"Cargo.toml", "[dependencies]\nshared = { workspace = true }\n", "shared", "1.0", ErrNoVersion},
+		{"Gemfile", "gem 'rails', github: 'rails/rails'\n", "rails", "7.1", ErrNoVersion},
+		{"Gemfile", "gem 'rails', '7.0'\n", "rails", "7.1'", ErrUnencodableVersion},
+		{"go.mod", "module m\n\nrequire example.com/a v1.0.0\n", "example.com/a", "v1 .1", ErrUnencodableVersion},
+		{"pom.xml", "<project><dependencies><dependency><groupId>g</groupId><artifactId>a</artifactId><version>${parent.prop}</version></dependency></dependencies></project>", "g:a", "2", ErrNoVersion},
+		{"Dockerfile", "FROM node:20\n", "node", "sha256:abc", ErrUnencodableVersion},
+		{"pom.xml", "<project><properties><jackson.version>2.15.0</jackson.version></properties><dependencies>" +
+			"<dependency><groupId>com.fasterxml.jackson.core</groupId><artifactId>jackson-core</artifactId><version>${jackson.version}</version></dependency>" +
+			"<dependency><groupId>com.fasterxml.jackson.core</groupId><artifactId>jackson-databind</artifactId><version>${jackson.version}</version></dependency>" +
+			"</dependencies></project>", "com.fasterxml.jackson.core:jackson-core", "2.17.0", ErrSharedVersion},
+		{"pom.xml", "<project><properties><junit.version>4.12</junit.version></properties><dependencies>" +
+			"<dependency><groupId>junit</groupId><artifactId>junit</artifactId><version>${junit.version}</version></dependency>" +
+			"</dependencies><build><plugins><plugin><artifactId>p</artifactId><version>${junit.version}</version></plugin></plugins></build></project>",
+			"junit:junit", "4.13.2", ErrSharedVersion},
+	}
+	for _, tt := range tests {
+		_, err := UpdateDependency(tt.filename, []byte(tt.content), tt.name, tt.version)
+		var updateErr *UpdateError
+		if !errors.As(err, &updateErr) || !errors.Is(err, tt.want) {
+			t.Errorf("%s %s: got %v, want %v", tt.filename, tt.name, err, tt.want)
+		}
+	}
+
+	_, err := UpdateDependency("unknown.txt", nil, "a", "1")
+	var unknown *UnknownFileError
+	if !errors.As(err, &unknown) {
+		t.Errorf("expected UnknownFileError, got %v", err)
+	}
+}
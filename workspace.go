@@ -0,0 +1,324 @@
+package manifests
+
+import (
+	"errors"
+	"io/fs"
+	"maps"
+	"path"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/git-pkgs/manifests/internal/core"
+)
+
+// Workspace is a monorepo workspace: the member packages that one or
+// more definition files in a directory declare.
+type Workspace struct {
+	Ecosystem string
+	// Dir is the slash-separated directory holding the definitions,
+	// relative to the scanned root. "." is the root itself.
+	Dir string
+	// Definitions are the paths of the files declaring the workspace,
+	// such as package.json and lerna.json, which may both list members.
+	Definitions []string
+	// Members are in lexical Dir order.
+	Members []*WorkspaceMember
+}
+
+// WorkspaceMember is a package in a workspace.
+type WorkspaceMember struct {
+	// Dir is the member's slash-separated directory, relative to the
+	// scanned root.
+	Dir string
+	// Name and Version are the package's own identity from its manifest.
+	Name    string
+	Version string
+	// Path is the member's manifest, or empty when no scanned manifest
+	// was found in Dir. That happens for members that are listed by path
+	// (go.work, Maven modules, Gradle includes, rush.json) but weren't
+	// scanned, and for Gradle projects without a build file.
+	Path string
+	// Manifest is a copy of the parsed manifest with the dependencies on
+	// other members marked Workspace. Nil when Path is empty.
+	Manifest *ParseResult
+	// Dependencies lists the member's dependencies on other members, in
+	// the order the manifest lists them.
+	Dependencies []WorkspaceDependency
+}
+
+// WorkspaceDependency is a dependency of one workspace member on
+// another.
+type WorkspaceDependency struct {
+	// Member is the member depended on.
+	Member *WorkspaceMember
+	// Dependency is the declaration in the depending member's Manifest.
+	Dependency *Dependency
+}
+
+// Workspaces finds the monorepo workspaces declared in fsys, using the
+// files a Scan of it found as the candidate members. These definitions
+// are read:
+//
+//   - package.json "workspaces" (npm, Yarn, Bun), pnpm-workspace.yaml,
+//     lerna.json and rush.json
+//   - Cargo.toml [workspace] members and exclude
+//   - pyproject.toml [tool.uv.workspace], or for Poetry, which has no
+//     workspace table, path dependencies below the project's directory
+//   - go.work use directives
+//   - pom.xml <modules>
+//   - settings.gradle and settings.gradle.kts includes
+//
+// Definitions are looked for in the root and in every directory holding
+// or enclosing a scanned file. A directory matching a member pattern is
+// a member when it holds a scanned manifest of the workspace's kind
+// (package.json, Cargo.toml, and so on); members listed by exact path
+// are included even without one.
+//
+// A member's dependency is internal when it names another member of
+// the same workspace. Internal dependencies are marked Workspace in the
+// member's Manifest and listed in its Dependencies. The ScannedFiles
+// themselves aren't modified.
+//
+// Definitions that can't be read or parsed are skipped and reported,
+// as *ScanError values, in the returned error; the workspaces that
+// could be read are returned alongside it. The limits in opts apply to
+// each definition as they do in Parse, failing it with a *LimitError.
+func Workspaces(fsys fs.FS, files []ScannedFile, opts ...Options) ([]*Workspace, error) {
+	var o Options
+	if len(opts) > 0 {
+		o = opts[0]
+	}
+	limits := o.limits()
+
+	dirs := map[string]bool{".": true}
+	manifests := make(map[string][]ScannedFile)
+	for _, f := range files {
+		dir := path.Dir(f.Path)
+		for d := dir; !dirs[d]; d = path.Dir(d) {
+			dirs[d] = true
+		}
+		if f.Kind == Manifest {
+			manifests[dir] = append(manifests[dir], f)
+		}
+	}
+
+	type key struct{ dir, ecosystem string }
+	type found struct {
+		ws  *Workspace
+		def *core.WorkspaceDefinition
+	}
+	byKey := make(map[key]*found)
+	var workspaces []*found
+	var errs []error
+	for _, dir := range slices.Sorted(maps.Keys(dirs)) {
+		entries, err := fs.ReadDir(fsys, dir)
+		if err != nil {
+			errs = append(errs, &ScanError{Path: dir, Err: err})
+			continue
+		}
+		for _, entry := range entries {
+			if !entry.Type().IsRegular() {
+				continue
+			}
+			p := path.Join(dir, entry.Name())
+			readers := core.IdentifyWorkspaceReaders(p)
+			if len(readers) == 0 {
+				continue
+			}
+			content, err := fs.ReadFile(fsys, p)
+			if err != nil {
+				errs = append(errs, &ScanError{Path: p, Err: err})
+				continue
+			}
+			if limits.MaxInputSize > 0 && len(content) > limits.MaxInputSize {
+				errs = append(errs, &ScanError{Path: p, Err: &LimitError{Filename: p, Err: ErrInputTooLarge, Limit: limits.MaxInputSize}})
+				continue
+			}
+			for _, reg := range readers {
+				var def *core.WorkspaceDefinition
+				if lr, ok := reg.Reader.(core.LimitedWorkspaceReader); ok {
+					def, err = lr.ReadWorkspaceLimited(p, content, limits)
+				} else {
+					def, err = reg.Reader.ReadWorkspace(p, content)
+				}
+				if err != nil {
+					errs = append(errs, &ScanError{Path: p, Err: err})
+					continue
+				}
+				if def == nil {
+					continue
+				}
+				// Definitions of the same kind in one directory, such as
+				// lerna.json beside package.json, make one workspace
+				k := key{dir, reg.Ecosystem}
+				if f, ok := byKey[k]; ok {
+					f.ws.Definitions = append(f.ws.Definitions, p)
+					f.def.Members = append(f.def.Members, def.Members...)
+					f.def.Exclude = append(f.def.Exclude, def.Exclude...)
+					continue
+				}
+				f := &found{ws: &Workspace{Ecosystem: reg.Ecosystem, Dir: dir, Definitions: []string{p}}, def: def}
+				byKey[k] = f
+				workspaces = append(workspaces, f)
+			}
+		}
+	}
+
+	result := make([]*Workspace, 0, len(workspaces))
+	for _, f := range workspaces {
+		f.ws.Members = workspaceMembers(f.ws, f.def, manifests)
+		linkWorkspaceMembers(f.ws)
+		result = append(result, f.ws)
+	}
+	return result, errors.Join(errs...)
+}
+
+// workspaceMembers resolves a definition's member patterns against the
+// directories holding a manifest of the workspace's ecosystem.
+func workspaceMembers(ws *Workspace, def *core.WorkspaceDefinition, manifests map[string][]ScannedFile) []*WorkspaceMember {
+	// manifestIn returns the preferred member manifest in dir
+	manifestIn := func(dir string) (ScannedFile, bool) {
+		for _, name := range def.Manifests {
+			for _, f := range manifests[dir] {
+				if path.Base(f.Path) == name && f.Ecosystem == ws.Ecosystem {
+					return f, true
+				}
+			}
+		}
+		return ScannedFile{}, false
+	}
+
+	members := make(map[string]*WorkspaceMember)
+	add := func(dir string) {
+		if _, ok := members[dir]; ok {
+			return
+		}
+		m := &WorkspaceMember{Dir: dir}
+		if f, ok := manifestIn(dir); ok {
+			m.Path = f.Path
+			m.Name = f.Name
+			m.Version = f.Version
+			m.Manifest = f.ParseResult
+		}
+		members[dir] = m
+	}
+
+	excluded := func(rel string) bool {
+		for _, pattern := range def.Exclude {
+			if matchWorkspacePattern(cleanWorkspacePattern(pattern), rel) {
+				return true
+			}
+		}
+		return false
+	}
+
+	for _, pattern := range def.Members {
+		pattern = cleanWorkspacePattern(pattern)
+		if !isWorkspaceGlob(pattern) {
+			dir := path.Join(ws.Dir, pattern)
+			if fs.ValidPath(dir) && !excluded(pattern) {
+				add(dir)
+			}
+			continue
+		}
+		for dir := range manifests {
+			rel, ok := relativeDir(ws.Dir, dir)
+			// Only "." itself makes the definition's directory a member
+			if !ok || rel == "." || !matchWorkspacePattern(pattern, rel) || excluded(rel) {
+				continue
+			}
+			if _, ok := manifestIn(dir); ok {
+				add(dir)
+			}
+		}
+	}
+
+	sorted := make([]*WorkspaceMember, 0, len(members))
+	for _, m := range members {
+		sorted = append(sorted, m)
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Dir < sorted[j].Dir })
+	return sorted
+}
+
+// linkWorkspaceMembers finds the dependencies of each member that name
+// another member, marking them in a copy of the member's manifest.
+func linkWorkspaceMembers(ws *Workspace) {
+	byName := make(map[string]*WorkspaceMember)
+	for _, m := range ws.Members {
+		if m.Name != "" && m.Manifest != nil {
+			k := projectKey(m.Manifest.Ecosystem, m.Name)
+			if _, ok := byName[k]; !ok {
+				byName[k] = m
+			}
+		}
+	}
+
+	for _, m := range ws.Members {
+		if m.Manifest == nil {
+			continue
+		}
+		manifest := *m.Manifest
+		manifest.Dependencies = slices.Clone(manifest.Dependencies)
+		m.Manifest = &manifest
+		for i := range manifest.Dependencies {
+			dep := &manifest.Dependencies[i]
+			target, ok := byName[projectKey(manifest.Ecosystem, dep.Name)]
+			if !ok || target == m {
+				continue
+			}
+			dep.Workspace = true
+			m.Dependencies = append(m.Dependencies, WorkspaceDependency{Member: target, Dependency: dep})
+		}
+	}
+}
+
+// cleanWorkspacePattern normalises "./packages/*/" to "packages/*".
+func cleanWorkspacePattern(pattern string) string {
+	return path.Clean(strings.TrimSpace(pattern))
+}
+
+func isWorkspaceGlob(pattern string) bool {
+	return strings.ContainsAny(pattern, "*?[")
+}
+
+// relativeDir returns dir relative to base, or false when dir isn't
+// base or below it.
+func relativeDir(base, dir string) (string, bool) {
+	switch {
+	case base == dir:
+		return ".", true
+	case base == ".":
+		return dir, true
+	}
+	return strings.CutPrefix(dir, base+"/")
+}
+
+// matchWorkspacePattern matches a slash-separated directory against a
+// pattern whose segments use path.Match syntax, where a "**" segment
+// matches any number of directories.
+func matchWorkspacePattern(pattern, dir string) bool {
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(dir, "/"))
+}
+
+func matchSegments(pattern, dir []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(dir); i++ {
+				if matchSegments(pattern[1:], dir[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(dir) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pattern[0], dir[0]); !ok {
+			return false
+		}
+		pattern, dir = pattern[1:], dir[1:]
+	}
+	return len(dir) == 0
+}
@@ -0,0 +1,350 @@
+package manifests
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+// scanWorkspaces scans fsys and returns its workspaces.
+func scanWorkspaces(t *testing.T, fsys fstest.MapFS) []*Workspace {
+	t.Helper()
+	res, err := Scan(fsys)
+	if err != nil {
+		t.Fatalf("Scan failed: %v", err)
+	}
+	workspaces, err := Workspaces(fsys, res.Files)
+	if err != nil {
+		t.Fatalf("Workspaces failed: %v", err)
+	}
+	return workspaces
+}
+
+func memberDirs(ws *Workspace) []string {
+	var dirs []string
+	for _, m := range ws.Members {
+		dirs = append(dirs, m.Dir)
+	}
+	return dirs
+}
+
+func TestWorkspacesNpm(t *testing.T) {
+	fsys := fstest.MapFS{
+		"package.json": {Data: []byte(`{"name": "root", "private": true, "workspaces": ["packages/*", "!packages/legacy"]}`)},
+		"lerna.json":   {Data: []byte(`{"packages": ["tools/*"]}`)},
+		"packages/core/package.json": {Data: []byte(`{"name": "@acme/core", "version": "1.2.0",
+			"dependencies": {"lodash": "^4.17.0"}}`)},
+		"packages/web/package.json": {Data: []byte(`{"name": "@acme/web", "version": "0.1.0",
+			"dependencies": {"@acme/core": "workspace:^", "react": "^18.0.0"},
+			"devDependencies": {"@acme/cli": "*"}}`)},
+		"packages/legacy/package.json": {Data: []byte(`{"name": "@acme/legacy"}`)},
+		"packages/docs/README.md":      {Data: []byte("no manifest\n")},
+		"tools/cli/package.json":       {Data: []byte(`{"name": "@acme/cli"}`)},
+	}
+
+	workspaces := scanWorkspaces(t, fsys)
+	if len(workspaces) != 1 {
+		t.Fatalf("expected 1 workspace, got %d", len(workspaces))
+	}
+	ws := workspaces[0]
+	if ws.Ecosystem != "npm" || ws.Dir != "." || len(ws.Definitions) != 2 {
+		t.Errorf("unexpected workspace %+v", ws)
+	}
+	want := []string{"packages/core", "packages/web", "tools/cli"}
+	if got := memberDirs(ws); !slices.Equal(got, want) {
+		t.Fatalf("members = %v, want %v", got, want)
+	}
+
+	core, web := ws.Members[0], ws.Members[1]
+	if core.Name != "@acme/core" || core.Version != "1.2.0" || core.Path != "packages/core/package.json" {
+		t.Errorf("unexpected member %+v", core)
+	}
+	if len(core.Dependencies) != 0 {
+		t.Errorf("core has internal dependencies %+v", core.Dependencies)
+	}
+
+	internal := make(map[string]*WorkspaceMember)
+	for _, d := range web.Dependencies {
+		if !d.Dependency.Workspace {
+			t.Errorf("%s not marked Workspace", d.Dependency.Name)
+		}
+		internal[d.Dependency.Name] = d.Member
+	}
+	if len(internal) != 2 || internal["@acme/core"] != core || internal["@acme/cli"] != ws.Members[2] {
+		t.Errorf("unexpected internal dependencies %+v", web.Dependencies)
+	}
+	for _, dep := range web.Manifest.Dependencies {
+		if dep.Name == "react" && dep.Workspace {
+			t.Error("react marked Workspace")
+		}
+	}
+}
+
+func TestWorkspacesDontModifyScan(t *testing.T) {
+	fsys := fstest.MapFS{
+		"package.json":         {Data: []byte(`{"workspaces": ["*"]}`)},
+		"a/package.json":       {Data: []byte(`{"name": "a", "dependencies": {"b": "^1.0.0"}}`)},
+		"b/package.json":       {Data: []byte(`{"name": "b"}`)},
+		"elsewhere/c/index.js": {Data: []byte("")},
+	}
+	res, err := Scan(fsys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	workspaces, err := Workspaces(fsys, res.Files)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(workspaces[0].Members[0].Dependencies) != 1 {
+		t.Fatalf("expected a→b, got %+v", workspaces[0].Members[0])
+	}
+	for _, f := range res.Files {
+		for _, dep := range f.Dependencies {
+			if dep.Workspace {
+				t.Errorf("%s: scanned dependency %s was modified", f.Path, dep.Name)
+			}
+		}
+	}
+}
+
+func TestWorkspacesCargo(t *testing.T) {
+	fsys := fstest.MapFS{
+		"Cargo.toml": {Data: []byte(`[package]
+name = "app"
+version = "0.1.0"
+
+[dependencies]
+app-core = { workspace = true }
+
+[workspace]
+members = ["crates/*"]
+exclude = ["crates/experimental"]
+
+[workspace.dependencies]
+app-core = { path = "crates/core" }
+`)},
+		"crates/core/Cargo.toml":         {Data: []byte("[package]\nname = \"app-core\"\nversion = \"0.1.0\"\n")},
+		"crates/experimental/Cargo.toml": {Data: []byte("[package]\nname = \"exp\"\n")},
+	}
+
+	workspaces := scanWorkspaces(t, fsys)
+	if len(workspaces) != 1 {
+		t.Fatalf("expected 1 workspace, got %d", len(workspaces))
+	}
+	ws := workspaces[0]
+	if got, want := memberDirs(ws), []string{".", "crates/core"}; !slices.Equal(got, want) {
+		t.Fatalf("members = %v, want %v", got, want)
+	}
+	root := ws.Members[0]
+	if root.Name != "app" || len(root.Dependencies) != 1 || root.Dependencies[0].Member.Name != "app-core" {
+		t.Errorf("unexpected root member %+v", root)
+	}
+}
+
+func TestWorkspacesByPath(t *testing.T) {
+	fsys := fstest.MapFS{
+		"go.work": {Data: []byte("go 1.23\n\nuse (\n\t./api\n\t./lib // shared\n)\nuse ./missing\n")},
+		"api/go.mod": {Data: []byte(`module example.com/api
+
+go 1.23
+
+require example.com/lib v0.0.0
+`)},
+		"lib/go.mod": {Data: []byte("module example.com/lib\n")},
+
+		"java/pom.xml": {Data: []byte(`<project xmlns="http://maven.apache.org/POM/4.0.0">
+  <groupId>com.acme</groupId><artifactId>parent</artifactId><version>1.0</version>
+  <modules><module>core</module><module>app/pom.xml</module></modules>
+</project>`)},
+		"java/core/pom.xml": {Data: []byte(`<project><groupId>com.acme</groupId><artifactId>core</artifactId><version>1.0</version></project>`)},
+		"java/app/pom.xml": {Data: []byte(`<project><groupId>com.acme</groupId><artifactId>app</artifactId><version>1.0</version>
+  <dependencies><dependency><groupId>com.acme</groupId><artifactId>core</artifactId><version>1.0</version></dependency></dependencies>
+</project>`)},
+
+		"android/settings.gradle": {Data: []byte(`rootProject.name = 'android'
+include ':app',
+        ':libs:ui'
+includeFlat 'shared'
+include(":feature")
+project(':feature').projectDir = file('features/main')
+`)},
+		"android/app/build.gradle": {Data: []byte("dependencies {\n    implementation 'com.squareup.okhttp3:okhttp:4.12.0'\n}\n")},
+	}
+
+	workspaces := scanWorkspaces(t, fsys)
+	want := map[string][]string{
+		"golang": {"api", "lib", "missing"},
+		"maven":  {"java/app", "java/core"},
+	}
+	byEco := make(map[string]*Workspace)
+	for _, ws := range workspaces {
+		if ws.Ecosystem == "maven" && ws.Dir == "android" {
+			// includeFlat names a sibling of the settings directory
+			if got := memberDirs(ws); !slices.Equal(got, []string{"android/app", "android/features/main", "android/libs/ui", "shared"}) {
+				t.Errorf("gradle members = %v", got)
+			}
+			continue
+		}
+		byEco[ws.Ecosystem] = ws
+	}
+	for eco, dirs := range want {
+		ws, ok := byEco[eco]
+		if !ok {
+			t.Errorf("no %s workspace", eco)
+			continue
+		}
+		if got := memberDirs(ws); !slices.Equal(got, dirs) {
+			t.Errorf("%s members = %v, want %v", eco, got, dirs)
+		}
+	}
+
+	golang := byEco["golang"]
+	if api := golang.Members[0]; len(api.Dependencies) != 1 || api.Dependencies[0].Member.Dir != "lib" {
+		t.Errorf("api dependencies = %+v", api.Dependencies)
+	}
+	if missing := golang.Members[2]; missing.Manifest != nil || missing.Path != "" {
+		t.Errorf("missing member has a manifest: %+v", missing)
+	}
+	maven := byEco["maven"]
+	if app := maven.Members[0]; len(app.Dependencies) != 1 || app.Dependencies[0].Member.Name != "com.acme:core" {
+		t.Errorf("app dependencies = %+v", app.Dependencies)
+	}
+}
+
+func TestWorkspacesPnpmAndUv(t *testing.T) {
+	fsys := fstest.MapFS{
+		"js/pnpm-workspace.yaml":            {Data: []byte("packages:\n  - 'apps/**'\n  - '!**/fixtures/**'\n")},
+		"js/package.json":                   {Data: []byte(`{"name": "js"}`)},
+		"js/apps/site/package.json":         {Data: []byte(`{"name": "site"}`)},
+		"js/apps/admin/ui/package.json":     {Data: []byte(`{"name": "admin-ui"}`)},
+		"js/apps/fixtures/x/package.json":   {Data: []byte(`{"name": "x"}`)},
+		"py/pyproject.toml":                 {Data: []byte("[project]\nname = \"root\"\ndependencies = [\"Shared_Utils>=0.1\"]\n\n[tool.uv.workspace]\nmembers = [\"packages/*\"]\n")},
+		"py/packages/shared/pyproject.toml": {Data: []byte("[project]\nname = \"shared-utils\"\nversion = \"0.1.0\"\n")},
+	}
+
+	workspaces := scanWorkspaces(t, fsys)
+	if len(workspaces) != 2 {
+		t.Fatalf("expected 2 workspaces, got %d", len(workspaces))
+	}
+	js, py := workspaces[0], workspaces[1]
+	if got, want := memberDirs(js), []string{"js/apps/admin/ui", "js/apps/site"}; !slices.Equal(got, want) {
+		t.Errorf("pnpm members = %v, want %v", got, want)
+	}
+	if got, want := memberDirs(py), []string{"py", "py/packages/shared"}; !slices.Equal(got, want) {
+		t.Errorf("uv members = %v, want %v", got, want)
+	}
+	// Python names match after normalisation
+	if root := py.Members[0]; len(root.Dependencies) != 1 || root.Dependencies[0].Member.Name != "shared-utils" {
+		t.Errorf("root dependencies = %+v", root.Dependencies)
+	}
+}
+
+func TestWorkspacesPoetry(t *testing.T) {
+	fsys := fstest.MapFS{
+		"pyproject.toml": {Data: []byte(`[tool.poetry]
+name = "platform"
+version = "1.0.0"
+
+[tool.poetry.dependencies]
+python = "^3.11"
+api = {path = "services/api", develop = true}
+vendored = {path = "dist/vendored-1.0.tar.gz"}
+
+[tool.poetry.group.dev.dependencies]
+testing-tools = {path = "./libs/testing"}
+`)},
+		"services/api/pyproject.toml": {Data: []byte(`[tool.poetry]
+name = "api"
+version = "0.3.0"
+
+[tool.poetry.dependencies]
+Testing_Tools = {path = "../../libs/testing"}
+requests = "^2.31"
+`)},
+		"libs/testing/pyproject.toml": {Data: []byte("[tool.poetry]\nname = \"testing-tools\"\nversion = \"0.1.0\"\n")},
+	}
+
+	workspaces := scanWorkspaces(t, fsys)
+	// services/api's path leads out of it, so only the root is a workspace
+	if len(workspaces) != 1 {
+		t.Fatalf("expected 1 workspace, got %d", len(workspaces))
+	}
+	ws := workspaces[0]
+	if ws.Dir != "." || ws.Ecosystem != "pypi" {
+		t.Errorf("workspace = %s %s", ws.Dir, ws.Ecosystem)
+	}
+	if got, want := memberDirs(ws), []string{".", "libs/testing", "services/api"}; !slices.Equal(got, want) {
+		t.Fatalf("members = %v, want %v", got, want)
+	}
+	root, api := ws.Members[0], ws.Members[2]
+	if len(root.Dependencies) != 2 {
+		t.Errorf("root dependencies = %+v", root.Dependencies)
+	}
+	if len(api.Dependencies) != 1 || api.Dependencies[0].Member.Name != "testing-tools" || !api.Dependencies[0].Dependency.Workspace {
+		t.Errorf("api dependencies = %+v", api.Dependencies)
+	}
+}
+
+func TestWorkspacesErrors(t *testing.T) {
+	fsys := fstest.MapFS{
+		"Cargo.toml":       {Data: []byte("[workspace\n")},
+		"a/package.json":   {Data: []byte(`{"workspaces": ["b"]}`)},
+		"a/b/package.json": {Data: []byte(`{"name": "b"}`)},
+	}
+	res, err := Scan(fsys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	workspaces, err := Workspaces(fsys, res.Files)
+	var scanErr *ScanError
+	if !errors.As(err, &scanErr) || scanErr.Path != "Cargo.toml" {
+		t.Errorf("expected a ScanError for Cargo.toml, got %v", err)
+	}
+	if len(workspaces) != 1 || workspaces[0].Dir != "a" {
+		t.Errorf("expected the npm workspace to survive, got %+v", workspaces)
+	}
+}
+
+func TestWorkspacesLimits(t *testing.T) {
+	fsys := fstest.MapFS{
+		"package.json":        {Data: []byte(`{"name": "root"}`)},
+		"pnpm-workspace.yaml": {Data: []byte(strings.Replace(laughs(6), "services:", "packages: [a]\nservices:", 1))},
+		"a/package.json":      {Data: []byte(`{"name": "a"}`)},
+	}
+	res, err := Scan(fsys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = Workspaces(fsys, res.Files, Options{MaxAliases: 1000})
+	var limitErr *LimitError
+	if !errors.As(err, &limitErr) || !errors.Is(err, ErrTooManyAliases) || limitErr.Filename != "pnpm-workspace.yaml" {
+		t.Errorf("expected a LimitError for pnpm-workspace.yaml, got %v", err)
+	}
+
+	workspaces, err := Workspaces(fsys, res.Files, Options{MaxInputSize: 10})
+	if !errors.Is(err, ErrInputTooLarge) || len(workspaces) != 0 {
+		t.Errorf("expected pnpm-workspace.yaml to be too large, got %v, %+v", err, workspaces)
+	}
+}
+
+func TestMatchWorkspacePattern(t *testing.T) {
+	tests := []struct {
+		pattern, dir string
+		want         bool
+	}{
+		{"packages/*", "packages/a", true},
+		{"packages/*", "packages/a/b", false},
+		{"packages/**", "packages/a/b", true},
+		{"**/fixtures/**", "apps/fixtures/x", true},
+		{"apps/*-web", "apps/shop-web", true},
+		{"apps/*-web", "apps/shop-api", false},
+		{".", ".", true},
+	}
+	for _, tt := range tests {
+		if got := matchWorkspacePattern(tt.pattern, tt.dir); got != tt.want {
+			t.Errorf("matchWorkspacePattern(%q, %q) = %v, want %v", tt.pattern, tt.dir, got, tt.want)
+		}
+	}
+}